@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
 )
 
 // TODO: add build info flag to retrieve metainfo
@@ -12,9 +19,50 @@ import (
 // var buildDate = "n/a"
 
 func main() {
-	model := client.New()
+	// secrets live in client memory, keep them out of core dumps
+	if err := secure.DisableCoreDumps(); err != nil {
+		log.Printf("failed to disable core dumps: %v", err)
+	}
+
+	loader := config.NewLoader(config.AppClient)
+	calibrate := loader.Flags().Bool("calibrate", false, "benchmark key derivation profiles, propose one to store in the config file and exit")
+	conf, err := loader.Load(os.Args[1:])
+	if errors.Is(err, config.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if conf.PrintConfig {
+		out, err := conf.Print(config.AppClient)
+		if err != nil {
+			log.Fatalf("failed to print config: %v", err)
+		}
+
+		fmt.Print(out)
+		return
+	}
+	if *calibrate {
+		if err := client.Calibrate(&conf.Client, loader.ConfigFile(), os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	// stdout exporter will interfere with TUI, prefer file exporter for client
+	shutdownTracing, err := tracing.Setup(conf.Client.Tracing, "gophkeeper-client")
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
+	model := client.New(&conf.Client)
 	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+	_, runErr := p.Run()
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
+	if runErr != nil {
+		log.Fatal(runErr)
 	}
 }
@@ -1,46 +1,167 @@
 package main
 
 import (
-	"flag"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
 )
 
+const usage = `Usage: migrator [flags] <command> [argument]
+
+Commands:
+  up           apply all pending migrations (default)
+  down         roll back the last migration, with --all roll back everything
+  version      print current and latest schema versions
+  steps N      apply N migrations, negative N rolls back -N migrations
+  goto V       migrate up or down to version V
+  force V      set version V and clear dirty state without running migrations,
+               use after fixing a failed migration by hand (-1 means no version)
+
+Migrations are embedded into the binary.
+
+Flags:
+`
+
 func main() {
-	var direction string
-	flag.StringVar(&direction, "direction", "up", "Migration direction (up/down)")
-	flag.Parse()
-	conf, err := config.NewConfig()
+	loader := config.NewLoader(config.AppMigrator)
+	flags := loader.Flags()
+	direction := flags.String("direction", "", "migration direction (up/down)")
+	all := flags.Bool("all", false, "let down roll back all migrations")
+	_ = flags.MarkDeprecated("direction", "use up or down command instead")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	conf, err := loader.Load(os.Args[1:])
+	if errors.Is(err, config.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
 		log.Fatalf("failed to load DB config: %v", err)
 	}
-	appDriver, err := database.NewSQLDriver(&conf.Database)
-	if err != nil {
-		log.Fatalf("failed to create PostgreSQL driver: %v", err)
+	if conf.PrintConfig {
+		out, err := conf.Print(config.AppMigrator)
+		if err != nil {
+			log.Fatalf("failed to print config: %v", err)
+		}
+
+		fmt.Print(out)
+		return
 	}
-	driver, err := postgres.WithInstance(appDriver.Conn, &postgres.Config{})
+
+	args := flags.Args()
+	if len(args) == 0 {
+		switch *direction {
+		case "", "up":
+			args = []string{"up"}
+		case "down":
+			// previous -direction down behaviour
+			args = []string{"down"}
+			*all = true
+		default:
+			log.Fatalf("unknown migration direction %q, expected up or down", *direction)
+		}
+	}
+
+	db, err := openDB(&conf.Database)
 	if err != nil {
-		log.Fatalf("failed to create PostgreSQL driver: %v", err)
+		log.Fatalf("failed to connect to database: %v", err)
 	}
-	migrator, err := migrate.NewWithDatabaseInstance("file://migrations", "postgres", driver)
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(conf.Database.Engine, db)
 	if err != nil {
 		log.Fatalf("failed to create new migrator: %v", err)
 	}
-	if direction == "up" {
-		if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("migration up failed: %v", err)
+	defer migrator.Close()
+
+	if err := run(migrator, args, *all); err != nil {
+		log.Fatalf("migration %s failed: %v", args[0], err)
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		log.Fatalf("failed to read schema version: %v", err)
+	}
+
+	log.Printf("schema version %d (dirty: %t), latest %d", version, dirty, migrator.Latest())
+}
+
+func run(migrator *database.Migrator, args []string, all bool) error {
+	command := args[0]
+	switch command {
+	case "up", "down", "version":
+		if len(args) != 1 {
+			return fmt.Errorf("%s takes no arguments", command)
+		}
+	case "steps", "goto", "force":
+		if len(args) != 2 {
+			return fmt.Errorf("%s takes exactly one argument", command)
+		}
+	default:
+		return fmt.Errorf("unknown command %q, see --help", command)
+	}
+
+	switch command {
+	case "up":
+		return migrator.Up()
+	case "down":
+		if all {
+			return migrator.Down()
+		}
+
+		return migrator.Steps(-1)
+	case "steps":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n == 0 {
+			return fmt.Errorf("invalid number of steps %q", args[1])
+		}
+
+		return migrator.Steps(n)
+	case "goto":
+		version, err := strconv.ParseUint(args[1], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+
+		return migrator.Goto(uint(version))
+	case "force":
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return fmt.Errorf("invalid version %q", args[1])
 		}
-		log.Printf("migration up completed successfully")
+
+		return migrator.Force(version)
 	}
-	if direction == "down" {
-		if err := migrator.Down(); err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("migration down failed: %v", err)
+
+	return nil
+}
+
+func openDB(conf *config.Database) (*sql.DB, error) {
+	switch conf.Engine {
+	case config.DatabaseEngineSQLite:
+		driver, err := database.NewSQLiteDriver(conf)
+		if err != nil {
+			return nil, err
 		}
-		log.Printf("migration down completed successfully")
+
+		return driver.Conn, nil
+	case config.DatabaseEnginePostgres:
+		driver, err := database.NewSQLDriver(conf, secrets.NewSecret([]byte(conf.Password)))
+		if err != nil {
+			return nil, err
+		}
+
+		return driver.Conn, nil
+	default:
+		return nil, fmt.Errorf("%s engine has no schema to migrate", conf.Engine)
 	}
 }
@@ -1,20 +1,58 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/app"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
 )
 
 func main() {
-	conf, err := config.NewConfig()
+	loader := config.NewLoader(config.AppServer)
+	conf, err := loader.Load(os.Args[1:])
+	if errors.Is(err, config.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if conf.PrintConfig {
+		out, err := conf.Print(config.AppServer)
+		if err != nil {
+			log.Fatalf("failed to print config: %v", err)
+		}
+
+		fmt.Print(out)
+		return
+	}
 
 	// TODO: now grpc server blocks main goroutine, in case of graceful shutdown
 	// need to run it in a separate goroutine
 	appServer := app.New(conf)
+	reload := func(conf *config.Config, err error) {
+		if err != nil {
+			log.Printf("failed to reload config: %v", err)
+			return
+		}
+
+		appServer.Reload(conf)
+	}
+	loader.Watch(reload)
+	go reloadOnSignal(loader, reload)
 	appServer.Start()
 }
+
+// reloadOnSignal re-reads config and secrets on SIGHUP, invalid config
+// is reported and ignored, so the server keeps running with previous values.
+func reloadOnSignal(loader *config.Loader, reload func(*config.Config, error)) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		reload(loader.Reload())
+	}
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/interceptor"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/admin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type adminGRPCServer struct {
+	admin.UnimplementedAdminServiceServer
+	adminService ports.AdminService
+}
+
+func NewAdminGRPCServer(adminService ports.AdminService) *adminGRPCServer {
+	return &adminGRPCServer{
+		adminService: adminService,
+	}
+}
+
+func (s *adminGRPCServer) SetSetting(
+	ctx context.Context,
+	req *admin.SetSettingRequest,
+) (*admin.SetSettingResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+	previous, err := s.adminService.SetSetting(ctx, userID, req.GetKey(), req.GetValue(), ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return admin.SetSettingResponse_builder{
+		PreviousValue: proto.String(previous),
+		Value:         proto.String(req.GetValue()),
+	}.Build(), nil
+}
@@ -2,13 +2,9 @@ package grpc
 
 import (
 	"context"
-	"errors"
 
-	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/auth"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -28,14 +24,9 @@ func (s *authGRPCServer) Register(
 	req *auth.RegisterRequest,
 ) (*auth.RegisterResponse, error) {
 	username, password := req.GetUsername(), req.GetPassword()
-	token, err := s.authService.Register(username, password)
-	var apperr *apperror.AppError
-	if errors.As(err, &apperr) {
-		appErr := err.(*apperror.AppError)
-		return nil, status.Errorf(appErr.GRPCStatus, "%s", appErr.Message)
-	}
+	token, err := s.authService.Register(ctx, username, password)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "%v", err)
+		return nil, err
 	}
 
 	resp := auth.RegisterResponse_builder{
@@ -50,14 +41,9 @@ func (s *authGRPCServer) Authenticate(
 	req *auth.AuthRequest,
 ) (*auth.AuthResponse, error) {
 	username, password := req.GetUsername(), req.GetPassword()
-	token, err := s.authService.Authenticate(username, password)
-	var apperr *apperror.AppError
-	if errors.As(err, &apperr) {
-		appErr := err.(*apperror.AppError)
-		return nil, status.Errorf(appErr.GRPCStatus, "%s", appErr.Message)
-	}
+	token, err := s.authService.Authenticate(ctx, username, password)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "%v", err)
+		return nil, err
 	}
 
 	resp := auth.AuthResponse_builder{
@@ -2,10 +2,9 @@ package grpc
 
 import (
 	"context"
-	"errors"
+	"sync/atomic"
 	"time"
 
-	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/interceptor"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
@@ -20,16 +19,26 @@ type storageGRPCServer struct {
 	storage.UnimplementedStorageServiceServer
 	storageService      ports.StorageService
 	subscriptionService ports.SubscriptionService
+	listTimeout         atomic.Int64
 }
 
 func NewStorageGRPCServer(
 	service ports.StorageService,
 	subscriptionService ports.SubscriptionService,
+	listTimeout time.Duration,
 ) *storageGRPCServer {
-	return &storageGRPCServer{
+	s := &storageGRPCServer{
 		storageService:      service,
 		subscriptionService: subscriptionService,
 	}
+	s.SetListTimeout(listTimeout)
+
+	return s
+}
+
+// SetListTimeout changes lifetime of new ListDataBlocks streams.
+func (s *storageGRPCServer) SetListTimeout(timeout time.Duration) {
+	s.listTimeout.Store(int64(timeout))
 }
 
 func (s *storageGRPCServer) SaveDataBlock(
@@ -49,10 +58,13 @@ func (s *storageGRPCServer) SaveDataBlock(
 		Salt:    req.GetSalt(),
 		Nonce:   req.GetNonce(),
 		Profile: req.GetProfile().String(),
+		Cipher:  req.GetCipher().String(),
+		UID:     req.GetBlockUid(),
+		Format:  int(req.GetFormat()),
 		TypeID:  int(req.GetTypeId()),
 	}
 
-	block, err := s.storageService.SaveDataBlock(userIDInt, block)
+	block, err := s.storageService.SaveDataBlock(ctx, userIDInt, block)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +72,64 @@ func (s *storageGRPCServer) SaveDataBlock(
 	return storage.SaveDataBlockResponse_builder{}.Build(), nil
 }
 
+func (s *storageGRPCServer) UpdateDataBlock(
+	ctx context.Context,
+	req *storage.UpdateDataBlockRequest,
+) (*storage.UpdateDataBlockResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	if err := s.storageService.UpdateDataBlock(ctx, userID, updatedBlock(userID, req)); err != nil {
+		return nil, err
+	}
+
+	return storage.UpdateDataBlockResponse_builder{}.Build(), nil
+}
+
+func (s *storageGRPCServer) UpdateDataBlocks(
+	ctx context.Context,
+	req *storage.UpdateDataBlocksRequest,
+) (*storage.UpdateDataBlocksResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	blocks := make([]*model.Block, len(req.GetBlocks()))
+	for i, b := range req.GetBlocks() {
+		blocks[i] = updatedBlock(userID, b)
+	}
+	if err := s.storageService.UpdateDataBlocks(ctx, userID, blocks); err != nil {
+		return nil, err
+	}
+
+	return storage.UpdateDataBlocksResponse_builder{Updated: proto.Int32(int32(len(blocks)))}.Build(), nil
+}
+
+func updatedBlock(userID int, req *storage.UpdateDataBlockRequest) *model.Block {
+	return &model.Block{
+		ID:      int(req.GetBlockId()),
+		UserID:  userID,
+		Data:    req.GetChiphertext(),
+		Salt:    req.GetSalt(),
+		Nonce:   req.GetNonce(),
+		Profile: req.GetProfile().String(),
+		Cipher:  req.GetCipher().String(),
+		Format:  int(req.GetFormat()),
+	}
+}
+
 func (s *storageGRPCServer) ListDataBlocks(
 	req *storage.ListDataBlocksRequest,
 	stream storage.StorageService_ListDataBlocksServer,
 ) error {
 	ctx := stream.Context()
 
-	// hold connection for 10 minutes to reduce stale connections
+	// hold connection for configured time to reduce stale connections
 	// then, a new subscription can be to fetch a data updates
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(s.listTimeout.Load()))
 	defer cancel()
 	userID := ctx.Value(interceptor.UserIDKey("userID"))
 	clientID := req.GetClientId()
@@ -78,9 +139,9 @@ func (s *storageGRPCServer) ListDataBlocks(
 	}
 
 	send := func() error {
-		blocks, err := s.storageService.ListDataBlocks(userIDInt)
+		blocks, err := s.storageService.ListDataBlocks(ctxWithTimeout, userIDInt)
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to list data blocks: %v", err)
+			return err
 		}
 
 		var respBlocks []*storage.DataBlock
@@ -92,6 +153,9 @@ func (s *storageGRPCServer) ListDataBlocks(
 				Salt:        block.Salt,
 				Nonce:       block.Nonce,
 				Profile:     storage.EncProfile(utils.ProfileToProto(utils.ScryptProfile(block.Profile))).Enum(),
+				Cipher:      utils.CipherToProto(utils.Cipher(block.Cipher)).Enum(),
+				BlockUid:    proto.String(block.UID),
+				Format:      proto.Int32(int32(block.Format)),
 				Type: storage.BlockType_builder{
 					Id:          proto.Int32(int32(block.Type.ID)),
 					TypeName:    proto.String(block.Type.TypeName),
@@ -138,13 +202,9 @@ func (s *storageGRPCServer) ListBlockTypes(
 	ctx context.Context,
 	req *storage.GetBlockTypesRequest,
 ) (*storage.GetBlockTypesResponse, error) {
-	types, err := s.storageService.GetBlockTypes()
-	var appError *apperror.AppError
-	if err != nil && errors.As(err, &appError) {
-		return nil, status.Errorf(appError.GRPCStatus, "%s", appError.Message)
-	}
+	types, err := s.storageService.GetBlockTypes(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list block types: %v", err)
+		return nil, err
 	}
 
 	var respTypes []*storage.BlockType
@@ -167,3 +227,74 @@ func (s *storageGRPCServer) ListBlockTypes(
 
 	return resp, nil
 }
+
+func (s *storageGRPCServer) GetUsage(
+	ctx context.Context,
+	req *storage.GetUsageRequest,
+) (*storage.GetUsageResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	usage, err := s.storageService.GetUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeQuotas []*storage.BlockTypeQuota
+	for _, q := range usage.TypeQuotas {
+		typeQuotas = append(typeQuotas, storage.BlockTypeQuota_builder{
+			Type: storage.BlockType_builder{
+				Id:          proto.Int32(int32(q.Type.ID)),
+				TypeName:    proto.String(q.Type.TypeName),
+				Description: proto.String(q.Type.Description),
+			}.Build(),
+			MaxBlockSize: proto.Int64(int64(q.MaxBlockSize)),
+		}.Build())
+	}
+
+	b := storage.GetUsageResponse_builder{
+		UsedBytes:  proto.Int64(int64(usage.Bytes)),
+		MaxBytes:   proto.Int64(int64(usage.MaxBytes)),
+		BlockCount: proto.Int32(int32(usage.Blocks)),
+		MaxBlocks:  proto.Int32(int32(usage.MaxBlocks)),
+		TypeQuotas: typeQuotas,
+	}
+
+	return b.Build(), nil
+}
+
+func (s *storageGRPCServer) GetVaultKey(
+	ctx context.Context,
+	req *storage.GetVaultKeyRequest,
+) (*storage.GetVaultKeyResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	wrapped, err := s.storageService.GetVaultKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.GetVaultKeyResponse_builder{WrappedKey: wrapped}.Build(), nil
+}
+
+func (s *storageGRPCServer) SetVaultKey(
+	ctx context.Context,
+	req *storage.SetVaultKeyRequest,
+) (*storage.SetVaultKeyResponse, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey("userID")).(int)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID")
+	}
+
+	err := s.storageService.SetVaultKey(ctx, userID, req.GetWrappedKey(), req.GetExpectedWrappedKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.SetVaultKeyResponse_builder{}.Build(), nil
+}
@@ -1,19 +1,27 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 
 	apigrpc "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/api/grpc"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
-	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/interceptor"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/logger"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/metrics"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/admin"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/auth"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/subscription"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/service"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -22,28 +30,44 @@ type services struct {
 	storageServer      storage.StorageServiceServer
 	authServer         auth.AuthServiceServer
 	subscriptionServer subscription.SubscriptionServiceServer
+	adminServer        admin.AdminServiceServer
 }
 
 type Application struct {
-	grpcServers services
-	conf        *config.Server
-	srv         *grpc.Server
-	logger      *zap.SugaredLogger
+	grpcServers     services
+	conf            *config.Server
+	srv             *grpc.Server
+	repositories    *repositories
+	metricsSrv      *http.Server
+	logger          *zap.SugaredLogger
+	shutdownTracing tracing.ShutdownFn
+	jwtSecret       *secrets.Secret
+	dbPassword      *secrets.Secret
+	// startConf is config the server was started with, it's used
+	// to detect changes requiring restart
+	startConf *config.Config
+	// reloaders apply reloadable settings to components
+	reloaders []func(conf *config.Config) error
 }
 
 // TODO: add logger
 // TODO: add graceful shutdown
 // TODO: add migrations
-func New(config *config.Config) *Application {
+func New(conf *config.Config) *Application {
 	app := &Application{}
-	app.conf = &config.Server
-	dbConfig := config.Database
-	db, err := database.NewSQLDriver(&dbConfig)
+	app.conf = &conf.Server
+	app.startConf = conf
+	app.jwtSecret = secrets.NewSecret([]byte(conf.Server.JWT.SecretKey))
+	app.dbPassword = secrets.NewSecret([]byte(conf.Database.Password))
+	dbConfig := conf.Database
+	repos, err := newRepositories(&dbConfig, app.dbPassword)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		log.Fatalf("failed to set up database: %v", err)
 	}
 
-	l, err := zap.NewProduction()
+	app.repositories = repos
+
+	l, logLevel, err := logger.New(conf.Server.LogLevel)
 	if err != nil {
 		log.Fatalf("failed to create logger: %v", err)
 	}
@@ -51,17 +75,40 @@ func New(config *config.Config) *Application {
 	defer l.Sync()
 	app.logger = l.Sugar()
 
+	app.shutdownTracing, err = tracing.Setup(conf.Server.Tracing, "gophkeeper-server")
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
 	// repositories
-	storageRepository := repository.NewStorageRepository(db)
-	userRepository := repository.NewUserRepository(db)
+	storageRepository := repos.storage
+	userRepository := repos.users
 	subscriptionRepository := repository.NewSubscriptionRepository()
 
+	// metrics
+	appMetrics := metrics.New()
+	repos.registerMetrics(appMetrics)
+	appMetrics.RegisterSubscribers(subscriptionRepository)
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", appMetrics.Handler())
+	app.metricsSrv = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", conf.Server.Metrics.Host, conf.Server.Metrics.Port),
+		Handler: metricsMux,
+	}
+
 	// services
-	subscriptionService := service.NewSubscriptionService(subscriptionRepository)
+	subscriptionService := service.NewSubscriptionService(
+		service.SubscriptionServiceArgs{
+			SubscriptionRepository: subscriptionRepository,
+			Metrics:                appMetrics,
+			Logger:                 app.logger,
+		},
+	)
 	storageService := service.NewStorageService(
 		service.StorageServiceArgs{
 			StorageRepository:   storageRepository,
 			SubscriptionService: subscriptionService,
+			Quotas:              conf.Server.Quotas,
 			Logger:              app.logger,
 		},
 	)
@@ -70,31 +117,91 @@ func New(config *config.Config) *Application {
 		service.AuthServiceArgs{
 			UserRepository: userRepository,
 			Logger:         app.logger,
-			JWTSecret:      []byte(config.Server.JWT.SecretKey),
+			JWTSecret:      app.jwtSecret,
+		},
+	)
+
+	adminService := service.NewAdminService(
+		service.AdminServiceArgs{
+			Level:        logLevel,
+			AdminUserIDs: conf.Server.Admin.UserIDs,
+			Logger:       app.logger,
 		},
 	)
 
 	// gRPC servers
-	grpcStorageServer := apigrpc.NewStorageGRPCServer(storageService, subscriptionService)
+	grpcStorageServer := apigrpc.NewStorageGRPCServer(
+		storageService,
+		subscriptionService,
+		conf.Server.Streams.ListTimeout,
+	)
 	grpcAuthServer := apigrpc.NewAuthGRPCServer(authService)
 	grpcSubscriptionServer := apigrpc.NewSubscriptionGRPCServer(subscriptionService)
 	app.grpcServers.storageServer = grpcStorageServer
 	app.grpcServers.authServer = grpcAuthServer
 	app.grpcServers.subscriptionServer = grpcSubscriptionServer
+	app.grpcServers.adminServer = apigrpc.NewAdminGRPCServer(adminService)
+
+	limiter := interceptor.NewLimiter(conf.Server.Limits)
+	app.reloaders = []func(conf *config.Config) error{
+		func(conf *config.Config) error { return adminService.ApplyConfig(&conf.Server) },
+		func(conf *config.Config) error {
+			limiter.Update(conf.Server.Limits)
+			return nil
+		},
+		func(conf *config.Config) error {
+			storageService.SetQuotas(conf.Server.Quotas)
+			return nil
+		},
+		func(conf *config.Config) error {
+			grpcStorageServer.SetListTimeout(conf.Server.Streams.ListTimeout)
+			return nil
+		},
+		func(conf *config.Config) error {
+			app.reloadSecrets(conf)
+			return nil
+		},
+	}
 
 	app.srv = grpc.NewServer(
-		grpc.UnaryInterceptor(interceptor.UnaryAuthInterceptor([]byte(config.Server.JWT.SecretKey))),
-		grpc.StreamInterceptor(interceptor.StreamAuthInterceptor([]byte(config.Server.JWT.SecretKey))),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.MaxRecvMsgSize(conf.Server.Limits.ServerMaxRecvMsgSize()),
+		grpc.ChainUnaryInterceptor(
+			interceptor.UnaryMetricsInterceptor(appMetrics),
+			interceptor.UnaryLoggingInterceptor(l),
+			interceptor.UnaryRecoveryInterceptor(),
+			interceptor.UnaryErrorInterceptor(),
+			interceptor.UnaryAuthInterceptor(app.jwtSecret),
+			limiter.UnaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			interceptor.StreamMetricsInterceptor(appMetrics),
+			interceptor.StreamLoggingInterceptor(l),
+			interceptor.StreamRecoveryInterceptor(),
+			interceptor.StreamErrorInterceptor(),
+			interceptor.StreamAuthInterceptor(app.jwtSecret),
+			limiter.StreamInterceptor(),
+		),
 	)
 
 	auth.RegisterAuthServiceServer(app.srv, app.grpcServers.authServer)
 	storage.RegisterStorageServiceServer(app.srv, app.grpcServers.storageServer)
 	subscription.RegisterSubscriptionServiceServer(app.srv, app.grpcServers.subscriptionServer)
+	admin.RegisterAdminServiceServer(app.srv, app.grpcServers.adminServer)
 
 	return app
 }
 
 func (a *Application) Start() error {
+	if a.conf.Metrics.Port != 0 {
+		go func() {
+			log.Printf("Starting metrics server on %s...", a.metricsSrv.Addr)
+			if err := a.metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("Starting gRPC server...")
 	addr := fmt.Sprintf("%s:%d", a.conf.Host, a.conf.Port)
 
@@ -103,11 +210,59 @@ func (a *Application) Start() error {
 		return err
 	}
 
+	return a.Serve(l)
+}
+
+// Serve accepts gRPC connections on l until the server is stopped,
+// metrics server isn't started. It lets tests serve over in-memory listeners.
+func (a *Application) Serve(l net.Listener) error {
 	return a.srv.Serve(l)
 }
 
+// Reload applies reloadable settings of conf: log level, limits, quotas,
+// stream timeouts, admins and secrets. Changes of other settings are reported
+// and ignored until restart.
+func (a *Application) Reload(conf *config.Config) {
+	if keys := conf.NonReloadableChanges(a.startConf, config.AppServer); len(keys) > 0 {
+		a.logger.Errorw("config changes require restart and are ignored", "keys", keys)
+	}
+	if size, startSize := conf.Server.Limits.ServerMaxRecvMsgSize(), a.startConf.Server.Limits.ServerMaxRecvMsgSize(); size > startSize {
+		a.logger.Warnw("message size limits above transport limit apply after restart", "limit", startSize)
+	}
+
+	var errs []error
+	for _, reload := range a.reloaders {
+		errs = append(errs, reload(conf))
+	}
+	if err := errors.Join(errs...); err != nil {
+		a.logger.Errorw("failed to apply config", "error", err)
+		return
+	}
+
+	a.logger.Info("config reloaded")
+}
+
+// reloadSecrets rotates JWT secret and database password. Tokens signed
+// with the previous JWT secret stay valid for the configured TTL, new
+// database connections use the new password while open ones are kept.
+func (a *Application) reloadSecrets(conf *config.Config) {
+	if a.jwtSecret.Set([]byte(conf.Server.JWT.SecretKey), conf.Server.JWT.PreviousSecretTTL) {
+		a.logger.Infow("JWT secret rotated", "previous_secret_ttl", conf.Server.JWT.PreviousSecretTTL)
+	}
+	if a.dbPassword.Set([]byte(conf.Database.Password), 0) {
+		a.logger.Info("database password rotated")
+	}
+}
+
 // TODO: make graceful shutdown
 func (a *Application) Shudown() {
 	a.srv.GracefulStop()
+	if err := a.metricsSrv.Shutdown(context.Background()); err != nil {
+		log.Printf("failed to stop metrics server: %v", err)
+	}
+	a.repositories.close()
+	if err := a.shutdownTracing(context.Background()); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
 	log.Println("gRPC servers stopped")
 }
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/metrics"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+)
+
+type userRepository interface {
+	ports.UserRepositoryReader
+	ports.UserRepositoryWriter
+}
+
+// repositories are persistent repositories of the configured database engine.
+type repositories struct {
+	storage ports.StorageRepository
+	users   userRepository
+	// registerMetrics exposes connection pool statistics
+	registerMetrics func(m *metrics.Metrics)
+	close           func()
+}
+
+func newRepositories(conf *config.Database, password *secrets.Secret) (*repositories, error) {
+	switch conf.Engine {
+	case config.DatabaseEngineMemory:
+		return &repositories{
+			storage:         repository.NewMemoryStorageRepository(),
+			users:           repository.NewMemoryUserRepository(),
+			registerMetrics: func(*metrics.Metrics) {},
+			close:           func() {},
+		}, nil
+	case config.DatabaseEngineSQLite:
+		db, err := database.NewSQLiteDriver(conf)
+		if err != nil {
+			return nil, err
+		}
+		if err := prepareSchema(conf, db.Conn); err != nil {
+			db.Conn.Close()
+			return nil, err
+		}
+
+		return &repositories{
+			storage:         repository.NewSQLiteStorageRepository(db),
+			users:           repository.NewSQLiteUserRepository(db),
+			registerMetrics: func(m *metrics.Metrics) { m.RegisterDBStats(db.Conn, conf.Path) },
+			close:           func() { db.Conn.Close() },
+		}, nil
+	default:
+		if conf.Migrate != config.DatabaseMigrateOff {
+			// migrations run over a separate database/sql connection
+			sqlDriver, err := database.NewSQLDriver(conf, password)
+			if err != nil {
+				return nil, err
+			}
+
+			err = prepareSchema(conf, sqlDriver.Conn)
+			sqlDriver.Conn.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		db, err := database.NewPgxDriver(conf, password)
+		if err != nil {
+			return nil, err
+		}
+
+		return &repositories{
+			storage:         repository.NewStorageRepository(db),
+			users:           repository.NewUserRepository(db),
+			registerMetrics: func(m *metrics.Metrics) { m.RegisterPoolStats(db.Pool, conf.DBName) },
+			close:           db.Close,
+		}, nil
+	}
+}
+
+// prepareSchema checks schema version or applies pending migrations
+// according to database.migrate mode.
+func prepareSchema(conf *config.Database, db *sql.DB) error {
+	if conf.Migrate == config.DatabaseMigrateOff {
+		return nil
+	}
+
+	migrator, err := database.NewMigrator(conf.Engine, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if conf.Migrate == config.DatabaseMigrateUp {
+		if err := migrator.Up(); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+
+	return migrator.Check()
+}
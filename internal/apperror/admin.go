@@ -0,0 +1,21 @@
+package apperror
+
+import "google.golang.org/grpc/codes"
+
+var AdminPermissionDeniedError = &AppError{
+	Message:    "admin permission required",
+	GRPCStatus: codes.PermissionDenied,
+	Reason:     "ADMIN_PERMISSION_DENIED",
+}
+
+var AdminSettingNotReloadableError = &AppError{
+	Message:    "setting can't be changed at runtime, it requires server restart",
+	GRPCStatus: codes.FailedPrecondition,
+	Reason:     "ADMIN_SETTING_NOT_RELOADABLE",
+}
+
+var AdminSettingUnsupportedError = &AppError{
+	Message:    "setting can be changed with config file reload only",
+	GRPCStatus: codes.InvalidArgument,
+	Reason:     "ADMIN_SETTING_UNSUPPORTED",
+}
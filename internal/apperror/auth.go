@@ -7,30 +7,39 @@ import (
 )
 
 func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("code: %d, %s: %v", e.GRPCStatus, e.Message, e.Err)
+	}
+
 	return fmt.Sprintf("code: %d, %s", e.GRPCStatus, e.Message)
 }
 
 var AuthInvalidCredentialsError = &AppError{
 	Message:    "invalid credentials",
 	GRPCStatus: codes.Unauthenticated,
+	Reason:     "AUTH_INVALID_CREDENTIALS",
 }
 
 var AuthUserNotExistsError = &AppError{
 	Message:    "user does not exist",
 	GRPCStatus: codes.NotFound,
+	Reason:     "AUTH_USER_NOT_FOUND",
 }
 
 var AuthUserExistsError = &AppError{
 	Message:    "user already exists",
 	GRPCStatus: codes.AlreadyExists,
+	Reason:     "AUTH_USER_EXISTS",
 }
 
 var AuthCreateUserError = &AppError{
 	Message:    "failed to create user",
 	GRPCStatus: codes.Internal,
+	Reason:     "AUTH_CREATE_USER_FAILED",
 }
 
 var AuthErrorGeneric = &AppError{
 	Message:    "generic authentication error",
 	GRPCStatus: codes.Internal,
+	Reason:     "AUTH_INTERNAL",
 }
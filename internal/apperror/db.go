@@ -9,3 +9,7 @@ func (e *DBError) Error() string {
 }
 
 var DBErrorNoRows = &DBError{Message: "no rows in result set"}
+
+var DBErrorQuotaBlocks = &DBError{Message: "block count quota exceeded"}
+
+var DBErrorQuotaBytes = &DBError{Message: "storage size quota exceeded"}
@@ -1,8 +1,60 @@
 package apperror
 
-import "google.golang.org/grpc/codes"
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrorDomain is reported in ErrorInfo details of every application error.
+const ErrorDomain = "gophkeeper"
 
 type AppError struct {
 	Message    string
 	GRPCStatus codes.Code
+	// Reason is a stable UPPER_SNAKE_CASE code sent to clients as ErrorInfo reason
+	Reason string
+	// Violations describe invalid request fields, sent as BadRequest details
+	Violations []FieldViolation
+	// RetryAfter hints clients when a request may be retried, sent as RetryInfo
+	RetryAfter time.Duration
+	// Err is an underlying cause, it's logged but never sent to clients
+	Err error
+}
+
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Wrap returns a copy of the error carrying the cause,
+// the copy still matches the original error with errors.Is.
+func (e *AppError) Wrap(err error) *AppError {
+	wrapped := *e
+	wrapped.Err = err
+
+	return &wrapped
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	if !ok {
+		return false
+	}
+
+	return t.Reason == e.Reason && t.GRPCStatus == e.GRPCStatus && t.Message == e.Message
+}
+
+// NewValidationError builds InvalidArgument error with given field violations.
+func NewValidationError(violations ...FieldViolation) *AppError {
+	return &AppError{
+		Message:    "invalid request",
+		GRPCStatus: codes.InvalidArgument,
+		Reason:     "INVALID_ARGUMENT",
+		Violations: violations,
+	}
 }
@@ -0,0 +1,27 @@
+package apperror
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+var LimitMessageSizeError = &AppError{
+	Message:    "request message is too large",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "LIMIT_MESSAGE_SIZE",
+}
+
+var LimitStreamsPerUserError = &AppError{
+	Message:    "too many concurrent streams for user",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "LIMIT_STREAMS_PER_USER",
+	RetryAfter: 5 * time.Second,
+}
+
+var LimitStreamsPerClientError = &AppError{
+	Message:    "too many concurrent block list streams for client",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "LIMIT_STREAMS_PER_CLIENT",
+	RetryAfter: 5 * time.Second,
+}
@@ -0,0 +1,21 @@
+package apperror
+
+import "google.golang.org/grpc/codes"
+
+var QuotaBytesExceededError = &AppError{
+	Message:    "storage size quota exceeded",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "QUOTA_BYTES_EXCEEDED",
+}
+
+var QuotaBlocksExceededError = &AppError{
+	Message:    "block count quota exceeded",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "QUOTA_BLOCKS_EXCEEDED",
+}
+
+var QuotaBlockSizeExceededError = &AppError{
+	Message:    "block is larger than allowed for its type",
+	GRPCStatus: codes.ResourceExhausted,
+	Reason:     "QUOTA_BLOCK_SIZE_EXCEEDED",
+}
@@ -0,0 +1,47 @@
+package apperror
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Status converts the error into gRPC status with ErrorInfo, BadRequest and
+// RetryInfo details. Underlying cause is not included.
+func (e *AppError) Status() *status.Status {
+	st := status.New(e.GRPCStatus, e.Message)
+	var details []protoadapt.MessageV1
+	if e.Reason != "" {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason: e.Reason,
+			Domain: ErrorDomain,
+		})
+	}
+	if len(e.Violations) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(e.Violations))
+		for _, v := range e.Violations {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(e.RetryAfter),
+		})
+	}
+	if len(details) == 0 {
+		return st
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
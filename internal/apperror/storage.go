@@ -1,28 +1,80 @@
 package apperror
 
-import "google.golang.org/grpc/codes"
+import (
+	"time"
 
-var StorageCreateBlockError = AppError{
+	"google.golang.org/grpc/codes"
+)
+
+var StorageCreateBlockError = &AppError{
 	Message:    "failed to create storage block",
 	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_CREATE_BLOCK_FAILED",
 }
 
-var StorageListDataBlockError = AppError{
+var StorageUpdateBlockError = &AppError{
+	Message:    "failed to update storage block",
+	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_UPDATE_BLOCK_FAILED",
+}
+
+var StorageListDataBlockError = &AppError{
 	Message:    "failed to list data blocks",
 	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_LIST_BLOCKS_FAILED",
 }
 
-var StorageErrorGeneric = AppError{
+var StorageErrorGeneric = &AppError{
 	Message:    "generic storage error",
 	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_INTERNAL",
 }
 
-var StorageErrorNotFound = AppError{
+var StorageErrorNotFound = &AppError{
 	Message:    "data not found",
 	GRPCStatus: codes.NotFound,
+	Reason:     "STORAGE_NOT_FOUND",
 }
 
-var StorageReadBlockTypeError = AppError{
+var StorageReadBlockTypeError = &AppError{
 	Message:    "failed to read block type",
 	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_READ_BLOCK_TYPES_FAILED",
+}
+
+var StorageUnavailableError = &AppError{
+	Message:    "storage is temporarily unavailable",
+	GRPCStatus: codes.Unavailable,
+	Reason:     "STORAGE_UNAVAILABLE",
+	RetryAfter: time.Second,
+}
+
+var StorageReadUsageError = &AppError{
+	Message:    "failed to read storage usage",
+	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_READ_USAGE_FAILED",
+}
+
+var StorageReadVaultKeyError = &AppError{
+	Message:    "failed to read vault key",
+	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_READ_VAULT_KEY_FAILED",
+}
+
+var StorageWriteVaultKeyError = &AppError{
+	Message:    "failed to write vault key",
+	GRPCStatus: codes.Internal,
+	Reason:     "STORAGE_WRITE_VAULT_KEY_FAILED",
+}
+
+var StorageVaultKeyNotFoundError = &AppError{
+	Message:    "vault key is not set up",
+	GRPCStatus: codes.NotFound,
+	Reason:     "STORAGE_VAULT_KEY_NOT_FOUND",
+}
+
+var StorageVaultKeyConflictError = &AppError{
+	Message:    "vault key has been changed by another client",
+	GRPCStatus: codes.FailedPrecondition,
+	Reason:     "STORAGE_VAULT_KEY_CONFLICT",
 }
@@ -67,23 +67,19 @@ func (abv *addBlockView) listenForTypes() tea.Cmd {
 	}
 }
 
-func (r *addBlockView) SaveBlock(
-	title string,
-	typeID int,
-	profile utils.ScryptProfile,
-	data []byte,
-	salt []byte,
-	nonce []byte,
-) error {
+func (r *addBlockView) SaveBlock(block *model.Block) error {
 	md := metadata.Pairs("authorization", r.State.Token)
 	ctx := metadata.NewOutgoingContext(context.Background(), md)
 	req := storage.SaveDataBlockRequest_builder{
-		Title:       proto.String(title),
-		TypeId:      proto.Int32(int32(typeID)),
-		Chiphertext: data,
-		Salt:        salt,
-		Nonce:       nonce,
-		Profile:     storage.EncProfile(utils.ProfileToProto(profile)).Enum(),
+		Title:       proto.String(block.Title),
+		TypeId:      proto.Int32(int32(block.TypeID)),
+		Chiphertext: block.Data,
+		Salt:        block.Salt,
+		Nonce:       block.Nonce,
+		Profile:     storage.EncProfile(utils.ProfileToProto(utils.ScryptProfile(block.Profile))).Enum(),
+		Cipher:      utils.CipherToProto(utils.Cipher(block.Cipher)).Enum(),
+		BlockUid:    proto.String(block.UID),
+		Format:      proto.Int32(int32(block.Format)),
 	}
 
 	_, err := r.client.StorageClient.SaveDataBlock(ctx, req.Build())
@@ -144,6 +140,8 @@ func (abv *addBlockView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					State:       abv.State,
 					Type:        *selectedType,
 					SaveBlockCb: abv.SaveBlock,
+					Cipher:      abv.State.Cipher,
+					Profile:     abv.State.Profile,
 				})
 
 				m.SetPrevModel(abv)
@@ -157,6 +155,8 @@ func (abv *addBlockView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						State:       abv.State,
 						Type:        *selectedType,
 						SaveBlockCb: abv.SaveBlock,
+						Cipher:      abv.State.Cipher,
+						Profile:     abv.State.Profile,
 					},
 				)
 				m.SetPrevModel(abv)
@@ -169,6 +169,8 @@ func (abv *addBlockView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						State:       abv.State,
 						Type:        *selectedType,
 						SaveBlockCb: abv.SaveBlock,
+						Cipher:      abv.State.Cipher,
+						Profile:     abv.State.Profile,
 					},
 				)
 				m.SetPrevModel(abv)
@@ -182,6 +184,8 @@ func (abv *addBlockView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						State:       abv.State,
 						Type:        *selectedType,
 						SaveBlockCb: abv.SaveBlock,
+						Cipher:      abv.State.Cipher,
+						Profile:     abv.State.Profile,
 					},
 				)
 				m.SetPrevModel(abv)
@@ -6,9 +6,11 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/auth"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -112,8 +114,18 @@ func (rm *authModel) submit() error {
 		return err
 	}
 
+	userID, err := utils.TokenUserID(token)
+	if err != nil {
+		return err
+	}
+
+	if rm.state.UserID != userID {
+		// keys of the previous user must not outlive their session
+		rm.state.DropSecrets()
+	}
 	rm.state.IsAuthorized = true
 	rm.state.Token = token
+	rm.state.UserID = userID
 
 	return nil
 }
@@ -170,6 +182,8 @@ func (rm *authModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			rm.inputs[rm.focused].Focus()
 			return rm, nil
 		case tea.KeyCtrlC:
+			rm.state.DropSecrets()
+
 			return rm, tea.Quit
 		}
 	}
@@ -182,7 +196,7 @@ func (rm *authModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (rm *authModel) View() string {
 	var errText string
 	if rm.err != nil {
-		errText = fmt.Sprintf("Error: %v, press ESC to retry\n", rm.err)
+		errText = fmt.Sprintf("Error: %s\npress ESC to retry\n", errfmt.Format(rm.err))
 	}
 	s := "\n== " + rm.title + " ==\n"
 	s += errText
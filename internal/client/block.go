@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"mime"
 	"net/http"
@@ -11,20 +13,52 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 )
 
 type blockModel struct {
-	prevModel     types.NamedTeaModel
-	block         model.Block
-	passInput     textinput.Model
-	focused       int
-	decryptedText []byte
-	err           error
+	prevModel  types.NamedTeaModel
+	state      *types.State
+	grpcClient *grpc.GRPCClient
+	block      model.Block
+	passInput  textinput.Model
+	focused    int
+	decrypted  *secure.Buffer
+	fileSaved  bool
+	// confirmDrop is set after the first ctrl+x, the second one removes
+	// the block password
+	confirmDrop bool
+	err         error
+	worker      worker.Worker
 }
 
-func NewBlockModel(prevModel types.NamedTeaModel) *blockModel {
+type msgBlockOpened struct {
+	decrypted  *secure.Buffer
+	upgraded   *model.Block
+	err        error
+	upgradeErr error
+}
+
+type msgPasswordDropped struct {
+	upgraded *model.Block
+	err      error
+}
+
+// NewBlockModel opens the block view, blocks sealed with the vault key only
+// are decrypted on Init, others ask for the block password.
+func NewBlockModel(
+	prevModel types.NamedTeaModel,
+	state *types.State,
+	grpcClient *grpc.GRPCClient,
+	block model.Block,
+) *blockModel {
 	passwordInput := textinput.New()
 	passwordInput.Placeholder = "Enter block password"
 	passwordInput.EchoMode = textinput.EchoPassword
@@ -33,10 +67,171 @@ func NewBlockModel(prevModel types.NamedTeaModel) *blockModel {
 	passwordInput.Width = 30
 	passwordInput.Focus()
 
-	return &blockModel{
-		prevModel: prevModel,
-		passInput: passwordInput,
+	bm := &blockModel{
+		prevModel:  prevModel,
+		state:      state,
+		grpcClient: grpcClient,
+		block:      block,
+		passInput:  passwordInput,
+		worker:     worker.New(),
 	}
+
+	return bm
+}
+
+// openBlock decrypts the block in a worker, password is empty for blocks
+// sealed with the vault key only. Blocks not sealed with the vault key are
+// upgraded to it, keeping their password. The worker owns password, it's
+// destroyed when done.
+func openBlock(grpcClient *grpc.GRPCClient, state *types.State, block model.Block, password *secure.Buffer) tea.Cmd {
+	vaultKey := state.VaultKey.Clone()
+	token, userID, cache := state.Token, state.UserID, state.KeyCache
+
+	return func() tea.Msg {
+		defer password.Destroy()
+		defer vaultKey.Destroy()
+
+		keys := utils.EnvelopeKeys{VaultKey: vaultKey.Bytes(), Cache: cache}
+		if password.Len() > 0 {
+			keys.Password = password.Bytes()
+		}
+
+		plaintext, err := utils.OpenBlock(&block, keys)
+		if err != nil {
+			return msgBlockOpened{err: err}
+		}
+
+		msg := msgBlockOpened{decrypted: secure.FromBytes(plaintext)}
+		if !utils.BlockUsesVaultKey(&block) {
+			msg.upgraded, msg.upgradeErr = upgradeBlock(grpcClient, token, userID, vaultKey, password, block, msg.decrypted)
+		}
+
+		return msg
+	}
+}
+
+// dropPassword reseals the opened block with the vault key only in a
+// worker, the user asked to remove its password.
+func dropPassword(grpcClient *grpc.GRPCClient, state *types.State, block model.Block, payload *secure.Buffer) tea.Cmd {
+	vaultKey := state.VaultKey.Clone()
+	token, userID := state.Token, state.UserID
+
+	return func() tea.Msg {
+		defer payload.Destroy()
+		defer vaultKey.Destroy()
+
+		if vaultKey == nil {
+			return msgPasswordDropped{err: fmt.Errorf("vault is locked")}
+		}
+
+		upgraded, err := upgradeBlock(grpcClient, token, userID, vaultKey, nil, block, payload)
+
+		return msgPasswordDropped{upgraded: upgraded, err: err}
+	}
+}
+
+// upgradeBlock re-encrypts a block into the current format with the vault
+// key, and with password too if it's set, so a block sealed with its
+// password only keeps needing it. The cipher is kept, the password key
+// keeps the block's argon2id profile or gets the default one.
+func upgradeBlock(
+	grpcClient *grpc.GRPCClient,
+	token string,
+	userID int,
+	vaultKey *secure.Buffer,
+	password *secure.Buffer,
+	block model.Block,
+	payload *secure.Buffer,
+) (*model.Block, error) {
+	keys := utils.EnvelopeKeys{VaultKey: vaultKey.Bytes()}
+	profile := utils.DefaultProfile
+	if password.Len() > 0 {
+		keys.Password = password.Bytes()
+		if p, ok := utils.ScryptProfiles[utils.ScryptProfile(block.Profile)]; ok && p.Algo == utils.AlgoArgon2id {
+			profile = utils.ScryptProfile(block.Profile)
+		}
+	}
+
+	upgraded := block
+	upgraded.UserID = userID
+	err := utils.SealBlock(&upgraded, keys, payload.Bytes(), profile, utils.Cipher(upgraded.Cipher))
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata.Pairs("authorization", token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	req := storage.UpdateDataBlockRequest_builder{
+		BlockId:     proto.Int32(int32(upgraded.ID)),
+		Chiphertext: upgraded.Data,
+		Salt:        upgraded.Salt,
+		Nonce:       upgraded.Nonce,
+		Profile:     utils.ProfileToProto(utils.ScryptProfile(upgraded.Profile)).Enum(),
+		Cipher:      utils.CipherToProto(utils.Cipher(upgraded.Cipher)).Enum(),
+		Format:      proto.Int32(int32(upgraded.Format)),
+	}
+
+	_, err = grpcClient.StorageClient.UpdateDataBlock(ctx, req.Build())
+	if err != nil {
+		return nil, err
+	}
+
+	return &upgraded, nil
+}
+
+// dropped replaces the block with the one resealed without password.
+func (bm *blockModel) dropped(msg msgPasswordDropped) {
+	if msg.err != nil {
+		bm.err = fmt.Errorf("Password removal failed: %v", msg.err)
+
+		return
+	}
+
+	bm.block = *msg.upgraded
+}
+
+// canDropPassword reports whether the opened block has a password that
+// can be removed, file blocks keep no plaintext to reseal.
+func (bm *blockModel) canDropPassword() bool {
+	return bm.decrypted != nil && !bm.worker.Busy() && utils.BlockNeedsPassword(&bm.block)
+}
+
+func (bm *blockModel) opened(msg msgBlockOpened) {
+	if errors.Is(msg.err, utils.ErrVaultKeyRequired) {
+		bm.err = fmt.Errorf("Vault is locked")
+
+		return
+	}
+	if msg.err != nil {
+		bm.err = fmt.Errorf("Invalid password")
+		bm.passInput.Reset()
+
+		return
+	}
+
+	bm.decrypted = msg.decrypted
+	if msg.upgraded != nil {
+		bm.block = *msg.upgraded
+	}
+	if msg.upgradeErr != nil {
+		bm.err = fmt.Errorf("Block format upgrade failed: %v", msg.upgradeErr)
+	}
+
+	if bm.block.Type.TypeName == string(model.TypeNameFile) {
+		bm.err = bm.SaveFileBlockToDisk(bm.block.Title, bm.decrypted.Bytes())
+		// the file is on disk now, the plaintext isn't shown
+		bm.fileSaved = true
+		bm.decrypted.Destroy()
+		bm.decrypted = nil
+	}
+}
+
+// close drops the plaintext and the typed password when the user leaves
+// the block view.
+func (bm *blockModel) close() {
+	bm.decrypted.Destroy()
+	bm.decrypted = nil
+	bm.passInput.Reset()
 }
 
 func (bm *blockModel) SaveFileBlockToDisk(blockName string, content []byte) error {
@@ -65,44 +260,61 @@ func (bm *blockModel) SaveFileBlockToDisk(blockName string, content []byte) erro
 }
 
 func (bm *blockModel) Init() tea.Cmd {
+	if !utils.BlockNeedsPassword(&bm.block) {
+		return bm.worker.Start("Decrypting block...", openBlock(bm.grpcClient, bm.state, bm.block, nil))
+	}
+
 	return textinput.Blink
 }
 
 func (bm *blockModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := bm.worker.Update(msg); handled {
+		return bm, cmd
+	}
+
 	switch msg := msg.(type) {
+	case msgBlockOpened:
+		bm.worker.Done()
+		bm.opened(msg)
+
+		return bm, nil
+	case msgPasswordDropped:
+		bm.worker.Done()
+		bm.dropped(msg)
+
+		return bm, nil
 	case tea.KeyMsg:
+		if msg.String() != "ctrl+x" {
+			bm.confirmDrop = false
+		}
 		switch msg.String() {
-		case "esc":
-			return bm.prevModel, nil
-		case "enter":
-			bm.err = nil
-			password := bm.passInput.Value()
-			decrypted, err := utils.DecryptWithPassword(
-				bm.block.Data,
-				[]byte(bm.block.Nonce),
-				[]byte(password),
-				[]byte(bm.block.Salt),
-				utils.ScryptProfile(bm.block.Profile),
-			)
-			if err != nil {
-				bm.err = fmt.Errorf("Invalid password")
-				bm.passInput.Reset()
-
+		case "ctrl+x":
+			if !bm.canDropPassword() {
 				return bm, nil
 			}
+			if !bm.confirmDrop {
+				bm.confirmDrop = true
 
-			bm.decryptedText = decrypted
+				return bm, nil
+			}
+			bm.confirmDrop = false
+			bm.err = nil
 
-			if bm.block.Type.TypeName == string(model.TypeNameFile) {
-				err = bm.SaveFileBlockToDisk(bm.block.Title, decrypted)
-				if err != nil {
-					bm.err = err
+			return bm, bm.worker.Start(
+				"Removing block password...",
+				dropPassword(bm.grpcClient, bm.state, bm.block, bm.decrypted.Clone()),
+			)
+		case "esc":
+			bm.close()
 
-					return bm, nil
-				}
-			}
+			return bm.prevModel, nil
+		case "enter":
+			bm.err = nil
 
-			return bm, nil
+			return bm, bm.worker.Start(
+				"Decrypting block...",
+				openBlock(bm.grpcClient, bm.state, bm.block, types.TakeSecret(&bm.passInput)),
+			)
 		}
 	}
 
@@ -118,7 +330,7 @@ func (bm *blockModel) View() string {
 		errText = fmt.Sprintf("\nError: %s", bm.err.Error())
 	}
 
-	if bm.block.Type.TypeName == string(model.TypeNameFile) && len(bm.decryptedText) != 0 {
+	if bm.fileSaved {
 		s := "File block has been decrypted and saved to disk.\n"
 		s += errText
 		s += "\n\nPress 'esc' to go back.\n"
@@ -131,10 +343,19 @@ func (bm *blockModel) View() string {
 	s += errText + "\n"
 	s += fmt.Sprintf("ID: %d | Type: %s | Title: %s\n", bm.block.ID, bm.block.Type.TypeName, bm.block.Title)
 	s += strings.Repeat("_", 40) + "\n\n"
-	if len(bm.decryptedText) > 0 {
+	if bm.decrypted != nil {
 		del := strings.Repeat("_", 40) + "\n"
-		s += fmt.Sprintf("Block data:\n%s\n%s\n", del, string(bm.decryptedText))
+		s += fmt.Sprintf("Block data:\n%s\n%s\n", del, bm.decrypted.Bytes())
 		s += strings.Repeat("_", 40) + "\n"
+		if bm.worker.Busy() {
+			s += bm.worker.View()
+		} else if bm.confirmDrop {
+			s += "\nPress 'ctrl+x' again to remove the block password, it will open with the vault key alone."
+		} else if bm.canDropPassword() {
+			s += "\nPress 'ctrl+x' to remove the block password."
+		}
+	} else if bm.worker.Busy() {
+		s += bm.worker.View()
 	} else {
 		s += bm.passInput.View()
 	}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/subscription"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/pkg/testserver"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestUpgradeBlockKeepsPassword upgrades a block sealed with its password
+// only, it must need both the vault key and the password afterwards.
+func TestUpgradeBlockKeepsPassword(t *testing.T) {
+	const userID = 1
+	var (
+		payload  = []byte("payload")
+		password = []byte("block password")
+		vaultKey = bytes.Repeat([]byte{7}, 32)
+	)
+
+	srv := testserver.New(t)
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := &grpc.GRPCClient{
+		StorageClient:      storage.NewStorageServiceClient(conn),
+		SubscriptionClient: subscription.NewSubscriptionServiceClient(conn),
+	}
+	token, err := srv.Register(context.Background(), "alice", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := testserver.WithToken(context.Background(), token)
+
+	block := model.Block{
+		UID:    uuid.NewString(),
+		UserID: userID,
+		TypeID: 1,
+		Type:   &model.Type{ID: 1, TypeName: string(model.TypeNameText)},
+		Title:  "note",
+	}
+	err = utils.SealBlock(&block, utils.EnvelopeKeys{Password: password}, payload, utils.ProfileArgon2idLow, utils.CipherAES256GCM)
+	if err != nil {
+		t.Fatalf("SealBlock: %v", err)
+	}
+	_, err = client.StorageClient.SaveDataBlock(ctx, storage.SaveDataBlockRequest_builder{
+		Title:       proto.String(block.Title),
+		Chiphertext: block.Data,
+		Profile:     utils.ProfileToProto(utils.ScryptProfile(block.Profile)).Enum(),
+		Cipher:      utils.CipherToProto(utils.Cipher(block.Cipher)).Enum(),
+		TypeId:      proto.Int32(int32(block.TypeID)),
+		BlockUid:    proto.String(block.UID),
+		Format:      proto.Int32(int32(block.Format)),
+	}.Build())
+	if err != nil {
+		t.Fatalf("SaveDataBlock: %v", err)
+	}
+	block.ID = int(storedBlock(t, client, ctx).GetBlockId())
+
+	upgraded, err := upgradeBlock(
+		client, token, userID, secure.FromBytes(bytes.Clone(vaultKey)), secure.FromBytes(bytes.Clone(password)),
+		block, secure.FromBytes(bytes.Clone(payload)),
+	)
+	if err != nil {
+		t.Fatalf("upgradeBlock: %v", err)
+	}
+	if !utils.BlockUsesVaultKey(upgraded) || !utils.BlockNeedsPassword(upgraded) {
+		t.Fatal("upgraded block doesn't need both the vault key and the password")
+	}
+	if !bytes.Equal(storedBlock(t, client, ctx).GetChiphertext(), upgraded.Data) {
+		t.Error("upgraded block isn't stored on the server")
+	}
+
+	if _, err := utils.OpenBlock(upgraded, utils.EnvelopeKeys{VaultKey: vaultKey}); !errors.Is(err, utils.ErrPasswordRequired) {
+		t.Errorf("OpenBlock with the vault key only: got %v, want %v", err, utils.ErrPasswordRequired)
+	}
+	got, err := utils.OpenBlock(upgraded, utils.EnvelopeKeys{VaultKey: vaultKey, Password: password})
+	if err != nil {
+		t.Fatalf("OpenBlock: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("OpenBlock = %q, want %q", got, payload)
+	}
+
+	// removing the password is explicit, it's resealed without one
+	dropped, err := upgradeBlock(
+		client, token, userID, secure.FromBytes(bytes.Clone(vaultKey)), nil,
+		*upgraded, secure.FromBytes(bytes.Clone(payload)),
+	)
+	if err != nil {
+		t.Fatalf("upgradeBlock without password: %v", err)
+	}
+	if utils.BlockNeedsPassword(dropped) {
+		t.Error("block needs a password after it was removed")
+	}
+}
+
+// storedBlock lists blocks of the user and returns the only one.
+func storedBlock(t *testing.T, client *grpc.GRPCClient, ctx context.Context) *storage.DataBlock {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	clientID := uuid.NewString()
+	_, err := client.SubscriptionClient.Subscribe(ctx, subscription.SubscribeRequest_builder{
+		ClientId: proto.String(clientID),
+	}.Build())
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	stream, err := client.StorageClient.ListDataBlocks(ctx, storage.ListDataBlocksRequest_builder{
+		ClientId: proto.String(clientID),
+	}.Build())
+	if err != nil {
+		t.Fatalf("ListDataBlocks: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("receive blocks: %v", err)
+	}
+	if len(resp.GetDataBlocks()) != 1 {
+		t.Fatalf("user has %d blocks, want 1", len(resp.GetDataBlocks()))
+	}
+
+	return resp.GetDataBlocks()[0]
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
 )
@@ -24,6 +26,9 @@ type BankCardBlock struct {
 	isSaved     bool
 	err         error
 	saveBlockCb SaveBlockFn
+	cipher      utils.Cipher
+	profile     profileSelector
+	worker      worker.Worker
 }
 
 func NewBankCardBlock(args BlockArgs) *BankCardBlock {
@@ -54,7 +59,7 @@ func NewBankCardBlock(args BlockArgs) *BankCardBlock {
 	cvvInput.Width = 5
 
 	masterPasswordInput := textinput.New()
-	masterPasswordInput.Placeholder = "Master Password"
+	masterPasswordInput.Placeholder = "Block password (optional)"
 	masterPasswordInput.EchoMode = textinput.EchoPassword
 	masterPasswordInput.EchoCharacter = '•'
 	masterPasswordInput.CharLimit = 64
@@ -72,6 +77,9 @@ func NewBankCardBlock(args BlockArgs) *BankCardBlock {
 			masterPasswordInput,
 		},
 		saveBlockCb: args.SaveBlockCb,
+		cipher:      args.Cipher,
+		profile:     newProfileSelector(args.Profile),
+		worker:      worker.New(),
 	}
 }
 
@@ -87,11 +95,39 @@ func (bcb *BankCardBlock) SetPrevModel(model types.NamedTeaModel) {
 	bcb.PrevModel = model
 }
 
+// clear drops entered card details when the block is saved or abandoned.
+func (bcb *BankCardBlock) clear() {
+	for i := range bcb.inputs {
+		bcb.inputs[i].Reset()
+	}
+	bcb.CardNumber, bcb.ExpiryDate, bcb.CardHolder, bcb.CVV = "", "", "", ""
+}
+
 func (bcb *BankCardBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := bcb.worker.Update(msg); handled {
+		return bcb, cmd
+	}
+	if bcb.profile.Update(msg) {
+		return bcb, nil
+	}
+
 	switch msg := msg.(type) {
+	case msgBlockSaved:
+		bcb.worker.Done()
+		if msg.err != nil {
+			bcb.err = msg.err
+
+			return bcb, nil
+		}
+		bcb.clear()
+		bcb.isSaved = true
+
+		return bcb, nil
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			bcb.clear()
+
 			return bcb.PrevModel, nil
 		case "enter":
 			if bcb.focused == len(bcb.inputs)-1 {
@@ -100,14 +136,7 @@ func (bcb *BankCardBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				bcb.ExpiryDate = bcb.inputs[2].Value()
 				bcb.CardHolder = bcb.inputs[3].Value()
 				bcb.CVV = bcb.inputs[4].Value()
-				masterPassword := bcb.inputs[5].Value()
-
-				key, err := utils.ExtractKeyFromPassword(masterPassword, utils.ProfileMedium)
-				if err != nil {
-					bcb.err = err
-
-					return bcb, nil
-				}
+				masterPassword := types.TakeSecret(&bcb.inputs[5])
 
 				payload := fmt.Sprintf(
 					"Title: %s\nPAN: %s\nExpiry: %s\nCard holder: %s\nCVV: %s\n",
@@ -118,30 +147,9 @@ func (bcb *BankCardBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					bcb.CVV,
 				)
 
-				ciphertext, nonce, err := utils.EncryptWithPassword([]byte(masterPassword), []byte(payload), key)
-				if err != nil {
-					bcb.err = err
-
-					return bcb, nil
-				}
-
-				err = bcb.saveBlockCb(
-					bcb.Title,
-					bcb.Type.ID,
-					utils.ProfileMedium,
-					ciphertext,
-					key.Salt,
-					nonce,
-				)
-				if err != nil {
-					bcb.err = err
-
-					return bcb, nil
-				}
-
-				bcb.isSaved = true
-
-				return bcb, nil
+				return bcb, bcb.worker.Start("Encrypting block...", saveBlock(
+					bcb.saveBlockCb, bcb.State, bcb.Type, bcb.Title, masterPassword, []byte(payload), bcb.profile.Profile(), bcb.cipher,
+				))
 			}
 
 			bcb.focused++
@@ -167,8 +175,12 @@ func (bcb *BankCardBlock) View() string {
 		}
 		s += cursor + " " + input.View() + "\n"
 	}
+	s += "\n" + bcb.profile.View() + "\n"
+	if bcb.worker.Busy() {
+		s += "\n" + bcb.worker.View() + "\n"
+	}
 	if bcb.err != nil {
-		s += "\nError: " + bcb.err.Error() + "\n"
+		s += "\nError: " + errfmt.Format(bcb.err) + "\n"
 	}
 	s += "\nPress 'Enter' to save the block or 'ESC' to cancel.\n"
 
@@ -5,7 +5,9 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
 )
@@ -22,6 +24,9 @@ type credentialsBlock struct {
 	saveBlockCb SaveBlockFn
 	err         error
 	isSaved     bool
+	cipher      utils.Cipher
+	profile     profileSelector
+	worker      worker.Worker
 }
 
 func NewCredentialsBlock(args BlockArgs) *credentialsBlock {
@@ -44,7 +49,7 @@ func NewCredentialsBlock(args BlockArgs) *credentialsBlock {
 	passwordInput.Width = 20
 
 	masterPasswordInput := textinput.New()
-	masterPasswordInput.Placeholder = "Master Password"
+	masterPasswordInput.Placeholder = "Block password (optional)"
 	masterPasswordInput.EchoMode = textinput.EchoPassword
 	masterPasswordInput.EchoCharacter = '•'
 	masterPasswordInput.CharLimit = 64
@@ -55,6 +60,9 @@ func NewCredentialsBlock(args BlockArgs) *credentialsBlock {
 		Type:        args.Type,
 		State:       args.State,
 		saveBlockCb: args.SaveBlockCb,
+		cipher:      args.Cipher,
+		profile:     newProfileSelector(args.Profile),
+		worker:      worker.New(),
 	}
 }
 
@@ -66,44 +74,51 @@ func (c *credentialsBlock) SetPrevModel(m types.NamedTeaModel) {
 	c.PrevModel = m
 }
 
+// clear drops entered credentials when the block is saved or abandoned.
+func (c *credentialsBlock) clear() {
+	for i := range c.inputs {
+		c.inputs[i].Reset()
+	}
+	c.Username, c.Password = "", ""
+}
+
 func (c *credentialsBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := c.worker.Update(msg); handled {
+		return c, cmd
+	}
+	if c.profile.Update(msg) {
+		return c, nil
+	}
+
 	switch msg := msg.(type) {
+	case msgBlockSaved:
+		c.worker.Done()
+		if msg.err != nil {
+			c.err = msg.err
+
+			return c, nil
+		}
+		c.clear()
+		c.isSaved = true
+
+		return c, nil
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			c.clear()
+
 			return c.PrevModel, nil
 		case "enter":
 			if c.focused == len(c.inputs)-1 {
 				c.Title = c.inputs[0].Value()
 				c.Username = c.inputs[1].Value()
 				c.Password = c.inputs[2].Value()
-				masterPassword := c.inputs[3].Value()
-				key, err := utils.ExtractKeyFromPassword(masterPassword, utils.ProfileMedium)
-				if err != nil {
-					c.err = err
-
-					return c, nil
-				}
-
+				masterPassword := types.TakeSecret(&c.inputs[3])
 				payload := fmt.Sprintf("username:%s / password:%s", c.Username, c.Password)
 
-				encrypted, nonce, err := utils.EncryptWithPassword([]byte(masterPassword), []byte(payload), key)
-				if err != nil {
-					c.err = err
-
-					return c, nil
-				}
-
-				err = c.saveBlockCb(c.Title, c.Type.ID, utils.ProfileMedium, encrypted, key.Salt, nonce)
-				if err != nil {
-					c.err = err
-
-					return c, nil
-				}
-
-				c.isSaved = true
-
-				return c, nil
+				return c, c.worker.Start("Encrypting block...", saveBlock(
+					c.saveBlockCb, c.State, c.Type, c.Title, masterPassword, []byte(payload), c.profile.Profile(), c.cipher,
+				))
 			}
 
 			c.focused++
@@ -126,7 +141,7 @@ func (c *credentialsBlock) View() string {
 	s := "Enter your credentials:\n\n"
 	var errText string
 	if c.err != nil {
-		errText = fmt.Sprintf("Error: %v\n\n", c.err)
+		errText = fmt.Sprintf("Error: %s\n\n", errfmt.Format(c.err))
 	}
 	s += errText
 
@@ -137,6 +152,10 @@ func (c *credentialsBlock) View() string {
 			s += "* " + input.View() + "\n"
 		}
 	}
+	s += "\n" + c.profile.View() + "\n"
+	if c.worker.Busy() {
+		s += "\n" + c.worker.View() + "\n"
+	}
 
 	s += "\nPress 'ESC' to go back. Press Enter to submit inputs.\n"
 
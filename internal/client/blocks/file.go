@@ -7,7 +7,9 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
 )
@@ -23,6 +25,10 @@ type FileBlock struct {
 	focused     int
 	PrevModel   types.NamedTeaModel
 	saveBlockFn SaveBlockFn
+	state       *types.State
+	cipher      utils.Cipher
+	profile     profileSelector
+	worker      worker.Worker
 }
 
 func NewFileBlock(args BlockArgs) *FileBlock {
@@ -38,7 +44,7 @@ func NewFileBlock(args BlockArgs) *FileBlock {
 	inputFilePath.Width = 50
 
 	masterPasswordInput := textinput.New()
-	masterPasswordInput.Placeholder = "Master Password"
+	masterPasswordInput.Placeholder = "Block password (optional)"
 	masterPasswordInput.EchoMode = textinput.EchoPassword
 	masterPasswordInput.EchoCharacter = '•'
 	masterPasswordInput.CharLimit = 64
@@ -49,6 +55,10 @@ func NewFileBlock(args BlockArgs) *FileBlock {
 		Title:       args.Title,
 		inputs:      []textinput.Model{inputDescription, inputFilePath, masterPasswordInput},
 		saveBlockFn: args.SaveBlockCb,
+		state:       args.State,
+		cipher:      args.Cipher,
+		profile:     newProfileSelector(args.Profile),
+		worker:      worker.New(),
 	}
 }
 
@@ -60,11 +70,38 @@ func (fb *FileBlock) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+// clear drops entered values when the block is saved or abandoned.
+func (fb *FileBlock) clear() {
+	for i := range fb.inputs {
+		fb.inputs[i].Reset()
+	}
+}
+
 func (fb *FileBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := fb.worker.Update(msg); handled {
+		return fb, cmd
+	}
+	if fb.profile.Update(msg) {
+		return fb, nil
+	}
+
 	switch msg := msg.(type) {
+	case msgBlockSaved:
+		fb.worker.Done()
+		if msg.err != nil {
+			fb.err = msg.err
+
+			return fb, nil
+		}
+		fb.clear()
+		fb.isSaved = true
+
+		return fb, nil
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			fb.clear()
+
 			return fb.PrevModel, nil
 		case "enter":
 			if fb.focused == len(fb.inputs)-1 {
@@ -87,31 +124,10 @@ func (fb *FileBlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return fb, nil
 				}
 
-				masterPassword := fb.inputs[2].Value()
-				key, err := utils.ExtractKeyFromPassword(masterPassword, utils.ProfileMedium)
-				if err != nil {
-					fb.err = err
-
-					return fb, nil
-				}
-
-				ciphertext, nonce, err := utils.EncryptWithPassword([]byte(masterPassword), content, key)
-				if err != nil {
-					fb.err = err
-
-					return fb, nil
-				}
-
-				err = fb.saveBlockFn(title, fb.Type.ID, utils.ProfileMedium, ciphertext, key.Salt, nonce)
-				if err != nil {
-					fb.err = err
-
-					return fb, nil
-				}
-
-				fb.isSaved = true
-
-				return fb, nil
+				masterPassword := types.TakeSecret(&fb.inputs[2])
+				return fb, fb.worker.Start("Encrypting file...", saveBlock(
+					fb.saveBlockFn, fb.state, fb.Type, title, masterPassword, content, fb.profile.Profile(), fb.cipher,
+				))
 			}
 
 			fb.focused++
@@ -133,13 +149,17 @@ func (fb *FileBlock) View() string {
 	s := "Create File Block:\n"
 	s += "------------------------------\n"
 	if fb.err != nil {
-		s += "Error: " + fb.err.Error() + "\n"
+		s += "Error: " + errfmt.Format(fb.err) + "\n"
 	}
 	s += "\n"
 
 	s += "Description: " + fb.inputs[0].View() + "\n"
 	s += "File Path: " + fb.inputs[1].View() + "\n"
 	s += "Master Password: " + fb.inputs[2].View() + "\n"
+	s += "\n" + fb.profile.View() + "\n"
+	if fb.worker.Busy() {
+		s += "\n" + fb.worker.View() + "\n"
+	}
 
 	s += "\nPress Enter to save the block or ESC to go back.\n"
 
@@ -0,0 +1,38 @@
+package blocks
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+)
+
+// profileSelector picks key derivation profile of the block password,
+// it starts with the configured one and tab cycles through the others.
+type profileSelector struct {
+	index int
+}
+
+func newProfileSelector(profile utils.ScryptProfile) profileSelector {
+	return profileSelector{index: max(slices.Index(utils.PasswordProfiles, profile), 0)}
+}
+
+// Update switches to the next profile on tab and reports whether msg is handled.
+func (ps *profileSelector) Update(msg tea.Msg) bool {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.String() != "tab" {
+		return false
+	}
+	ps.index = (ps.index + 1) % len(utils.PasswordProfiles)
+
+	return true
+}
+
+func (ps profileSelector) Profile() utils.ScryptProfile {
+	return utils.PasswordProfiles[ps.index]
+}
+
+func (ps profileSelector) View() string {
+	return fmt.Sprintf("Password profile: < %s > (tab to change)", ps.Profile())
+}
@@ -6,12 +6,20 @@ import (
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"github.com/google/uuid"
 )
 
-type SaveBlockFn func(title string, typeID int, profile utils.ScryptProfile, encryptedData, salt, nonce []byte) error
+type SaveBlockFn func(block *model.Block) error
+
+type msgBlockSaved struct {
+	err error
+}
 
 type TextBlock struct {
 	title       string
@@ -24,6 +32,9 @@ type TextBlock struct {
 	state       *types.State
 	err         error
 	saveBlockCb SaveBlockFn
+	cipher      utils.Cipher
+	profile     profileSelector
+	worker      worker.Worker
 }
 
 type BlockArgs struct {
@@ -31,11 +42,83 @@ type BlockArgs struct {
 	Type        model.Type
 	State       *types.State
 	SaveBlockCb SaveBlockFn
+	// Cipher seals new block payload
+	Cipher utils.Cipher
+	// Profile is preselected for the block password
+	Profile utils.ScryptProfile
+}
+
+// sealBlock encrypts payload into a new block with a key derived from keys,
+// password key uses the given profile. Block UID, owner, type and title
+// are bound to the ciphertext as associated data.
+func sealBlock(
+	userID int,
+	t model.Type,
+	title string,
+	keys utils.EnvelopeKeys,
+	payload []byte,
+	profile utils.ScryptProfile,
+	c utils.Cipher,
+) (*model.Block, error) {
+	block := &model.Block{
+		UID:    uuid.NewString(),
+		UserID: userID,
+		TypeID: t.ID,
+		Title:  title,
+		Type:   &t,
+	}
+
+	err := utils.SealBlock(block, keys, payload, profile, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// saveBlock seals payload into a new block with the vault key, and with
+// the block password too if it's set, and saves it in a worker, key
+// derivation would freeze the UI otherwise. The worker owns password and
+// payload, they're wiped when done.
+func saveBlock(
+	save SaveBlockFn,
+	state *types.State,
+	t model.Type,
+	title string,
+	password *secure.Buffer,
+	payload []byte,
+	profile utils.ScryptProfile,
+	c utils.Cipher,
+) tea.Cmd {
+	vaultKey := state.VaultKey.Clone()
+	cache, userID := state.KeyCache, state.UserID
+
+	return func() tea.Msg {
+		defer password.Destroy()
+		defer vaultKey.Destroy()
+		defer secure.Wipe(payload)
+
+		if vaultKey == nil {
+			return msgBlockSaved{err: fmt.Errorf("vault is locked")}
+		}
+
+		keys := utils.EnvelopeKeys{VaultKey: vaultKey.Bytes(), Cache: cache}
+		if password.Len() > 0 {
+			keys.Password = password.Bytes()
+		}
+
+		block, err := sealBlock(userID, t, title, keys, payload, profile, c)
+		if err != nil {
+			return msgBlockSaved{err: err}
+		}
+
+		return msgBlockSaved{err: save(block)}
+	}
 }
 
 func NewTextBlock(args BlockArgs) *TextBlock {
 	passwordInput := textinput.New()
-	passwordInput.Placeholder = "Enter block password"
+	passwordInput.Placeholder = "Block password (optional)"
 	passwordInput.EchoMode = textinput.EchoPassword
 	passwordInput.EchoCharacter = '•'
 	passwordInput.CharLimit = 64
@@ -57,6 +140,9 @@ func NewTextBlock(args BlockArgs) *TextBlock {
 		inputs:      []textinput.Model{titleInput, passwordInput},
 		textarea:    dataBlockInput,
 		saveBlockCb: args.SaveBlockCb,
+		cipher:      args.Cipher,
+		profile:     newProfileSelector(args.Profile),
+		worker:      worker.New(),
 	}
 }
 
@@ -72,8 +158,34 @@ func (r *TextBlock) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+// clear drops entered text when the block is saved or abandoned.
+func (r *TextBlock) clear() {
+	for i := range r.inputs {
+		r.inputs[i].Reset()
+	}
+	r.textarea.Reset()
+}
+
 func (r *TextBlock) UpdateTextBlock(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := r.worker.Update(msg); handled {
+		return r, cmd
+	}
+	if r.profile.Update(msg) {
+		return r, nil
+	}
+
 	switch msg := msg.(type) {
+	case msgBlockSaved:
+		r.worker.Done()
+		if msg.err != nil {
+			r.err = msg.err
+
+			return r, nil
+		}
+		r.clear()
+		r.isSaved = true
+
+		return r, nil
 	// Handle key messages
 	case tea.KeyMsg:
 		if msg.Type == tea.KeyEnter && msg.Alt {
@@ -83,6 +195,8 @@ func (r *TextBlock) UpdateTextBlock(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch msg.String() {
 		case "esc":
+			r.clear()
+
 			return r.PrevModel, nil
 		case "enter":
 			if !r.textarea.Focused() {
@@ -93,33 +207,12 @@ func (r *TextBlock) UpdateTextBlock(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			if r.focused == len(r.inputs) && r.textarea.Focused() == false {
 				title := r.inputs[0].Value()
-				password := r.inputs[1].Value()
+				password := types.TakeSecret(&r.inputs[1])
 				data := r.textarea.Value()
 
-				key, err := utils.ExtractKeyFromPassword(password, utils.ProfileMedium)
-				if err != nil {
-					r.err = err
-
-					return r, nil
-				}
-
-				encrypted, nonce, err := utils.EncryptWithPassword([]byte(data), []byte(data), key)
-				if err != nil {
-					r.err = err
-
-					return r, nil
-				}
-
-				err = r.saveBlockCb(title, r.Type.ID, utils.ProfileMedium, encrypted, key.Salt, nonce)
-				if err != nil {
-					r.err = err
-
-					return r, nil
-				}
-
-				r.isSaved = true
-
-				return r, nil
+				return r, r.worker.Start("Encrypting block...", saveBlock(
+					r.saveBlockCb, r.state, r.Type, title, password, []byte(data), r.profile.Profile(), r.cipher,
+				))
 			}
 		}
 	}
@@ -160,7 +253,7 @@ func (r *TextBlock) TextBlockView() string {
 func (r *TextBlock) View() string {
 	s := fmt.Sprintf("== Creating new block: %s ==\n\n", r.Type.TypeName)
 	if r.err != nil {
-		s += fmt.Sprintf("Error: %s\n\n", r.err.Error())
+		s += fmt.Sprintf("Error: %s\n\n", errfmt.Format(r.err))
 	}
 	if r.isSaved {
 		s += "Block saved successfully! Press ESC to go back.\n"
@@ -171,6 +264,10 @@ func (r *TextBlock) View() string {
 	case string(model.TypeNameText):
 		s += r.TextBlockView()
 	}
+	s += "\n\n" + r.profile.View()
+	if r.worker.Busy() {
+		s += "\n\n" + r.worker.View()
+	}
 
 	s += "\n\nPress ESC to go back."
 
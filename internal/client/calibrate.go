@@ -0,0 +1,83 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+)
+
+// Calibrate benchmarks key derivation profiles on this machine and writes
+// timings and the strongest profile within configured target to w. Once
+// confirmed on in, the profile is written to the YAML config file.
+func Calibrate(conf *config.ClientConf, configFile string, in io.Reader, w io.Writer) error {
+	fmt.Fprintf(w, "Measuring key derivation profiles, target %s...\n\n", conf.KDFTarget)
+
+	best, timings, err := utils.CalibrateProfile(conf.KDFTarget)
+	if err != nil {
+		return fmt.Errorf("failed to calibrate profiles: %w", err)
+	}
+
+	for _, t := range timings {
+		mark := " "
+		if t.Profile == best {
+			mark = "*"
+		}
+		params := utils.ScryptProfiles[t.Profile]
+		fmt.Fprintf(w, "%s %-12s memory %4d MiB, time %d, threads %d: %s\n",
+			mark, configProfile(t.Profile), params.Memory>>10, params.Time, params.Threads, t.Duration.Round(time.Millisecond))
+	}
+	for _, p := range utils.PasswordProfiles[len(timings):] {
+		fmt.Fprintf(w, "  %-12s skipped\n", configProfile(p))
+	}
+
+	profile := configProfile(best)
+	if timings[0].Duration > conf.KDFTarget {
+		fmt.Fprintf(w, "\nNo profile fits the target, the weakest one is proposed.\n")
+	}
+	fmt.Fprintf(w, "\nProposed profile: %s (configured: %s)\n", profile, conf.Profile)
+	if profile == conf.Profile {
+		return nil
+	}
+	if configFile == "" {
+		printProfileHint(w, profile)
+
+		return nil
+	}
+
+	fmt.Fprintf(w, "Write client.profile: %s to %s? [y/N] ", profile, configFile)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		fmt.Fprintf(w, "Config file is left unchanged.\n")
+		printProfileHint(w, profile)
+
+		return nil
+	}
+
+	err = config.SetFileValue(configFile, "client.profile", string(profile))
+	if errors.Is(err, config.ErrNotYAML) {
+		fmt.Fprintf(w, "%s is not a YAML file, it's left unchanged.\n", configFile)
+		printProfileHint(w, profile)
+
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", configFile, err)
+	}
+	fmt.Fprintf(w, "Saved to %s, CLIENT_PROFILE and --profile still take precedence.\n", configFile)
+
+	return nil
+}
+
+func printProfileHint(w io.Writer, profile config.ClientProfile) {
+	fmt.Fprintf(w, "Store it in client config as client.profile: %s, CLIENT_PROFILE=%s or --profile %s.\n",
+		profile, profile, profile)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+)
+
+func TestCalibrateWritesProfile(t *testing.T) {
+	// no profile fits, so the weakest one is proposed after one measurement
+	conf := &config.ClientConf{Profile: config.ClientProfileArgon2idV3, KDFTarget: time.Nanosecond}
+
+	tests := []struct {
+		name   string
+		answer string
+		want   config.ClientProfile
+	}{
+		{"confirmed", "y\n", config.ClientProfileArgon2idV1},
+		{"declined", "n\n", config.ClientProfileArgon2idV3},
+		{"no answer", "", config.ClientProfileArgon2idV3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "client.yaml")
+			err := os.WriteFile(path, []byte("client:\n  profile: argon2id-v3 # comment\n"), 0o600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := Calibrate(conf, path, strings.NewReader(tt.answer), &out); err != nil {
+				t.Fatalf("Calibrate: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "client:\n  profile: " + string(tt.want) + " # comment\n"
+			if string(data) != want {
+				t.Errorf("config file is %q, want %q\noutput:\n%s", data, want, out.String())
+			}
+		})
+	}
+}
@@ -7,7 +7,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
 	grpcclient "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials/insecure"
@@ -24,10 +27,11 @@ type modelView struct {
 	g         *grpc.ClientConn
 }
 
-func New() *modelView {
+func New(conf *config.ClientConf) *modelView {
 	g, err := grpc.NewClient(
-		"127.0.0.1:8080",
+		conf.Address(),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithConnectParams(grpc.ConnectParams{
 			Backoff: backoff.Config{
 				BaseDelay:  time.Second,
@@ -44,7 +48,7 @@ func New() *modelView {
 		g.WaitForStateChange(context.Background(), g.GetState())
 	}()
 
-	state := types.NewState()
+	state := types.NewState(clientCipher(conf.Cipher), clientProfile(conf.Profile), utils.NewKeyCache(conf.KeyCacheTTL))
 
 	// defer g.Close()
 	client := grpcclient.NewGRPCClient(g)
@@ -77,6 +81,38 @@ func New() *modelView {
 	return mainModel
 }
 
+func clientCipher(c config.ClientCipher) utils.Cipher {
+	if c == config.ClientCipherXChaCha20Poly1305 {
+		return utils.CipherXChaCha20Poly1305
+	}
+
+	return utils.CipherAES256GCM
+}
+
+// clientProfile maps configured profile to the utils one.
+func clientProfile(p config.ClientProfile) utils.ScryptProfile {
+	switch p {
+	case config.ClientProfileArgon2idV1:
+		return utils.ProfileArgon2idLow
+	case config.ClientProfileArgon2idV3:
+		return utils.ProfileArgon2idHigh
+	default:
+		return utils.ProfileArgon2idMedium
+	}
+}
+
+// configProfile maps utils profile to the configured one.
+func configProfile(p utils.ScryptProfile) config.ClientProfile {
+	switch p {
+	case utils.ProfileArgon2idLow:
+		return config.ClientProfileArgon2idV1
+	case utils.ProfileArgon2idHigh:
+		return config.ClientProfileArgon2idV3
+	default:
+		return config.ClientProfileArgon2idV2
+	}
+}
+
 func (cv *modelView) Init() tea.Cmd {
 	return nil
 }
@@ -102,6 +138,8 @@ func (cv *modelView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
+			cv.State.DropSecrets()
+
 			return cv, tea.Quit
 		case "up", "k":
 			if cv.cursor > 0 {
@@ -0,0 +1,40 @@
+package errfmt
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// Format renders an error for TUI views. For gRPC status errors
+// the message is extended with ErrorInfo reason, BadRequest field
+// violations and RetryInfo delay sent by the server.
+func Format(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(st.Message())
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			sb.WriteString(fmt.Sprintf(" [%s]", d.GetReason()))
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				sb.WriteString(fmt.Sprintf("\n  - %s: %s", v.GetField(), v.GetDescription()))
+			}
+		case *errdetails.RetryInfo:
+			sb.WriteString(fmt.Sprintf("\n  retry in %s", d.GetRetryDelay().AsDuration()))
+		}
+	}
+
+	return sb.String()
+}
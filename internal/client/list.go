@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
@@ -24,6 +25,7 @@ type blockListView struct {
 	state             *types.State
 	blocks            []*model.Block
 	blocksChan        chan []*model.Block
+	cancelStream      context.CancelFunc
 	err               error
 }
 
@@ -48,7 +50,9 @@ type MsgBlocksReceived []*model.Block
 
 func (sm *blockListView) Init() tea.Cmd {
 	sm.blocks = make([]*model.Block, 0)
-	go sm.startBlockStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	sm.cancelStream = cancel
+	go sm.startBlockStream(ctx)
 
 	return sm.listenForBlocks()
 }
@@ -80,6 +84,11 @@ func (sm *blockListView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			// release server side stream, a new one is opened on next visit
+			if sm.cancelStream != nil {
+				sm.cancelStream()
+			}
+
 			return sm.PrevModel, nil
 		case "down":
 			if sm.cursor < len(sm.blocks)-1 {
@@ -94,10 +103,17 @@ func (sm *blockListView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return sm, nil
 			}
 			block := sm.blocks[sm.cursor]
-			blockModel := NewBlockModel(sm)
-			blockModel.block = *block
+			blockModel := NewBlockModel(sm, sm.state, sm.grpcClient, *block)
 
 			return blockModel, blockModel.Init()
+		case "R":
+			if len(sm.blocks) == 0 {
+				return sm, nil
+			}
+			rotateView := NewRotateView(sm.state, sm.grpcClient, sm.blocks)
+			rotateView.SetPrevModel(sm)
+
+			return rotateView, rotateView.Init()
 		}
 	case MsgBlocksReceived:
 		sm.blocks = msg
@@ -108,7 +124,7 @@ func (sm *blockListView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return sm, nil
 }
 
-func (sm *blockListView) startBlockStream() {
+func (sm *blockListView) startBlockStream(ctx context.Context) {
 	req := storage.ListDataBlocksRequest_builder{
 		ClientId: proto.String(sm.state.ClientID),
 	}
@@ -117,7 +133,7 @@ func (sm *blockListView) startBlockStream() {
 		"authorization": sm.state.Token,
 	})
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 	sub := subscription.SubscribeRequest_builder{
 		ClientId: &sm.state.ClientID,
 	}.Build()
@@ -149,11 +165,15 @@ func (sm *blockListView) startBlockStream() {
 				t := blockResp.GetType()
 				block := &model.Block{
 					ID:      int(blockResp.GetBlockId()),
+					UID:     blockResp.GetBlockUid(),
+					UserID:  sm.state.UserID,
 					Title:   blockResp.GetTitle(),
 					Data:    blockResp.GetChiphertext(),
 					Salt:    blockResp.GetSalt(),
 					Nonce:   blockResp.GetNonce(),
 					Profile: blockResp.GetProfile().String(),
+					Cipher:  blockResp.GetCipher().String(),
+					Format:  int(blockResp.GetFormat()),
 					Type: &model.Type{
 						ID:          int(t.GetId()),
 						TypeName:    t.GetTypeName(),
@@ -173,7 +193,7 @@ func (sm *blockListView) View() string {
 	s := "== Storage ==\n\n"
 	var errText string
 	if sm.err != nil {
-		errText = "Error: " + sm.err.Error() + "\n\n"
+		errText = "Error: " + errfmt.Format(sm.err) + "\n\n"
 	}
 	s += errText
 
@@ -191,7 +211,7 @@ func (sm *blockListView) View() string {
 		}
 	}
 
-	s += "\nPress 'esc' to return to the main menu.\n"
+	s += "\nPress 'R' to re-encrypt blocks, 'esc' to return to the main menu.\n"
 
 	return s
 }
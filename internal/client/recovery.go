@@ -0,0 +1,646 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
+	grpcclient "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	qrcode "github.com/skip2/go-qrcode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRecoveryShares    = 5
+	defaultRecoveryThreshold = 3
+	recoveryQRSize           = 256
+)
+
+type msgSharesSplit struct {
+	texts []string
+	err   error
+}
+
+type msgShareWritten struct {
+	dir   string
+	files []string
+	err   error
+}
+
+type msgVaultRecovered struct {
+	key *secure.Buffer
+	err error
+	// badShares asks to enter shares again
+	badShares bool
+}
+
+// exportSharesView splits the unlocked vault key into recovery shares and
+// hands them out one at a time. Every share is shown with its QR code and
+// is optionally written to a directory chosen for it, a directory gets one
+// share of a split at most, so no single place holds enough of them to
+// recover the vault. Shares never leave the client.
+type exportSharesView struct {
+	PrevModel types.NamedTeaModel
+	state     *types.State
+	inputs    []textinput.Model
+	focused   int
+	threshold int
+	// texts are the shares of the split, nil until the key is split and
+	// after every share is handed out
+	texts   []string
+	current int
+	qr      string
+	dest    textinput.Model
+	// handedOut tells where every handed out share went, dirs maps absolute
+	// directories to the share numbers written there
+	handedOut []string
+	dirs      map[string]int
+	done      bool
+	err       error
+	worker    worker.Worker
+}
+
+func NewExportSharesView(state *types.State) *exportSharesView {
+	shares := textinput.New()
+	shares.Placeholder = "Number of shares"
+	shares.SetValue(strconv.Itoa(defaultRecoveryShares))
+	shares.CharLimit = 3
+	shares.Width = 30
+
+	threshold := textinput.New()
+	threshold.Placeholder = "Shares required to recover"
+	threshold.SetValue(strconv.Itoa(defaultRecoveryThreshold))
+	threshold.CharLimit = 3
+	threshold.Width = 30
+
+	dest := textinput.New()
+	dest.Placeholder = "Directory to write this share to, empty to only show it"
+	dest.CharLimit = 1024
+	dest.Width = 60
+
+	return &exportSharesView{
+		state:  state,
+		inputs: []textinput.Model{shares, threshold},
+		dest:   dest,
+		worker: worker.New(),
+	}
+}
+
+func (ev *exportSharesView) GetTitle() string {
+	return "Recovery Shares"
+}
+
+func (ev *exportSharesView) SetPrevModel(m types.NamedTeaModel) {
+	ev.PrevModel = m
+}
+
+func (ev *exportSharesView) IsAuthorizedModel() bool {
+	return true
+}
+
+func (ev *exportSharesView) Init() tea.Cmd {
+	ev.inputs[0].Focus()
+
+	return textinput.Blink
+}
+
+// handingOut reports whether the shares are split and not all of them are
+// handed out yet.
+func (ev *exportSharesView) handingOut() bool {
+	return ev.texts != nil
+}
+
+func (ev *exportSharesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := ev.worker.Update(msg); handled {
+		return ev, cmd
+	}
+
+	switch msg := msg.(type) {
+	case msgSharesSplit:
+		ev.worker.Done()
+		if msg.err != nil {
+			ev.err = msg.err
+
+			return ev, nil
+		}
+		ev.err = nil
+		ev.texts = msg.texts
+		ev.handedOut = make([]string, len(msg.texts))
+		ev.dirs = make(map[string]int)
+		ev.current = 0
+		ev.inputs[ev.focused].Blur()
+		ev.showShare()
+
+		return ev, textinput.Blink
+	case msgShareWritten:
+		ev.worker.Done()
+		if msg.err != nil {
+			ev.err = msg.err
+
+			return ev, nil
+		}
+		ev.dirs[msg.dir] = ev.current + 1
+		ev.nextShare(strings.Join(msg.files, ", "))
+
+		return ev, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			ev.clear()
+
+			return ev.PrevModel, nil
+		case "enter":
+			if ev.done {
+				return ev.PrevModel, nil
+			}
+			if ev.handingOut() {
+				return ev, ev.handOut()
+			}
+			if ev.focused < len(ev.inputs)-1 {
+				ev.inputs[ev.focused].Blur()
+				ev.focused++
+				ev.inputs[ev.focused].Focus()
+
+				return ev, nil
+			}
+
+			n, errN := strconv.Atoi(ev.inputs[0].Value())
+			k, errK := strconv.Atoi(ev.inputs[1].Value())
+			if errN != nil || errK != nil {
+				ev.err = fmt.Errorf("number of shares and threshold must be numbers")
+
+				return ev, nil
+			}
+			ev.err = nil
+			ev.threshold = k
+
+			return ev, ev.worker.Start("Splitting the vault key...", splitShares(ev.state, n, k))
+		}
+	}
+
+	if ev.done {
+		return ev, nil
+	}
+
+	var cmd tea.Cmd
+	if ev.handingOut() {
+		ev.dest, cmd = ev.dest.Update(msg)
+	} else {
+		ev.inputs[ev.focused], cmd = ev.inputs[ev.focused].Update(msg)
+	}
+
+	return ev, cmd
+}
+
+// splitShares splits the vault key into n shares with threshold k in a
+// worker.
+func splitShares(state *types.State, n, k int) tea.Cmd {
+	vaultKey := state.VaultKey.Clone()
+	userID := state.UserID
+
+	return func() tea.Msg {
+		defer vaultKey.Destroy()
+
+		if vaultKey == nil {
+			return msgSharesSplit{err: fmt.Errorf("vault is locked")}
+		}
+
+		texts, err := utils.SplitVaultKey(vaultKey.Bytes(), userID, n, k)
+
+		return msgSharesSplit{texts: texts, err: err}
+	}
+}
+
+// handOut writes the current share to the entered directory in a worker,
+// with no directory the share is only shown. A directory already holding a
+// share of the split is refused.
+func (ev *exportSharesView) handOut() tea.Cmd {
+	dir := strings.TrimSpace(ev.dest.Value())
+	if dir == "" {
+		ev.err = nil
+		ev.nextShare("shown on screen only")
+
+		return nil
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		ev.err = err
+
+		return nil
+	}
+	if i, ok := ev.dirs[dir]; ok {
+		ev.err = fmt.Errorf("share %d is written to %s already, choose another place", i, dir)
+
+		return nil
+	}
+	ev.err = nil
+
+	text, n := ev.texts[ev.current], len(ev.texts)
+
+	return ev.worker.Start("Writing the recovery share...", func() tea.Msg {
+		files, err := writeShare(text, n, dir)
+
+		return msgShareWritten{dir: dir, files: files, err: err}
+	})
+}
+
+// showShare renders the QR code of the current share.
+func (ev *exportSharesView) showShare() {
+	ev.dest.Reset()
+	ev.dest.Focus()
+	ev.qr = ""
+
+	qr, err := qrcode.New(ev.texts[ev.current], qrcode.Medium)
+	if err != nil {
+		ev.err = err
+
+		return
+	}
+	ev.qr = qr.ToSmallString(false)
+}
+
+// nextShare records where the current share went and shows the next one,
+// shares are dropped once all of them are handed out.
+func (ev *exportSharesView) nextShare(where string) {
+	ev.handedOut[ev.current] = where
+	ev.current++
+	if ev.current < len(ev.texts) {
+		ev.showShare()
+
+		return
+	}
+
+	ev.texts, ev.qr = nil, ""
+	ev.dest.Reset()
+	ev.dest.Blur()
+	ev.done = true
+}
+
+// clear drops shares which aren't handed out yet.
+func (ev *exportSharesView) clear() {
+	ev.texts, ev.qr = nil, ""
+	ev.dest.Reset()
+}
+
+// writeShare writes the share of a split into n shares to dir as a text
+// file with the share and its QR code, along with a PNG of the QR code.
+// Files are readable by the owner only.
+func writeShare(text string, n int, dir string) ([]string, error) {
+	share, err := utils.ParseRecoveryShare(text)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := qrcode.New(text, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	png, err := qr.PNG(recoveryQRSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "GophKeeper vault recovery share %d of %d\n", share.Index, n)
+	fmt.Fprintf(&b, "Any %d shares of set %x recover the vault key of user %d.\n\n", share.Threshold, share.SetID, share.UserID)
+	b.WriteString(text + "\n\n")
+	b.WriteString(qr.ToSmallString(false))
+
+	name := filepath.Join(dir, fmt.Sprintf("gophkeeper-share-%x-%d-of-%d", share.SetID, share.Index, n))
+	if err := os.WriteFile(name+".txt", []byte(b.String()), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(name+".png", png, 0o600); err != nil {
+		return []string{name + ".txt"}, err
+	}
+
+	return []string{name + ".txt", name + ".png"}, nil
+}
+
+func (ev *exportSharesView) View() string {
+	s := "== Recovery Shares ==\n\n"
+	if ev.err != nil {
+		s += "Error: " + errfmt.Format(ev.err) + "\n\n"
+	}
+
+	switch {
+	case ev.done:
+		s += "Recovery shares are handed out:\n\n"
+		for i, where := range ev.handedOut {
+			s += fmt.Sprintf("  share %d: %s\n", i+1, where)
+		}
+		s += fmt.Sprintf("\nAny %d of them recover the vault key without the master password.\n", ev.threshold)
+		s += "Keep them in separate places, delete written files once they're printed or moved.\n"
+		s += "\nPress 'enter' to go back.\n"
+
+		return s
+	case ev.handingOut():
+		s += fmt.Sprintf("Recovery share %d of %d, any %d of them recover the vault key:\n\n", ev.current+1, len(ev.texts), ev.threshold)
+		s += ev.texts[ev.current] + "\n\n" + ev.qr + "\n"
+		s += "Write it down or scan the QR code, or enter a directory to write it to,\n"
+		s += "e.g. on a removable drive. Every share goes to a place of its own.\n\n"
+		s += ev.dest.View() + "\n"
+		if ev.worker.Busy() {
+			s += "\n" + ev.worker.View() + "\n"
+		}
+		s += "\nPress 'enter' for the next share, 'esc' to abandon the rest.\n"
+
+		return s
+	}
+
+	s += "Split the vault key into shares, any of the required number of them\n"
+	s += "recover the vault if the master password is lost. Shares are handed\n"
+	s += "out one by one.\n\n"
+	for _, input := range ev.inputs {
+		s += input.View() + "\n"
+	}
+	if ev.worker.Busy() {
+		s += "\n" + ev.worker.View() + "\n"
+	}
+
+	s += "\nPress 'esc' to go back.\n"
+
+	return s
+}
+
+// recoverVaultView collects recovery shares, reconstructs the vault key
+// from them and re-wraps it with a new master password. Shares are entered
+// as text or as paths of exported share files.
+type recoverVaultView struct {
+	PrevModel types.NamedTeaModel
+	state     *types.State
+	client    *grpcclient.GRPCClient
+	wrapped   []byte
+	share     textinput.Model
+	shares    []*utils.RecoveryShare
+	inputs    []textinput.Model
+	focused   int
+	err       error
+	worker    worker.Worker
+}
+
+// NewRecoverVaultView returns a recovery view of the vault whose current
+// wrapped key is wrapped, it's replaced only if it's unchanged meanwhile.
+func NewRecoverVaultView(state *types.State, client *grpcclient.GRPCClient, wrapped []byte) *recoverVaultView {
+	share := textinput.New()
+	share.Placeholder = "Recovery share or path to a share file"
+	share.CharLimit = 1024
+	share.Width = 60
+	share.Focus()
+
+	return &recoverVaultView{
+		state:   state,
+		client:  client,
+		wrapped: wrapped,
+		share:   share,
+		worker:  worker.New(),
+	}
+}
+
+func (rv *recoverVaultView) GetTitle() string {
+	return "Recover Vault"
+}
+
+func (rv *recoverVaultView) SetPrevModel(m types.NamedTeaModel) {
+	rv.PrevModel = m
+}
+
+func (rv *recoverVaultView) IsAuthorizedModel() bool {
+	return true
+}
+
+func (rv *recoverVaultView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// collecting reports whether shares are still being entered.
+func (rv *recoverVaultView) collecting() bool {
+	return rv.inputs == nil
+}
+
+func (rv *recoverVaultView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := rv.worker.Update(msg); handled {
+		return rv, cmd
+	}
+
+	switch msg := msg.(type) {
+	case msgVaultRecovered:
+		rv.worker.Done()
+		if msg.err != nil {
+			rv.err = msg.err
+			if status.Code(msg.err) == codes.FailedPrecondition {
+				rv.err = fmt.Errorf("vault key has been changed by another client, go back and retry")
+			}
+			if msg.badShares {
+				rv.clear()
+				rv.inputs = nil
+				rv.share.Focus()
+			}
+
+			return rv, nil
+		}
+		rv.clear()
+
+		// the vault view takes the key and returns to its caller
+		return rv.PrevModel, func() tea.Msg { return msgVaultUnlocked{key: msg.key} }
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			rv.clear()
+
+			return rv.PrevModel, nil
+		case "enter":
+			if rv.collecting() {
+				return rv.addShare()
+			}
+			if rv.focused < len(rv.inputs)-1 {
+				rv.inputs[rv.focused].Blur()
+				rv.focused++
+				rv.inputs[rv.focused].Focus()
+
+				return rv, nil
+			}
+
+			rv.err = nil
+
+			return rv, rv.worker.Start("Recovering the vault...", rv.recover())
+		}
+	}
+
+	var cmd tea.Cmd
+	if rv.collecting() {
+		rv.share, cmd = rv.share.Update(msg)
+	} else {
+		rv.inputs[rv.focused], cmd = rv.inputs[rv.focused].Update(msg)
+	}
+
+	return rv, cmd
+}
+
+// addShare parses the entered share and asks for a new master password
+// once the threshold of shares is collected.
+func (rv *recoverVaultView) addShare() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(rv.share.Value())
+	rv.share.Reset()
+	if !strings.HasPrefix(strings.ToUpper(text), utils.RecoverySharePrefix) {
+		data, err := os.ReadFile(text)
+		if err != nil {
+			rv.err = err
+
+			return rv, nil
+		}
+		found, ok := utils.FindRecoveryShare(string(data))
+		if !ok {
+			rv.err = fmt.Errorf("no recovery share in %s", text)
+
+			return rv, nil
+		}
+		text = found
+	}
+
+	share, err := utils.ParseRecoveryShare(text)
+	if err != nil {
+		rv.err = err
+
+		return rv, nil
+	}
+	if share.UserID != rv.state.UserID {
+		rv.err = fmt.Errorf("recovery share belongs to another user")
+
+		return rv, nil
+	}
+	for _, s := range rv.shares {
+		if s.SetID != share.SetID {
+			rv.err = fmt.Errorf("recovery share is of another split, start over to use it")
+
+			return rv, nil
+		}
+		if s.Index == share.Index {
+			rv.err = fmt.Errorf("recovery share %d is already entered", share.Index)
+
+			return rv, nil
+		}
+	}
+	rv.err = nil
+	rv.shares = append(rv.shares, share)
+
+	if len(rv.shares) < share.Threshold {
+		return rv, nil
+	}
+
+	rv.share.Blur()
+	rv.inputs = []textinput.Model{
+		newMasterPasswordInput("New master password"),
+		newMasterPasswordInput("Repeat new master password"),
+	}
+	rv.focused = 0
+	rv.inputs[0].Focus()
+
+	return rv, textinput.Blink
+}
+
+// recover reconstructs the vault key, wraps it with the new master password
+// and replaces the wrapped key on the server in a worker.
+func (rv *recoverVaultView) recover() tea.Cmd {
+	password := types.TakeSecret(&rv.inputs[0])
+	repeated := types.TakeSecret(&rv.inputs[1])
+	rv.focused = 0
+	rv.inputs[1].Blur()
+	rv.inputs[0].Focus()
+
+	client, state, shares, wrapped := rv.client, rv.state, rv.shares, rv.wrapped
+	userID, profile, cipher := state.UserID, state.Profile, state.Cipher
+
+	return func() tea.Msg {
+		defer password.Destroy()
+		defer repeated.Destroy()
+
+		if password.Len() < minMasterPasswordLength {
+			return msgVaultRecovered{err: fmt.Errorf("master password must be at least %d characters", minMasterPasswordLength)}
+		}
+		if !bytes.Equal(password.Bytes(), repeated.Bytes()) {
+			return msgVaultRecovered{err: fmt.Errorf("master passwords do not match")}
+		}
+
+		key, err := utils.RecoverVaultKey(shares, userID)
+		if err != nil {
+			return msgVaultRecovered{err: err, badShares: true}
+		}
+
+		rewrapped, err := utils.WrapVaultKey(key.Bytes(), password.Bytes(), userID, profile, cipher)
+		if err != nil {
+			key.Destroy()
+
+			return msgVaultRecovered{err: err}
+		}
+
+		_, err = client.StorageClient.SetVaultKey(
+			authorizedContext(state),
+			storage.SetVaultKeyRequest_builder{
+				WrappedKey:         rewrapped,
+				ExpectedWrappedKey: wrapped,
+			}.Build(),
+		)
+		if err != nil {
+			key.Destroy()
+
+			return msgVaultRecovered{err: err}
+		}
+
+		return msgVaultRecovered{key: key}
+	}
+}
+
+// clear drops entered shares and passwords.
+func (rv *recoverVaultView) clear() {
+	for _, s := range rv.shares {
+		secure.Wipe(s.Value)
+	}
+	rv.shares = nil
+	rv.share.Reset()
+	for i := range rv.inputs {
+		rv.inputs[i].Reset()
+	}
+}
+
+func (rv *recoverVaultView) View() string {
+	s := "== Recover Vault ==\n\n"
+	if rv.err != nil {
+		s += "Error: " + errfmt.Format(rv.err) + "\n\n"
+	}
+
+	if rv.collecting() {
+		s += "Enter recovery shares one by one to recover the vault key.\n"
+		if len(rv.shares) > 0 {
+			s += fmt.Sprintf("%d of %d shares are entered.\n", len(rv.shares), rv.shares[0].Threshold)
+		}
+		s += "\n" + rv.share.View() + "\n"
+	} else {
+		s += "Shares are collected, set a new master password for the vault.\n\n"
+		for _, input := range rv.inputs {
+			s += input.View() + "\n"
+		}
+	}
+	if rv.worker.Busy() {
+		s += "\n" + rv.worker.View() + "\n"
+	}
+
+	s += "\nPress 'esc' to go back.\n"
+
+	return s
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+)
+
+// TestExportSharesOnePerPlace hands out shares one at a time, a directory
+// must never get more than one share of the split.
+func TestExportSharesOnePerPlace(t *testing.T) {
+	const userID = 1
+	vaultKey := bytes.Repeat([]byte{7}, utils.VaultKeySize)
+	state := &types.State{UserID: userID, VaultKey: secure.FromBytes(bytes.Clone(vaultKey))}
+	ev := NewExportSharesView(state)
+	ev.Init()
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	press := func(dest string) tea.Msg {
+		t.Helper()
+		ev.dest.SetValue(dest)
+		_, cmd := ev.Update(enter)
+
+		return workerResult(cmd)
+	}
+
+	// default 3 of 5
+	ev.Update(enter)
+	_, cmd := ev.Update(enter)
+	ev.Update(workerResult(cmd))
+	if !ev.handingOut() {
+		t.Fatalf("shares aren't handed out: %v", ev.err)
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	ev.Update(press(first))
+	if msg := press(first); msg != nil || ev.err == nil {
+		t.Fatal("second share is written to the directory of the first one")
+	}
+	ev.Update(press(second))
+	for range 3 {
+		if msg := press(""); msg != nil {
+			t.Fatalf("share to show only is written: %v", msg)
+		}
+	}
+	if !ev.done || ev.texts != nil {
+		t.Fatal("shares are kept after all of them are handed out")
+	}
+
+	var shares []*utils.RecoveryShare
+	for _, dir := range []string{first, second} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("%s has %d shares, want 1", dir, len(files))
+		}
+		info, err := os.Stat(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s permissions are %o, want 600", files[0], perm)
+		}
+		data, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, ok := utils.FindRecoveryShare(string(data))
+		if !ok {
+			t.Fatalf("no share in %s", files[0])
+		}
+		share, err := utils.ParseRecoveryShare(text)
+		if err != nil {
+			t.Fatalf("ParseRecoveryShare: %v", err)
+		}
+		shares = append(shares, share)
+	}
+	if shares[0].Index == shares[1].Index {
+		t.Error("both directories got the same share")
+	}
+	if _, err := utils.RecoverVaultKey(shares, userID); err == nil {
+		t.Error("vault key is recovered from fewer shares than the threshold")
+	}
+	if !strings.Contains(ev.View(), "shown on screen only") {
+		t.Error("summary doesn't tell which shares were only shown")
+	}
+}
+
+// workerResult runs cmd started by a worker and returns its result, spinner
+// ticks are skipped.
+func workerResult(cmd tea.Cmd) tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) == 0 {
+		return nil
+	}
+
+	return batch[0]()
+}
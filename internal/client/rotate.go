@@ -0,0 +1,480 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// rotationBatchBlocks matches the server limit of a batch update
+	rotationBatchBlocks = 100
+	// rotationBatchBytes keeps batches under the server message size limit,
+	// a larger block is sent alone
+	rotationBatchBytes = 8 << 20
+)
+
+type rotationStage int
+
+const (
+	rotationSelect rotationStage = iota
+	rotationPasswords
+	rotationRunning
+	rotationFinished
+)
+
+// MsgRotationProgress reports re-encryption of a block or a committed batch.
+type MsgRotationProgress struct {
+	processed int
+	committed int
+	skipped   int
+	failed    []string
+	err       error
+	finished  bool
+}
+
+// rotationJob re-encrypts blocks with new keys and commits them in batches,
+// every batch atomically. Blocks which are already sealed with the new keys
+// and profile are skipped, so an interrupted rotation is resumed by running
+// it again with the same passwords. Blocks not opening with the old keys,
+// e.g. of other block passwords, are reported and left unchanged.
+type rotationJob struct {
+	client  *grpc.GRPCClient
+	state   *types.State
+	blocks  []*model.Block
+	oldKeys utils.EnvelopeKeys
+	newKeys utils.EnvelopeKeys
+	profile utils.ScryptProfile
+}
+
+func (j *rotationJob) run(progress chan<- MsgRotationProgress) {
+	defer close(progress)
+
+	var (
+		p          MsgRotationProgress
+		batch      []*model.Block
+		batchBytes int
+	)
+	commit := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := j.commit(batch); err != nil {
+			return err
+		}
+		p.committed += len(batch)
+		batch, batchBytes = nil, 0
+
+		return nil
+	}
+
+	for _, block := range j.blocks {
+		if j.rotated(block) {
+			p.skipped++
+			p.processed++
+			progress <- p
+
+			continue
+		}
+
+		payload, err := utils.OpenBlock(block, j.oldKeys)
+		if err != nil {
+			p.failed = append(p.failed, block.Title)
+			p.processed++
+			progress <- p
+
+			continue
+		}
+
+		resealed := *block
+		resealed.UserID = j.state.UserID
+		err = utils.SealBlock(&resealed, j.newKeys, payload, j.profile, utils.Cipher(block.Cipher))
+		secure.Wipe(payload)
+		if err != nil {
+			p.err = err
+			break
+		}
+
+		if len(batch) == rotationBatchBlocks || batchBytes+len(resealed.Data) > rotationBatchBytes {
+			if p.err = commit(); p.err != nil {
+				break
+			}
+		}
+		batch = append(batch, &resealed)
+		batchBytes += len(resealed.Data)
+		p.processed++
+		progress <- p
+	}
+
+	if p.err == nil {
+		p.err = commit()
+	}
+	p.finished = true
+	progress <- p
+}
+
+// rotated reports whether the block is already sealed in the current
+// format with the new keys and, for a block password, the new profile.
+func (j *rotationJob) rotated(block *model.Block) bool {
+	if !utils.BlockUsesVaultKey(block) {
+		return false
+	}
+
+	e, err := utils.ParseEnvelope(block.Data)
+	if err != nil || e.NeedsPassword() != (j.newKeys.Password != nil) {
+		return false
+	}
+	if e.NeedsPassword() {
+		params := utils.ScryptProfiles[j.profile]
+		if e.Params.Algo != params.Algo || e.Params.Memory != params.Memory ||
+			e.Params.Time != params.Time || e.Params.Threads != params.Threads {
+			return false
+		}
+	}
+
+	_, err = utils.OpenBlock(block, j.newKeys)
+
+	return err == nil
+}
+
+func (j *rotationJob) commit(batch []*model.Block) error {
+	reqs := make([]*storage.UpdateDataBlockRequest, len(batch))
+	for i, b := range batch {
+		reqs[i] = storage.UpdateDataBlockRequest_builder{
+			BlockId:     proto.Int32(int32(b.ID)),
+			Chiphertext: b.Data,
+			Salt:        b.Salt,
+			Nonce:       b.Nonce,
+			Profile:     utils.ProfileToProto(utils.ScryptProfile(b.Profile)).Enum(),
+			Cipher:      utils.CipherToProto(utils.Cipher(b.Cipher)).Enum(),
+			Format:      proto.Int32(int32(b.Format)),
+		}.Build()
+	}
+
+	_, err := j.client.StorageClient.UpdateDataBlocks(
+		authorizedContext(j.state),
+		storage.UpdateDataBlocksRequest_builder{Blocks: reqs}.Build(),
+	)
+
+	return err
+}
+
+func listenForRotation(progress <-chan MsgRotationProgress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-progress
+		if !ok {
+			return nil
+		}
+
+		return p
+	}
+}
+
+// rotateView selects blocks and re-encrypts them with a new block password
+// and/or a stronger profile. An empty new password leaves blocks sealed with
+// the vault key only.
+type rotateView struct {
+	PrevModel types.NamedTeaModel
+	state     *types.State
+	client    *grpc.GRPCClient
+	blocks    []*model.Block
+	selected  map[int]bool
+	cursor    int
+	stage     rotationStage
+	inputs    []textinput.Model
+	focused   int
+	profile   int
+	progress  MsgRotationProgress
+	updates   chan MsgRotationProgress
+	total     int
+	err       error
+	// secrets of the rotation are kept for a resume until the view is left
+	vaultKey    *secure.Buffer
+	oldPassword *secure.Buffer
+	newPassword *secure.Buffer
+}
+
+func NewRotateView(state *types.State, client *grpc.GRPCClient, blocks []*model.Block) *rotateView {
+	oldPassword := newMasterPasswordInput("Current block password (empty if none)")
+	newPassword := newMasterPasswordInput("New block password (empty for vault key only)")
+	repeatPassword := newMasterPasswordInput("Repeat new block password")
+
+	return &rotateView{
+		state:    state,
+		client:   client,
+		blocks:   slices.Clone(blocks),
+		selected: make(map[int]bool),
+		inputs:   []textinput.Model{oldPassword, newPassword, repeatPassword},
+		profile:  max(slices.Index(utils.PasswordProfiles, state.Profile), 0),
+	}
+}
+
+func (rv *rotateView) GetTitle() string {
+	return "Re-encrypt Blocks"
+}
+
+func (rv *rotateView) SetPrevModel(m types.NamedTeaModel) {
+	rv.PrevModel = m
+}
+
+func (rv *rotateView) IsAuthorizedModel() bool {
+	return true
+}
+
+func (rv *rotateView) Init() tea.Cmd {
+	return nil
+}
+
+func (rv *rotateView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case MsgBlocksReceived:
+		// committed batches are pushed by the server, fresh blocks let a
+		// resumed rotation skip them; the list view keeps listening too
+		rv.blocks = slices.Clone(msg)
+		_, cmd := rv.PrevModel.Update(msg)
+
+		return rv, cmd
+	case MsgRotationProgress:
+		rv.progress = msg
+		if msg.finished {
+			rv.stage = rotationFinished
+
+			return rv, nil
+		}
+
+		return rv, listenForRotation(rv.updates)
+	case tea.KeyMsg:
+		if msg.String() == "esc" && rv.stage != rotationRunning {
+			rv.clear()
+
+			return rv.PrevModel, nil
+		}
+
+		switch rv.stage {
+		case rotationSelect:
+			return rv.updateSelect(msg)
+		case rotationPasswords:
+			return rv.updatePasswords(msg)
+		case rotationFinished:
+			if msg.String() == "enter" && rv.progress.err != nil {
+				// blocks of committed batches are skipped as rotated
+				return rv, rv.start()
+			}
+		}
+	}
+
+	return rv, nil
+}
+
+func (rv *rotateView) updateSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if rv.cursor > 0 {
+			rv.cursor--
+		}
+	case "down":
+		if rv.cursor < len(rv.blocks)-1 {
+			rv.cursor++
+		}
+	case " ":
+		if len(rv.blocks) > 0 {
+			id := rv.blocks[rv.cursor].ID
+			rv.selected[id] = !rv.selected[id]
+		}
+	case "a":
+		all := !rv.allSelected()
+		for _, b := range rv.blocks {
+			rv.selected[b.ID] = all
+		}
+	case "enter":
+		if rv.selectedBlocks() == nil {
+			rv.err = fmt.Errorf("select at least one block")
+
+			return rv, nil
+		}
+		rv.err = nil
+		rv.stage = rotationPasswords
+		rv.focused = 0
+		rv.inputs[0].Focus()
+
+		return rv, textinput.Blink
+	}
+
+	return rv, nil
+}
+
+func (rv *rotateView) updatePasswords(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left":
+		if rv.profile > 0 {
+			rv.profile--
+		}
+
+		return rv, nil
+	case "right":
+		if rv.profile < len(utils.PasswordProfiles)-1 {
+			rv.profile++
+		}
+
+		return rv, nil
+	case "enter":
+		if rv.focused < len(rv.inputs)-1 {
+			rv.inputs[rv.focused].Blur()
+			rv.focused++
+			rv.inputs[rv.focused].Focus()
+
+			return rv, nil
+		}
+		rv.clear()
+		rv.newPassword = types.TakeSecret(&rv.inputs[1])
+		repeated := types.TakeSecret(&rv.inputs[2])
+		defer repeated.Destroy()
+		if !bytes.Equal(rv.newPassword.Bytes(), repeated.Bytes()) {
+			rv.clear()
+			rv.err = fmt.Errorf("new passwords do not match")
+			rv.inputs[rv.focused].Blur()
+			rv.focused = 1
+			rv.inputs[rv.focused].Focus()
+
+			return rv, nil
+		}
+		rv.err = nil
+		rv.vaultKey = rv.state.VaultKey.Clone()
+		rv.oldPassword = types.TakeSecret(&rv.inputs[0])
+
+		return rv, rv.start()
+	}
+
+	var cmd tea.Cmd
+	rv.inputs[rv.focused], cmd = rv.inputs[rv.focused].Update(msg)
+
+	return rv, cmd
+}
+
+// clear destroys secrets of the rotation.
+func (rv *rotateView) clear() {
+	for _, secret := range []*secure.Buffer{rv.vaultKey, rv.oldPassword, rv.newPassword} {
+		secret.Destroy()
+	}
+	rv.vaultKey, rv.oldPassword, rv.newPassword = nil, nil, nil
+}
+
+// start runs the rotation of selected blocks in the background.
+func (rv *rotateView) start() tea.Cmd {
+	oldKeys := utils.EnvelopeKeys{VaultKey: rv.vaultKey.Bytes(), Cache: rv.state.KeyCache}
+	if rv.oldPassword.Len() > 0 {
+		oldKeys.Password = rv.oldPassword.Bytes()
+	}
+	newKeys := utils.EnvelopeKeys{VaultKey: rv.vaultKey.Bytes(), Cache: rv.state.KeyCache}
+	if rv.newPassword.Len() > 0 {
+		newKeys.Password = rv.newPassword.Bytes()
+	}
+
+	job := &rotationJob{
+		client:  rv.client,
+		state:   rv.state,
+		blocks:  rv.selectedBlocks(),
+		oldKeys: oldKeys,
+		newKeys: newKeys,
+		profile: utils.PasswordProfiles[rv.profile],
+	}
+	rv.total = len(job.blocks)
+	rv.progress = MsgRotationProgress{}
+	rv.stage = rotationRunning
+
+	rv.updates = make(chan MsgRotationProgress)
+	go job.run(rv.updates)
+
+	return listenForRotation(rv.updates)
+}
+
+func (rv *rotateView) selectedBlocks() []*model.Block {
+	var blocks []*model.Block
+	for _, b := range rv.blocks {
+		if rv.selected[b.ID] {
+			blocks = append(blocks, b)
+		}
+	}
+
+	return blocks
+}
+
+func (rv *rotateView) allSelected() bool {
+	for _, b := range rv.blocks {
+		if !rv.selected[b.ID] {
+			return false
+		}
+	}
+
+	return len(rv.blocks) > 0
+}
+
+func (rv *rotateView) View() string {
+	s := "== Re-encrypt Blocks ==\n\n"
+	if rv.err != nil {
+		s += "Error: " + errfmt.Format(rv.err) + "\n\n"
+	}
+
+	switch rv.stage {
+	case rotationSelect:
+		for i, b := range rv.blocks {
+			cursor := " "
+			if rv.cursor == i {
+				cursor = ">"
+			}
+			mark := "[ ]"
+			if rv.selected[b.ID] {
+				mark = "[x]"
+			}
+			s += fmt.Sprintf("%s %s %-30s %-20s\n", cursor, mark, b.Title, b.Type.TypeName)
+		}
+		s += "\nPress space to select, 'a' to select all, enter to continue.\n"
+	case rotationPasswords:
+		s += fmt.Sprintf("%d blocks selected\n\n", len(rv.selectedBlocks()))
+		for _, input := range rv.inputs {
+			s += input.View() + "\n"
+		}
+		s += fmt.Sprintf("\nProfile of the new password: < %s >\n", utils.PasswordProfiles[rv.profile])
+		s += "\nPress left/right to change the profile, enter to continue.\n"
+	case rotationRunning, rotationFinished:
+		p := rv.progress
+		s += fmt.Sprintf(
+			"%s %d/%d processed, %d committed, %d already done\n",
+			usageBar(int64(p.processed), int64(rv.total)),
+			p.processed,
+			rv.total,
+			p.committed,
+			p.skipped,
+		)
+		if len(p.failed) > 0 {
+			s += "\nNot opened with the current password, left unchanged:\n"
+			for _, title := range p.failed {
+				s += "  - " + title + "\n"
+			}
+		}
+		if p.err != nil {
+			s += "\nInterrupted: " + errfmt.Format(p.err) + "\n"
+			s += "Press enter to resume, committed blocks are skipped.\n"
+		}
+		if rv.stage == rotationFinished && p.err == nil {
+			s += "\nDone.\n"
+		}
+	}
+
+	if rv.stage != rotationRunning {
+		s += "\nPress 'esc' to go back.\n"
+	}
+
+	return s
+}
@@ -5,6 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	grpcclient "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
 )
 
 type storageModel struct {
@@ -14,6 +15,8 @@ type storageModel struct {
 	selected  map[int]struct{}
 	cursor    int
 	title     string
+	client    *grpcclient.GRPCClient
+	usage     MsgUsageReceived
 }
 
 func NewStorageModel(state *types.State) *storageModel {
@@ -21,7 +24,8 @@ func NewStorageModel(state *types.State) *storageModel {
 		title:    "Storage Menu",
 		selected: make(map[int]struct{}),
 		State:    state,
-		choices:  []string{"View Blocks", "Add Block"},
+		choices:  []string{"View Blocks", "Add Block", "Recovery Shares"},
+		client:   grpcclient.NewGRPCClient(),
 	}
 
 	return s
@@ -44,15 +48,21 @@ func (sm *storageModel) IsAuthorizedModel() bool {
 }
 
 func (sm *storageModel) Init() tea.Cmd {
-	return nil
+	sm.usage = MsgUsageReceived{}
+
+	return fetchUsage(sm.client, sm.State)
 }
 
 func (sm *storageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case MsgUsageReceived:
+		sm.usage = msg
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
 			return sm.PrevModel, nil
+		case "r":
+			return sm, sm.Init()
 		case "up":
 			if sm.cursor > 0 {
 				sm.cursor--
@@ -62,6 +72,13 @@ func (sm *storageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				sm.cursor++
 			}
 		case "enter", " ":
+			if sm.State.VaultKey == nil {
+				vaultView := NewVaultView(sm.State)
+				vaultView.SetPrevModel(sm)
+
+				return vaultView, vaultView.Init()
+			}
+
 			switch sm.cursor {
 			case 0:
 				blockListView := NewBlockListView(sm.State)
@@ -73,6 +90,11 @@ func (sm *storageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				addBlockView.SetPrevModel(sm)
 
 				return addBlockView, addBlockView.Init()
+			case 2:
+				exportView := NewExportSharesView(sm.State)
+				exportView.SetPrevModel(sm)
+
+				return exportView, exportView.Init()
 			}
 		}
 	}
@@ -82,6 +104,7 @@ func (sm *storageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (sm *storageModel) View() string {
 	s := "Storage Menu:\n\n"
+	s += renderUsage(sm.usage) + "\n"
 	for i, choice := range sm.choices {
 		cursor := " " // no cursor
 		if sm.cursor == i {
@@ -91,7 +114,7 @@ func (sm *storageModel) View() string {
 		s += fmt.Sprintf("%s %s\n", cursor, choice)
 	}
 
-	s += "\nPress q to quit.\n"
+	s += "\nPress r to refresh usage, q to quit.\n"
 
 	return s
 }
@@ -0,0 +1,15 @@
+package types
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// TakeSecret moves input value into a secure buffer and resets the input,
+// so the view doesn't keep the secret after use.
+func TakeSecret(input *textinput.Model) *secure.Buffer {
+	secret := secure.FromString(input.Value())
+	input.Reset()
+
+	return secret
+}
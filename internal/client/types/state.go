@@ -1,16 +1,39 @@
 package types
 
-import "github.com/google/uuid"
+import (
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"github.com/google/uuid"
+)
 
 type State struct {
 	IsAuthorized bool   `json:"is_authorized"`
 	Token        string `json:"token"`
 	UserID       int    `json:"user_id"`
 	ClientID     string `json:"client_id"`
+	// Cipher seals new blocks, it's set from client config
+	Cipher utils.Cipher `json:"-"`
+	// Profile is preselected for new block passwords, it's set from client config
+	Profile utils.ScryptProfile `json:"-"`
+	// KeyCache keeps password derived keys for the session, nil if disabled
+	KeyCache *utils.KeyCache `json:"-"`
+	// VaultKey is the unwrapped vault data key, nil until the vault is unlocked
+	VaultKey *secure.Buffer `json:"-"`
 }
 
-func NewState() *State {
+func NewState(cipher utils.Cipher, profile utils.ScryptProfile, keyCache *utils.KeyCache) *State {
 	return &State{
 		ClientID: uuid.NewString(),
+		Cipher:   cipher,
+		Profile:  profile,
+		KeyCache: keyCache,
 	}
 }
+
+// DropSecrets destroys the vault key and cached keys, the vault has to be
+// unlocked again.
+func (s *State) DropSecrets() {
+	s.VaultKey.Destroy()
+	s.VaultKey = nil
+	s.KeyCache.Purge()
+}
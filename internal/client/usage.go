@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	grpcclient "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"google.golang.org/grpc/metadata"
+)
+
+const usageBarWidth = 30
+
+type MsgUsageReceived struct {
+	usage *storage.GetUsageResponse
+	err   error
+}
+
+// fetchUsage requests storage usage of the authorized user.
+func fetchUsage(client *grpcclient.GRPCClient, state *types.State) tea.Cmd {
+	return func() tea.Msg {
+		md := metadata.Pairs("authorization", state.Token)
+		ctx := metadata.NewOutgoingContext(context.Background(), md)
+		resp, err := client.StorageClient.GetUsage(ctx, storage.GetUsageRequest_builder{}.Build())
+
+		return MsgUsageReceived{usage: resp, err: err}
+	}
+}
+
+// renderUsage renders storage size usage bar and block count.
+func renderUsage(msg MsgUsageReceived) string {
+	if msg.err != nil {
+		return "Usage: " + errfmt.Format(msg.err) + "\n"
+	}
+	if msg.usage == nil {
+		return "Usage: loading...\n"
+	}
+
+	u := msg.usage
+	s := "Storage: "
+	if u.GetMaxBytes() > 0 {
+		s += fmt.Sprintf(
+			"%s %3.0f%% %s of %s\n",
+			usageBar(u.GetUsedBytes(), u.GetMaxBytes()),
+			100*float64(u.GetUsedBytes())/float64(u.GetMaxBytes()),
+			formatBytes(u.GetUsedBytes()),
+			formatBytes(u.GetMaxBytes()),
+		)
+	} else {
+		s += formatBytes(u.GetUsedBytes()) + " (unlimited)\n"
+	}
+
+	s += "Blocks:  "
+	if u.GetMaxBlocks() > 0 {
+		s += fmt.Sprintf("%d of %d\n", u.GetBlockCount(), u.GetMaxBlocks())
+	} else {
+		s += fmt.Sprintf("%d (unlimited)\n", u.GetBlockCount())
+	}
+
+	return s
+}
+
+func usageBar(used, limit int64) string {
+	filled := int(used * usageBarWidth / limit)
+	filled = min(max(filled, 0), usageBarWidth)
+
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", usageBarWidth-filled) + "]"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/errfmt"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/types"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/client/worker"
+	grpcclient "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/grpc"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const minMasterPasswordLength = 8
+
+type MsgVaultKeyReceived struct {
+	wrapped []byte
+	err     error
+}
+
+type msgVaultUnlocked struct {
+	key *secure.Buffer
+	err error
+}
+
+// vaultView unlocks the vault key with the master password, or sets it up
+// if the user has none yet. The unlocked key is kept in the state for the
+// session and opens every block without a block password.
+type vaultView struct {
+	PrevModel types.NamedTeaModel
+	state     *types.State
+	client    *grpcclient.GRPCClient
+	inputs    []textinput.Model
+	focused   int
+	wrapped   []byte
+	setup     bool
+	loaded    bool
+	err       error
+	worker    worker.Worker
+}
+
+func NewVaultView(state *types.State) *vaultView {
+	return &vaultView{
+		state:  state,
+		client: grpcclient.NewGRPCClient(),
+		worker: worker.New(),
+	}
+}
+
+func newMasterPasswordInput(placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.EchoMode = textinput.EchoPassword
+	input.EchoCharacter = '•'
+	input.CharLimit = 64
+	input.Width = 30
+
+	return input
+}
+
+func (vv *vaultView) GetTitle() string {
+	return "Vault"
+}
+
+func (vv *vaultView) SetPrevModel(m types.NamedTeaModel) {
+	vv.PrevModel = m
+}
+
+func (vv *vaultView) IsAuthorizedModel() bool {
+	return true
+}
+
+func (vv *vaultView) Init() tea.Cmd {
+	return fetchVaultKey(vv.client, vv.state)
+}
+
+// fetchVaultKey requests wrapped vault key of the authorized user.
+func fetchVaultKey(client *grpcclient.GRPCClient, state *types.State) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := client.StorageClient.GetVaultKey(
+			authorizedContext(state),
+			storage.GetVaultKeyRequest_builder{}.Build(),
+		)
+
+		return MsgVaultKeyReceived{wrapped: resp.GetWrappedKey(), err: err}
+	}
+}
+
+func authorizedContext(state *types.State) context.Context {
+	md := metadata.Pairs("authorization", state.Token)
+
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func (vv *vaultView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if handled, cmd := vv.worker.Update(msg); handled {
+		return vv, cmd
+	}
+
+	switch msg := msg.(type) {
+	case MsgVaultKeyReceived:
+		vv.loaded = false
+		vv.setup = status.Code(msg.err) == codes.NotFound
+		if msg.err != nil && !vv.setup {
+			vv.err = msg.err
+
+			return vv, nil
+		}
+
+		vv.wrapped = msg.wrapped
+		vv.inputs = []textinput.Model{newMasterPasswordInput("Master password")}
+		if vv.setup {
+			vv.inputs = append(vv.inputs, newMasterPasswordInput("Repeat master password"))
+		}
+		vv.focused = 0
+		vv.inputs[0].Focus()
+		vv.loaded = true
+
+		return vv, textinput.Blink
+	case msgVaultUnlocked:
+		vv.worker.Done()
+		if status.Code(msg.err) == codes.FailedPrecondition {
+			// another client has set up the vault meanwhile
+			vv.err = fmt.Errorf("vault has been set up by another client, unlock it")
+
+			return vv, fetchVaultKey(vv.client, vv.state)
+		}
+		if msg.err != nil {
+			vv.err = msg.err
+			for i := range vv.inputs {
+				vv.inputs[i].Reset()
+			}
+
+			return vv, nil
+		}
+
+		vv.state.VaultKey.Destroy()
+		vv.state.VaultKey = msg.key
+
+		return vv.PrevModel, vv.PrevModel.Init()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return vv.PrevModel, nil
+		case "ctrl+r":
+			if !vv.loaded || vv.setup {
+				return vv, nil
+			}
+			recoverView := NewRecoverVaultView(vv.state, vv.client, vv.wrapped)
+			recoverView.SetPrevModel(vv)
+
+			return recoverView, recoverView.Init()
+		case "enter":
+			if !vv.loaded {
+				return vv, nil
+			}
+			if vv.focused < len(vv.inputs)-1 {
+				vv.inputs[vv.focused].Blur()
+				vv.focused++
+				vv.inputs[vv.focused].Focus()
+
+				return vv, nil
+			}
+
+			vv.err = nil
+			if vv.setup {
+				return vv, vv.worker.Start("Setting up the vault...", vv.setupVault())
+			}
+
+			return vv, vv.worker.Start("Unlocking the vault...", vv.unlockVault())
+		}
+	}
+
+	if !vv.loaded {
+		return vv, nil
+	}
+
+	var cmd tea.Cmd
+	vv.inputs[vv.focused], cmd = vv.inputs[vv.focused].Update(msg)
+
+	return vv, cmd
+}
+
+// setupVault generates a vault key, wraps it with the master password and
+// stores it on the server in a worker. Inputs are cleared.
+func (vv *vaultView) setupVault() tea.Cmd {
+	password := types.TakeSecret(&vv.inputs[0])
+	repeated := types.TakeSecret(&vv.inputs[1])
+	client, state := vv.client, vv.state
+	userID, profile, cipher := state.UserID, state.Profile, state.Cipher
+
+	return func() tea.Msg {
+		defer password.Destroy()
+		defer repeated.Destroy()
+
+		if password.Len() < minMasterPasswordLength {
+			return msgVaultUnlocked{err: fmt.Errorf("master password must be at least %d characters", minMasterPasswordLength)}
+		}
+		if !bytes.Equal(password.Bytes(), repeated.Bytes()) {
+			return msgVaultUnlocked{err: fmt.Errorf("master passwords do not match")}
+		}
+
+		key, err := utils.NewVaultKey()
+		if err != nil {
+			return msgVaultUnlocked{err: err}
+		}
+
+		wrapped, err := utils.WrapVaultKey(key.Bytes(), password.Bytes(), userID, profile, cipher)
+		if err != nil {
+			key.Destroy()
+
+			return msgVaultUnlocked{err: err}
+		}
+
+		_, err = client.StorageClient.SetVaultKey(
+			authorizedContext(state),
+			storage.SetVaultKeyRequest_builder{WrappedKey: wrapped}.Build(),
+		)
+		if err != nil {
+			key.Destroy()
+
+			return msgVaultUnlocked{err: err}
+		}
+
+		return msgVaultUnlocked{key: key}
+	}
+}
+
+// unlockVault unwraps the vault key with the master password in a worker.
+// The input is cleared.
+func (vv *vaultView) unlockVault() tea.Cmd {
+	wrapped, userID := vv.wrapped, vv.state.UserID
+	password := types.TakeSecret(&vv.inputs[0])
+
+	return func() tea.Msg {
+		defer password.Destroy()
+
+		key, err := utils.UnwrapVaultKey(wrapped, password.Bytes(), userID)
+		if err != nil {
+			return msgVaultUnlocked{err: fmt.Errorf("Invalid master password")}
+		}
+
+		return msgVaultUnlocked{key: key}
+	}
+}
+
+func (vv *vaultView) View() string {
+	s := "== Vault ==\n\n"
+	if vv.err != nil {
+		s += "Error: " + errfmt.Format(vv.err) + "\n\n"
+	}
+
+	switch {
+	case !vv.loaded && vv.err == nil:
+		s += "Loading vault key...\n"
+	case !vv.loaded:
+	case vv.setup:
+		s += "Set up a master password, it unlocks every block for the session.\n"
+		s += "It can't be recovered, blocks are lost without it, unless recovery\n"
+		s += "shares of the vault key are exported from the storage menu.\n\n"
+		for _, input := range vv.inputs {
+			s += input.View() + "\n"
+		}
+	default:
+		s += "Enter the master password to unlock the vault.\n\n"
+		s += vv.inputs[0].View() + "\n"
+		s += "\nLost it? Press 'ctrl+r' to recover the vault with recovery shares.\n"
+	}
+	if vv.worker.Busy() {
+		s += "\n" + vv.worker.View() + "\n"
+	}
+
+	s += "\nPress 'esc' to go back.\n"
+
+	return s
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Worker runs slow work (key derivation, decryption, RPCs) of a view in a
+// tea.Cmd and shows a spinner meanwhile, so the UI isn't frozen by it.
+// Keys are ignored while the work is running.
+type Worker struct {
+	spinner spinner.Model
+	label   string
+	busy    bool
+}
+
+func New() Worker {
+	return Worker{spinner: spinner.New(spinner.WithSpinner(spinner.Dot))}
+}
+
+// Start marks the worker busy and returns cmd batched with spinner ticks,
+// the view must call Done when cmd result is received.
+func (w *Worker) Start(label string, cmd tea.Cmd) tea.Cmd {
+	w.busy = true
+	w.label = label
+
+	return tea.Batch(cmd, w.spinner.Tick)
+}
+
+func (w *Worker) Done() {
+	w.busy = false
+}
+
+func (w *Worker) Busy() bool {
+	return w.busy
+}
+
+// Update advances the spinner and swallows keys while busy, it reports
+// whether msg is handled.
+func (w *Worker) Update(msg tea.Msg) (bool, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !w.busy {
+			// drop the tick, so the spinner stops
+			return true, nil
+		}
+
+		var cmd tea.Cmd
+		w.spinner, cmd = w.spinner.Update(msg)
+
+		return true, cmd
+	case tea.KeyMsg:
+		return w.busy, nil
+	}
+
+	return false, nil
+}
+
+func (w *Worker) View() string {
+	if !w.busy {
+		return ""
+	}
+
+	return w.spinner.View() + " " + w.label
+}
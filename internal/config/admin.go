@@ -0,0 +1,6 @@
+package config
+
+type Admin struct {
+	// UserIDs lists users allowed to call AdminService, empty list disables it
+	UserIDs []int `mapstructure:"user_ids"`
+}
@@ -1,6 +1,40 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
+// ClientCipher is the AEAD new blocks are sealed with.
+type ClientCipher string
+
+const (
+	ClientCipherAES256GCM         ClientCipher = "aes-256-gcm"
+	ClientCipherXChaCha20Poly1305 ClientCipher = "xchacha20-poly1305"
+)
+
+// ClientProfile is the key derivation profile of new block passwords.
+type ClientProfile string
+
+const (
+	ClientProfileArgon2idV1 ClientProfile = "argon2id-v1"
+	ClientProfileArgon2idV2 ClientProfile = "argon2id-v2"
+	ClientProfileArgon2idV3 ClientProfile = "argon2id-v3"
+)
+
 type ClientConf struct {
-	Host string
-	Port int
+	Host    string        `mapstructure:"host"`
+	Port    int           `mapstructure:"port"`
+	Cipher  ClientCipher  `mapstructure:"cipher"`
+	Profile ClientProfile `mapstructure:"profile"`
+	// KDFTarget is the key derivation time --calibrate picks a profile for
+	KDFTarget time.Duration `mapstructure:"kdf_target"`
+	// KeyCacheTTL is the idle timeout of derived block keys kept in
+	// memory, 0 disables the cache
+	KeyCacheTTL time.Duration `mapstructure:"key_cache_ttl"`
+	Tracing     Tracing       `mapstructure:"tracing"`
+}
+
+func (c *ClientConf) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
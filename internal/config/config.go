@@ -1,49 +1,301 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"strings"
+	"sync"
 
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// App selects flags and validation rules of an entrypoint.
+type App string
+
+const (
+	AppServer   App = "server"
+	AppClient   App = "client"
+	AppMigrator App = "migrator"
+)
+
+// ErrHelp is returned by Load when help is requested with -h/--help,
+// usage is already printed then.
+var ErrHelp = pflag.ErrHelp
+
+// ConfigFileEnv names environment variable with config file path,
+// --config flag takes precedence over it.
+const ConfigFileEnv = "CONFIG_FILE"
+
 type Config struct {
-	Server   Server   `mapstructure:"server"`
-	Database Database `mapstructure:"database"`
+	Server   Server     `mapstructure:"server"`
+	Database Database   `mapstructure:"database"`
+	Client   ClientConf `mapstructure:"client"`
+	Secrets  Secrets    `mapstructure:"secrets"`
+	// PrintConfig is set by --print-config flag, entrypoints should print
+	// masked effective config and exit
+	PrintConfig bool `mapstructure:"-"`
 }
 
 var confBindings = []string{
 	"server.host",
 	"server.port",
+	"server.log_level",
+	"database.engine",
+	"database.path",
+	"database.migrate",
 	"database.host",
 	"database.port",
 	"database.user",
 	"database.password",
+	"database.password_file",
 	"database.dbname",
 	"database.timeout",
+	"database.query_timeout",
+	"database.min_conns",
+	"database.max_conns",
+	"database.statement_cache_capacity",
 	"server.jwt.secret",
+	"server.jwt.secret_file",
+	"server.jwt.previous_secret_ttl",
+	"server.limits.max_recv_msg_size",
+	"server.limits.method_max_recv_msg_size",
+	"server.limits.max_streams_per_user",
+	"server.limits.max_list_streams_per_client",
+	"server.quotas.max_bytes",
+	"server.quotas.max_blocks",
+	"server.quotas.max_block_size",
+	"server.quotas.type_max_block_size",
+	"server.streams.list_timeout",
+	"server.admin.user_ids",
+	"server.metrics.host",
+	"server.metrics.port",
+	"server.tracing.exporter",
+	"server.tracing.file",
+	"server.tracing.endpoint",
+	"server.tracing.ratio",
+	"client.host",
+	"client.port",
+	"client.cipher",
+	"client.profile",
+	"client.kdf_target",
+	"client.key_cache_ttl",
+	"client.tracing.exporter",
+	"client.tracing.file",
+	"client.tracing.endpoint",
+	"client.tracing.ratio",
+	"secrets.provider",
+	"secrets.keyring_path",
+}
+
+// Loader reads configuration from (in increasing precedence) defaults,
+// YAML/TOML config file, environment variables and command line flags.
+type Loader struct {
+	app         App
+	v           *viper.Viper
+	flags       *pflag.FlagSet
+	configFile  string
+	printConfig bool
+	// mu serializes reloads triggered by signals and file watcher
+	mu sync.Mutex
+}
+
+func NewLoader(app App) *Loader {
+	l := &Loader{
+		app:   app,
+		v:     viper.New(),
+		flags: pflag.NewFlagSet(string(app), pflag.ContinueOnError),
+	}
+
+	l.flags.StringVar(&l.configFile, "config", "", "path to YAML or TOML config file (env "+ConfigFileEnv+")")
+	l.flags.BoolVar(&l.printConfig, "print-config", false, "print effective config with masked secrets and exit")
+	for _, f := range appFlags[app] {
+		l.flags.String(f.name, "", f.usage+" ("+envName(f.key)+")")
+	}
+
+	return l
+}
+
+// Flags returns flag set, entrypoints may register their own flags
+// before calling Load.
+func (l *Loader) Flags() *pflag.FlagSet {
+	return l.flags
 }
 
-func NewConfig() (*Config, error) {
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	if err := bindEnvs(); err != nil {
+// ConfigFile returns path of the config file in use, empty if there's none.
+// It's known once Load is called.
+func (l *Loader) ConfigFile() string {
+	return l.v.ConfigFileUsed()
+}
+
+// NewConfig loads and validates configuration of the app using process arguments.
+func NewConfig(app App) (*Config, error) {
+	return NewLoader(app).Load(os.Args[1:])
+}
+
+// Defaults returns config holding default values only, it isn't validated.
+// It lets embedding code (e.g. test servers) start from defaults without
+// reading environment and files.
+func Defaults() (*Config, error) {
+	l := &Loader{v: viper.New()}
+	setDefaults(l.v)
+
+	return l.decode()
+}
+
+// Load parses args, reads all configuration sources and validates the result.
+func (l *Loader) Load(args []string) (*Config, error) {
+	if err := l.flags.Parse(args); err != nil {
 		return nil, err
 	}
 
-	var conf Config
-	if err := viper.Unmarshal(&conf); err != nil {
+	l.v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	l.v.AutomaticEnv()
+	if err := l.bindEnvs(); err != nil {
+		return nil, err
+	}
+	if err := l.bindFlags(); err != nil {
 		return nil, err
 	}
 
+	setDefaults(l.v)
+
+	configFile := l.configFile
+	if configFile == "" {
+		configFile = os.Getenv(ConfigFileEnv)
+	}
+	if configFile != "" {
+		l.v.SetConfigFile(configFile)
+	}
+
+	return l.load()
+}
+
+// Reload re-reads config file, secret files and secret provider,
+// flags and environment of the process are kept as is.
+func (l *Loader) Reload() (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.load()
+}
+
+func (l *Loader) load() (*Config, error) {
+	if configFile := l.v.ConfigFileUsed(); configFile != "" {
+		if err := l.v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
+		}
+	}
+
+	conf, err := l.decode()
+	if err != nil {
+		return nil, err
+	}
+	if err := l.resolveSecrets(conf); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
+	conf.PrintConfig = l.printConfig
+	if err := conf.Validate(l.app); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+func (l *Loader) decode() (*Config, error) {
+	var conf Config
+	err := l.v.Unmarshal(
+		&conf,
+		viper.DecodeHook(
+			mapstructure.ComposeDecodeHookFunc(
+				stringToMethodLimitsHook(),
+				stringToBlockTypeLimitsHook(),
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+			),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	limits := &conf.Server.Limits
+	limits.MethodMaxRecvMsgSize = limits.MethodMaxRecvMsgSize.lowerKeys()
+
 	return &conf, nil
 }
 
-func bindEnvs() error {
+func (l *Loader) bindEnvs() error {
 	for _, key := range confBindings {
-		if err := viper.BindEnv(key); err != nil {
+		if err := l.v.BindEnv(key); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// bindFlags binds only flags set explicitly, so empty flag defaults
+// don't shadow values from environment and config file.
+func (l *Loader) bindFlags() error {
+	var errs []error
+	for _, f := range appFlags[l.app] {
+		flag := l.flags.Lookup(f.name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+
+		errs = append(errs, l.v.BindPFlag(f.key, flag))
+	}
+
+	return errors.Join(errs...)
+}
+
+func envName(key string) string {
+	return "env " + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.host", "127.0.0.1")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.log_level", "info")
+	v.SetDefault("server.jwt.previous_secret_ttl", "1h")
+	v.SetDefault("server.metrics.host", "127.0.0.1")
+	v.SetDefault("server.metrics.port", 9090)
+	v.SetDefault("server.tracing.exporter", string(TracingExporterNone))
+	v.SetDefault("server.tracing.ratio", 1)
+	v.SetDefault("server.limits.max_recv_msg_size", 4<<20)
+	// file blocks are limited to 10MB of plaintext, batches are split by clients
+	v.SetDefault(
+		"server.limits.method_max_recv_msg_size",
+		"/storage.StorageService/SaveDataBlock=11534336,"+
+			"/storage.StorageService/UpdateDataBlock=11534336,"+
+			"/storage.StorageService/UpdateDataBlocks=11534336",
+	)
+	v.SetDefault("server.limits.max_streams_per_user", 16)
+	v.SetDefault("server.limits.max_list_streams_per_client", 2)
+	v.SetDefault("server.quotas.max_bytes", 100<<20)
+	v.SetDefault("server.quotas.max_blocks", 1000)
+	v.SetDefault("server.quotas.max_block_size", 64<<10)
+	// file blocks are limited by SaveDataBlock message size
+	v.SetDefault("server.quotas.type_max_block_size", "file=11534336")
+	v.SetDefault("server.streams.list_timeout", "10m")
+	v.SetDefault("database.engine", string(DatabaseEnginePostgres))
+	v.SetDefault("database.migrate", string(DatabaseMigrateCheck))
+	v.SetDefault("database.port", 5432)
+	v.SetDefault("database.timeout", 5000)
+	v.SetDefault("database.min_conns", 2)
+	v.SetDefault("database.max_conns", 10)
+	v.SetDefault("database.statement_cache_capacity", 512)
+	v.SetDefault("client.host", "127.0.0.1")
+	v.SetDefault("client.port", 8080)
+	v.SetDefault("client.cipher", string(ClientCipherAES256GCM))
+	v.SetDefault("client.profile", string(ClientProfileArgon2idV2))
+	v.SetDefault("client.kdf_target", "1s")
+	v.SetDefault("client.key_cache_ttl", "5m")
+	v.SetDefault("client.tracing.exporter", string(TracingExporterNone))
+	v.SetDefault("client.tracing.ratio", 1)
+	v.SetDefault("secrets.provider", string(SecretsProviderNone))
+}
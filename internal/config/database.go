@@ -1,18 +1,61 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
+
+type DatabaseEngine string
+
+const (
+	DatabaseEnginePostgres DatabaseEngine = "postgres"
+	DatabaseEngineSQLite   DatabaseEngine = "sqlite"
+	// DatabaseEngineMemory keeps data in process memory until restart,
+	// it's meant for tests and demos
+	DatabaseEngineMemory DatabaseEngine = "memory"
+)
+
+// DatabaseMigrate selects what server does with schema migrations on startup.
+type DatabaseMigrate string
+
+const (
+	DatabaseMigrateOff DatabaseMigrate = "off"
+	// DatabaseMigrateCheck refuses to start unless schema is at the latest version
+	DatabaseMigrateCheck DatabaseMigrate = "check"
+	// DatabaseMigrateUp applies pending migrations, then checks the version
+	DatabaseMigrateUp DatabaseMigrate = "up"
+)
 
 type Database struct {
-	Host        string `mapstructure:"host"`
-	Port        int    `mapstructure:"port"`
-	User        string `mapstructure:"user"`
-	Password    string `mapstructure:"password"`
-	DBName      string `mapstructure:"dbname"`
-	ConnTimeout int    `mapstructure:"timeout"`
+	// Engine is one of postgres, sqlite or memory
+	Engine DatabaseEngine `mapstructure:"engine"`
+	// Path is SQLite database file, used by sqlite engine only
+	Path string `mapstructure:"path"`
+	// Migrate is server schema migration mode on startup: off, check or up
+	Migrate     DatabaseMigrate `mapstructure:"migrate"`
+	Host        string          `mapstructure:"host"`
+	Port        int             `mapstructure:"port"`
+	User        string          `mapstructure:"user"`
+	Password    string          `mapstructure:"password"`
+	DBName      string          `mapstructure:"dbname"`
+	ConnTimeout int             `mapstructure:"timeout"`
+	// QueryTimeout limits a single statement execution in milliseconds,
+	// applied both as a context deadline and as server side statement_timeout.
+	// Zero disables the limit.
+	QueryTimeout int `mapstructure:"query_timeout"`
+	// MinConns and MaxConns bound connection pool size
+	MinConns int `mapstructure:"min_conns"`
+	MaxConns int `mapstructure:"max_conns"`
+	// StatementCacheCapacity is a number of prepared statements cached
+	// per connection, zero disables prepared statements
+	StatementCacheCapacity int `mapstructure:"statement_cache_capacity"`
 }
 
 func (d *Database) GetDSN() string {
 	dsn := "postgres://" + d.User + ":" + d.Password + "@" + d.Host + ":" + fmt.Sprint(d.Port) + "/" + d.DBName + "?sslmode=disable"
-	fmt.Printf("DSN: %s\n", dsn)
+	if d.QueryTimeout > 0 {
+		dsn += "&statement_timeout=" + url.QueryEscape(fmt.Sprint(d.QueryTimeout))
+	}
+
 	return dsn
 }
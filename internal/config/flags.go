@@ -0,0 +1,53 @@
+package config
+
+type flagBinding struct {
+	name  string
+	key   string
+	usage string
+}
+
+var databaseFlags = []flagBinding{
+	{name: "db-engine", key: "database.engine", usage: "database engine (postgres, sqlite, memory)"},
+	{name: "db-path", key: "database.path", usage: "SQLite database file"},
+	{name: "db-host", key: "database.host", usage: "database host"},
+	{name: "db-port", key: "database.port", usage: "database port"},
+	{name: "db-user", key: "database.user", usage: "database user"},
+	{name: "db-name", key: "database.dbname", usage: "database name"},
+	{name: "db-timeout", key: "database.timeout", usage: "database connect timeout, ms"},
+	{name: "db-query-timeout", key: "database.query_timeout", usage: "database statement timeout, ms"},
+	{name: "db-min-conns", key: "database.min_conns", usage: "minimum number of pooled database connections"},
+	{name: "db-max-conns", key: "database.max_conns", usage: "maximum number of pooled database connections"},
+}
+
+var secretsFlags = []flagBinding{
+	{name: "secrets-provider", key: "secrets.provider", usage: "secret provider (none, keyring)"},
+	{name: "secrets-keyring", key: "secrets.keyring_path", usage: "keyring file for keyring secret provider"},
+}
+
+// appFlags lists command line flags of every entrypoint. Secrets have no flags
+// on purpose as process arguments are visible to other users of the host.
+var appFlags = map[App][]flagBinding{
+	AppServer: append([]flagBinding{
+		{name: "host", key: "server.host", usage: "gRPC server host"},
+		{name: "port", key: "server.port", usage: "gRPC server port"},
+		{name: "log-level", key: "server.log_level", usage: "log level (debug, info, warn, error)"},
+		{name: "metrics-host", key: "server.metrics.host", usage: "metrics HTTP server host"},
+		{name: "metrics-port", key: "server.metrics.port", usage: "metrics HTTP server port, 0 disables metrics endpoint"},
+		{name: "tracing-exporter", key: "server.tracing.exporter", usage: "tracing exporter (none, stdout, file, otlp)"},
+		{name: "tracing-file", key: "server.tracing.file", usage: "tracing file for file exporter"},
+		{name: "tracing-endpoint", key: "server.tracing.endpoint", usage: "OTLP collector endpoint"},
+		{name: "db-migrate", key: "database.migrate", usage: "schema migrations on startup (off, check, up)"},
+	}, append(databaseFlags, secretsFlags...)...),
+	AppClient: {
+		{name: "host", key: "client.host", usage: "gophkeeper server host"},
+		{name: "port", key: "client.port", usage: "gophkeeper server port"},
+		{name: "cipher", key: "client.cipher", usage: "cipher of new blocks (aes-256-gcm, xchacha20-poly1305)"},
+		{name: "profile", key: "client.profile", usage: "key derivation profile of new block passwords (argon2id-v1, argon2id-v2, argon2id-v3)"},
+		{name: "kdf-target", key: "client.kdf_target", usage: "key derivation time --calibrate picks a profile for"},
+		{name: "key-cache-ttl", key: "client.key_cache_ttl", usage: "idle timeout of derived keys cached in memory, 0 disables the cache"},
+		{name: "tracing-exporter", key: "client.tracing.exporter", usage: "tracing exporter (none, file, otlp)"},
+		{name: "tracing-file", key: "client.tracing.file", usage: "tracing file for file exporter"},
+		{name: "tracing-endpoint", key: "client.tracing.endpoint", usage: "OTLP collector endpoint"},
+	},
+	AppMigrator: append(append([]flagBinding{}, databaseFlags...), secretsFlags...),
+}
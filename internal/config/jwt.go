@@ -1,5 +1,10 @@
 package config
 
+import "time"
+
 type JWT struct {
 	SecretKey string `mapstructure:"secret"`
+	// PreviousSecretTTL is how long tokens signed with the previous secret
+	// are accepted after rotation, 0 revokes them at once
+	PreviousSecretTTL time.Duration `mapstructure:"previous_secret_ttl"`
 }
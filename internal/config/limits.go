@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// MethodLimits maps full gRPC method name (e.g. /storage.StorageService/SaveDataBlock)
+// to a limit value. From environment it's read as comma separated method=value pairs.
+// Names are kept lower-cased, viper lower-cases map keys of config files.
+type MethodLimits map[string]int
+
+// lowerKeys returns limits with lower-cased method names.
+func (m MethodLimits) lowerKeys() MethodLimits {
+	if m == nil {
+		return nil
+	}
+
+	lower := make(MethodLimits, len(m))
+	for method, limit := range m {
+		lower[strings.ToLower(method)] = limit
+	}
+
+	return lower
+}
+
+type Limits struct {
+	// MaxRecvMsgSize is a default maximum request message size in bytes
+	MaxRecvMsgSize int `mapstructure:"max_recv_msg_size"`
+	// MethodMaxRecvMsgSize overrides MaxRecvMsgSize for particular methods
+	MethodMaxRecvMsgSize MethodLimits `mapstructure:"method_max_recv_msg_size"`
+	// MaxStreamsPerUser limits concurrent server streams opened by a single user, 0 means unlimited
+	MaxStreamsPerUser int `mapstructure:"max_streams_per_user"`
+	// MaxListStreamsPerClient limits concurrent ListDataBlocks streams per client ID, 0 means unlimited
+	MaxListStreamsPerClient int `mapstructure:"max_list_streams_per_client"`
+}
+
+// RecvMsgSize returns receive message size limit for the method, method
+// names are matched case-insensitively.
+func (l *Limits) RecvMsgSize(method string) int {
+	if size, ok := l.MethodMaxRecvMsgSize[strings.ToLower(method)]; ok {
+		return size
+	}
+
+	return l.MaxRecvMsgSize
+}
+
+// ServerMaxRecvMsgSize returns the largest configured limit,
+// it's used as transport level limit of the gRPC server.
+func (l *Limits) ServerMaxRecvMsgSize() int {
+	size := l.MaxRecvMsgSize
+	for _, s := range l.MethodMaxRecvMsgSize {
+		if s > size {
+			size = s
+		}
+	}
+
+	return size
+}
+
+func stringToMethodLimitsHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(MethodLimits{}) {
+			return data, nil
+		}
+
+		limits, err := parseNamedLimits(data.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return MethodLimits(limits), nil
+	}
+}
+
+// parseNamedLimits parses comma separated name=value pairs.
+func parseNamedLimits(raw string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid limit %q, expected name=value", pair)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit %q: %w", pair, err)
+		}
+
+		limits[strings.TrimSpace(name)] = n
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExampleConfigMethodLimits(t *testing.T) {
+	t.Setenv("SERVER_JWT_SECRET", "test-secret")
+
+	conf, err := NewLoader(AppServer).Load([]string{
+		"--config", filepath.Join("..", "..", "config.example.yaml"),
+	})
+	if err != nil {
+		t.Fatalf("load example config: %v", err)
+	}
+
+	limits := conf.Server.Limits
+	tests := []struct {
+		method string
+		want   int
+	}{
+		{"/storage.StorageService/SaveDataBlock", 11534336},
+		{"/storage.StorageService/UpdateDataBlock", 11534336},
+		{"/storage.StorageService/UpdateDataBlocks", 11534336},
+		{"/storage.StorageService/GetUsage", 4194304},
+	}
+	for _, tt := range tests {
+		if got := limits.RecvMsgSize(tt.method); got != tt.want {
+			t.Errorf("RecvMsgSize(%s) = %d, want %d", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestEnvMethodLimits(t *testing.T) {
+	t.Setenv("SERVER_JWT_SECRET", "test-secret")
+	t.Setenv("DATABASE_ENGINE", "memory")
+	t.Setenv("SERVER_LIMITS_METHOD_MAX_RECV_MSG_SIZE", "/storage.StorageService/SaveDataBlock=1024")
+
+	conf, err := NewLoader(AppServer).Load(nil)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	if got := conf.Server.Limits.RecvMsgSize("/storage.StorageService/SaveDataBlock"); got != 1024 {
+		t.Errorf("RecvMsgSize = %d, want 1024", got)
+	}
+}
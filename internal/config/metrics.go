@@ -0,0 +1,6 @@
+package config
+
+type Metrics struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
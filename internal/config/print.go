@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+const secretMask = "******"
+
+// secretKeys lists config keys holding secrets.
+var secretKeys = []string{
+	"server.jwt.secret",
+	"database.password",
+}
+
+var appSections = map[App][]string{
+	AppServer:   {"server", "database", "secrets"},
+	AppClient:   {"client"},
+	AppMigrator: {"database", "secrets"},
+}
+
+// Print renders effective config sections used by the app as YAML
+// with secret values masked.
+func (c *Config) Print(app App) (string, error) {
+	all := map[string]any{}
+	if err := mapstructure.Decode(c, &all); err != nil {
+		return "", err
+	}
+
+	for _, key := range secretKeys {
+		maskKey(all, key)
+	}
+
+	out := map[string]any{}
+	for _, section := range appSections[app] {
+		out[section] = all[section]
+	}
+
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func maskKey(m map[string]any, key string) {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		v, ok := m[part]
+		if !ok {
+			return
+		}
+		if i == len(parts)-1 {
+			if s, ok := v.(string); ok && s != "" {
+				m[part] = secretMask
+			}
+
+			return
+		}
+
+		next, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+
+		m = next
+	}
+}
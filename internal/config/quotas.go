@@ -0,0 +1,47 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// BlockTypeLimits maps block type name (e.g. file) to a limit value. From
+// environment it's read as comma separated type=value pairs.
+type BlockTypeLimits map[string]int
+
+// Quotas limit data stored by every user, sizes are in bytes of encrypted payload.
+type Quotas struct {
+	// MaxBytes limits total payload size of user blocks, 0 means unlimited
+	MaxBytes int `mapstructure:"max_bytes"`
+	// MaxBlocks limits number of user blocks, 0 means unlimited
+	MaxBlocks int `mapstructure:"max_blocks"`
+	// MaxBlockSize limits payload size of a single block, 0 means unlimited
+	MaxBlockSize int `mapstructure:"max_block_size"`
+	// TypeMaxBlockSize overrides MaxBlockSize for particular block types
+	TypeMaxBlockSize BlockTypeLimits `mapstructure:"type_max_block_size"`
+}
+
+// BlockSize returns payload size limit of a block of the type.
+func (q *Quotas) BlockSize(typeName string) int {
+	if size, ok := q.TypeMaxBlockSize[typeName]; ok {
+		return size
+	}
+
+	return q.MaxBlockSize
+}
+
+func stringToBlockTypeLimitsHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(BlockTypeLimits{}) {
+			return data, nil
+		}
+
+		limits, err := parseNamedLimits(data.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return BlockTypeLimits(limits), nil
+	}
+}
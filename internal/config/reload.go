@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// reloadableKeys lists settings (and sections) applied at runtime,
+// any other setting change requires restart.
+var reloadableKeys = []string{
+	"server.log_level",
+	"server.limits",
+	"server.quotas",
+	"server.streams",
+	"server.admin",
+	"server.jwt.secret",
+	"database.password",
+	"secrets",
+}
+
+// IsReloadable reports whether setting under the key may be changed at runtime.
+func IsReloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NonReloadableChanges returns sorted keys of the app settings
+// which differ from prev and can't be applied without restart.
+func (c *Config) NonReloadableChanges(prev *Config, app App) []string {
+	curr, err := flatten(c, app)
+	if err != nil {
+		return nil
+	}
+	old, err := flatten(prev, app)
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for key, value := range curr {
+		if IsReloadable(key) {
+			continue
+		}
+		if !reflect.DeepEqual(value, old[key]) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := curr[key]; !ok && !IsReloadable(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func flatten(c *Config, app App) (map[string]any, error) {
+	all := map[string]any{}
+	if err := mapstructure.Decode(c, &all); err != nil {
+		return nil, err
+	}
+
+	out := map[string]any{}
+	for _, section := range appSections[app] {
+		flattenInto(out, section, all[section])
+	}
+
+	return out, nil
+}
+
+func flattenInto(out map[string]any, prefix string, v any) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		out[prefix] = v
+		return
+	}
+
+	for key, value := range m {
+		flattenInto(out, prefix+"."+key, value)
+	}
+}
+
+// Watch reloads configuration on config file changes and passes the result
+// (or an error) to onChange. It's a no-op without a config file.
+func (l *Loader) Watch(onChange func(*Config, error)) {
+	if l.v.ConfigFileUsed() == "" {
+		return
+	}
+
+	l.v.OnConfigChange(func(fsnotify.Event) {
+		onChange(l.Reload())
+	})
+	l.v.WatchConfig()
+}
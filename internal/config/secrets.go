@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+)
+
+type SecretsProvider string
+
+const (
+	SecretsProviderNone    SecretsProvider = "none"
+	SecretsProviderKeyring SecretsProvider = "keyring"
+)
+
+// fileSuffix marks a key holding path to a file with secret value,
+// e.g. server.jwt.secret_file (env SERVER_JWT_SECRET_FILE).
+const fileSuffix = "_file"
+
+type Secrets struct {
+	// Provider is an external secret source consulted for secrets
+	// not set directly or with *_FILE, one of none or keyring
+	Provider SecretsProvider `mapstructure:"provider"`
+	// KeyringPath is a JSON file used by the keyring provider
+	KeyringPath string `mapstructure:"keyring_path"`
+}
+
+// secretRefs maps secret keys to config fields used by the app.
+func (c *Config) secretRefs(app App) map[string]*string {
+	refs := map[string]*string{}
+	switch app {
+	case AppServer:
+		refs["server.jwt.secret"] = &c.Server.JWT.SecretKey
+		refs["database.password"] = &c.Database.Password
+	case AppMigrator:
+		refs["database.password"] = &c.Database.Password
+	}
+
+	return refs
+}
+
+// resolveSecrets fills secrets missing in config with (in decreasing precedence)
+// contents of *_FILE files and values of the secret provider.
+func (l *Loader) resolveSecrets(conf *Config) error {
+	provider, err := newSecretProvider(conf.Secrets)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for key, ref := range conf.secretRefs(l.app) {
+		fileKey := key + fileSuffix
+		path := l.v.GetString(fileKey)
+		if *ref != "" {
+			if path != "" {
+				errs = append(errs, fmt.Errorf("%s (%s) and %s (%s) are mutually exclusive", key, envName(key), fileKey, envName(fileKey)))
+			}
+
+			continue
+		}
+		if path != "" {
+			value, err := readSecretFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", fileKey, err))
+				continue
+			}
+
+			*ref = value
+			continue
+		}
+		if provider == nil {
+			continue
+		}
+
+		value, err := provider.Secret(context.Background(), key)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		*ref = value
+	}
+
+	return errors.Join(errs...)
+}
+
+func newSecretProvider(conf Secrets) (secrets.Provider, error) {
+	switch conf.Provider {
+	case "", SecretsProviderNone:
+		return nil, nil
+	case SecretsProviderKeyring:
+		if conf.KeyringPath == "" {
+			return nil, fmt.Errorf("secrets.keyring_path (%s) is required for keyring provider", envName("secrets.keyring_path"))
+		}
+
+		return secrets.NewFileKeyring(conf.KeyringPath), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q, expected one of none, keyring", conf.Provider)
+	}
+}
+
+// readSecretFile reads secret value trimming trailing newline
+// usually added by editors and `echo`.
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -1,7 +1,14 @@
 package config
 
 type Server struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
-	JWT  JWT    `mapstructure:"jwt"`
+	Host     string  `mapstructure:"host"`
+	Port     int     `mapstructure:"port"`
+	LogLevel string  `mapstructure:"log_level"`
+	JWT      JWT     `mapstructure:"jwt"`
+	Limits   Limits  `mapstructure:"limits"`
+	Quotas   Quotas  `mapstructure:"quotas"`
+	Streams  Streams `mapstructure:"streams"`
+	Admin    Admin   `mapstructure:"admin"`
+	Metrics  Metrics `mapstructure:"metrics"`
+	Tracing  Tracing `mapstructure:"tracing"`
 }
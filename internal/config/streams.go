@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type Streams struct {
+	// ListTimeout limits ListDataBlocks stream lifetime, clients resubscribe
+	// after it to drop stale connections
+	ListTimeout time.Duration `mapstructure:"list_timeout"`
+}
@@ -0,0 +1,22 @@
+package config
+
+type TracingExporter string
+
+const (
+	TracingExporterNone   TracingExporter = "none"
+	TracingExporterStdout TracingExporter = "stdout"
+	TracingExporterFile   TracingExporter = "file"
+	TracingExporterOTLP   TracingExporter = "otlp"
+)
+
+type Tracing struct {
+	// Exporter is one of none, stdout, file or otlp, empty value disables tracing
+	Exporter TracingExporter `mapstructure:"exporter"`
+	// FilePath is used by the file exporter to write spans as JSON lines
+	FilePath string `mapstructure:"file"`
+	// Endpoint is an OTLP gRPC collector address (host:port)
+	Endpoint string `mapstructure:"endpoint"`
+	// SampleRatio is a fraction of traces to record, 0 records none and 1
+	// or more records all
+	SampleRatio float64 `mapstructure:"ratio"`
+}
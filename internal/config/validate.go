@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ValidationError lists all configuration problems found at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(key string, format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf("%s (%s): %s", key, envName(key), fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(key, "is required")
+	}
+}
+
+func (v *validator) port(key string, value int, allowZero bool) {
+	if allowZero && value == 0 {
+		return
+	}
+	if value < 1 || value > 65535 {
+		v.addf(key, "must be in range 1..65535, got %d", value)
+	}
+}
+
+func (v *validator) nonNegative(key string, value int) {
+	if value < 0 {
+		v.addf(key, "must not be negative, got %d", value)
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	if value <= 0 {
+		v.addf(key, "must be positive, got %d", value)
+	}
+}
+
+// Validate checks settings used by the app and reports all problems at once.
+func (c *Config) Validate(app App) error {
+	v := &validator{}
+	switch app {
+	case AppServer:
+		c.Server.validate(v)
+		c.Database.validate(v)
+	case AppMigrator:
+		c.Database.validate(v)
+	case AppClient:
+		c.Client.validate(v)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+func (s *Server) validate(v *validator) {
+	v.required("server.host", s.Host)
+	v.port("server.port", s.Port, false)
+	v.required("server.jwt.secret", s.JWT.SecretKey)
+	if s.JWT.PreviousSecretTTL < 0 {
+		v.addf("server.jwt.previous_secret_ttl", "must not be negative, got %s", s.JWT.PreviousSecretTTL)
+	}
+	if s.LogLevel != "" {
+		if _, err := zapcore.ParseLevel(s.LogLevel); err != nil {
+			v.addf("server.log_level", "unknown level %q", s.LogLevel)
+		}
+	}
+
+	v.port("server.metrics.port", s.Metrics.Port, true)
+	s.Limits.validate(v)
+	s.Quotas.validate(v)
+	if s.Streams.ListTimeout <= 0 {
+		v.addf("server.streams.list_timeout", "must be positive, got %s", s.Streams.ListTimeout)
+	}
+	for _, id := range s.Admin.UserIDs {
+		if id <= 0 {
+			v.addf("server.admin.user_ids", "user ID must be positive, got %d", id)
+		}
+	}
+	s.Tracing.validate(v, "server.tracing")
+}
+
+func (l *Limits) validate(v *validator) {
+	v.positive("server.limits.max_recv_msg_size", l.MaxRecvMsgSize)
+	for method, size := range l.MethodMaxRecvMsgSize {
+		if !strings.HasPrefix(method, "/") {
+			v.addf("server.limits.method_max_recv_msg_size", "method %q must be a full method name like /package.Service/Method", method)
+		}
+		if size <= 0 {
+			v.addf("server.limits.method_max_recv_msg_size", "limit of %s must be positive, got %d", method, size)
+		}
+	}
+
+	v.nonNegative("server.limits.max_streams_per_user", l.MaxStreamsPerUser)
+	v.nonNegative("server.limits.max_list_streams_per_client", l.MaxListStreamsPerClient)
+}
+
+func (q *Quotas) validate(v *validator) {
+	v.nonNegative("server.quotas.max_bytes", q.MaxBytes)
+	v.nonNegative("server.quotas.max_blocks", q.MaxBlocks)
+	v.nonNegative("server.quotas.max_block_size", q.MaxBlockSize)
+	for typeName, size := range q.TypeMaxBlockSize {
+		if size < 0 {
+			v.addf("server.quotas.type_max_block_size", "limit of %s must not be negative, got %d", typeName, size)
+		}
+	}
+}
+
+func (d *Database) validate(v *validator) {
+	v.positive("database.timeout", d.ConnTimeout)
+	v.nonNegative("database.query_timeout", d.QueryTimeout)
+	switch d.Migrate {
+	case DatabaseMigrateOff, DatabaseMigrateCheck, DatabaseMigrateUp:
+	default:
+		v.addf("database.migrate", "unknown mode %q, expected one of off, check, up", d.Migrate)
+	}
+	switch d.Engine {
+	case DatabaseEngineMemory:
+	case DatabaseEngineSQLite:
+		v.required("database.path", d.Path)
+	case DatabaseEnginePostgres:
+		v.required("database.host", d.Host)
+		v.port("database.port", d.Port, false)
+		v.required("database.user", d.User)
+		v.required("database.dbname", d.DBName)
+		v.nonNegative("database.min_conns", d.MinConns)
+		v.positive("database.max_conns", d.MaxConns)
+		if d.MinConns > d.MaxConns {
+			v.addf("database.min_conns", "must not exceed database.max_conns (%d), got %d", d.MaxConns, d.MinConns)
+		}
+		v.nonNegative("database.statement_cache_capacity", d.StatementCacheCapacity)
+	default:
+		v.addf("database.engine", "unknown engine %q, expected one of postgres, sqlite, memory", d.Engine)
+	}
+}
+
+func (c *ClientConf) validate(v *validator) {
+	v.required("client.host", c.Host)
+	v.port("client.port", c.Port, false)
+	switch c.Cipher {
+	case ClientCipherAES256GCM, ClientCipherXChaCha20Poly1305:
+	default:
+		v.addf("client.cipher", "unknown cipher %q, expected one of aes-256-gcm, xchacha20-poly1305", c.Cipher)
+	}
+	switch c.Profile {
+	case ClientProfileArgon2idV1, ClientProfileArgon2idV2, ClientProfileArgon2idV3:
+	default:
+		v.addf("client.profile", "unknown profile %q, expected one of argon2id-v1, argon2id-v2, argon2id-v3", c.Profile)
+	}
+	if c.KDFTarget <= 0 {
+		v.addf("client.kdf_target", "must be positive, got %s", c.KDFTarget)
+	}
+	if c.KeyCacheTTL < 0 {
+		v.addf("client.key_cache_ttl", "must not be negative, got %s", c.KeyCacheTTL)
+	}
+	c.Tracing.validate(v, "client.tracing")
+}
+
+func (t *Tracing) validate(v *validator, prefix string) {
+	switch t.Exporter {
+	case "", TracingExporterNone, TracingExporterStdout:
+	case TracingExporterFile:
+		v.required(prefix+".file", t.FilePath)
+	case TracingExporterOTLP:
+		v.required(prefix+".endpoint", t.Endpoint)
+	default:
+		v.addf(prefix+".exporter", "unknown exporter %q, expected one of none, stdout, file, otlp", t.Exporter)
+	}
+	if t.SampleRatio < 0 {
+		v.addf(prefix+".ratio", "must not be negative, got %v", t.SampleRatio)
+	}
+}
+
+// IsValidationError reports whether err is a configuration validation error.
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrNotYAML is returned by SetFileValue for config files of other formats,
+// they can't be rewritten without losing comments.
+var ErrNotYAML = errors.New("only YAML config files can be updated")
+
+// SetFileValue sets dotted key to value in the YAML config file at path,
+// missing sections are added. Comments and other settings are kept, the
+// file is replaced atomically with its permissions preserved.
+func SetFileValue(path, key, value string) error {
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return ErrNotYAML
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping of settings", path)
+	}
+	if err := setNodeValue(doc.Content[0], strings.Split(key, "."), value); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// setNodeValue sets the scalar at path below mapping node, comments of an
+// existing scalar are kept.
+func setNodeValue(node *yaml.Node, path []string, value string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+
+		child := node.Content[i+1]
+		if len(path) > 1 {
+			if child.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s is not a section", path[0])
+			}
+
+			return setNodeValue(child, path[1:], value)
+		}
+		if child.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%s is not a scalar", path[0])
+		}
+		child.Value, child.Tag, child.Style = value, "!!str", 0
+
+		return nil
+	}
+
+	child := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	for i := len(path) - 1; i > 0; i-- {
+		child = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: path[i]}, child},
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}, child)
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	example, err := os.ReadFile(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, example, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetFileValue(path, "client.profile", string(ClientProfileArgon2idV3)); err != nil {
+		t.Fatalf("SetFileValue: %v", err)
+	}
+	if err := SetFileValue(path, "client.extra.key", "value"); err != nil {
+		t.Fatalf("SetFileValue of a new section: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode is %v, want 0600", info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# profile of new block passwords") {
+		t.Error("comment of the updated setting is lost")
+	}
+
+	conf, err := NewLoader(AppClient).Load([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("load updated config: %v", err)
+	}
+	if conf.Client.Profile != ClientProfileArgon2idV3 {
+		t.Errorf("profile is %q, want %q", conf.Client.Profile, ClientProfileArgon2idV3)
+	}
+	if conf.Client.Port != 8080 {
+		t.Errorf("port is %d, other settings must be kept", conf.Client.Port)
+	}
+}
+
+func TestSetFileValueErrors(t *testing.T) {
+	dir := t.TempDir()
+	toml := filepath.Join(dir, "config.toml")
+	scalar := filepath.Join(dir, "scalar.yaml")
+	for path, content := range map[string]string{
+		toml:   "[client]\nprofile = \"argon2id-v1\"\n",
+		scalar: "client: localhost\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := SetFileValue(toml, "client.profile", "argon2id-v3"); !errors.Is(err, ErrNotYAML) {
+		t.Errorf("SetFileValue of TOML: got %v, want %v", err, ErrNotYAML)
+	}
+	if err := SetFileValue(scalar, "client.profile", "argon2id-v3"); err == nil {
+		t.Error("SetFileValue below a scalar succeeded")
+	}
+}
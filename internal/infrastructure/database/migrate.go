@@ -0,0 +1,166 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrSchemaVersion is returned by Migrator.Check when database schema
+// doesn't match embedded migrations.
+var ErrSchemaVersion = errors.New("database schema version mismatch")
+
+// Migrator applies migrations embedded into the binary.
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+	// closeDB is set when the migrator owns db connection
+	closeDB bool
+}
+
+// NewMigrator creates migrator of the engine over db. PostgreSQL migrator
+// holds a dedicated connection, db is closed with the migrator then.
+// SQLite db is left open, so it can be shared with repositories
+// (in-memory SQLite databases live in a single connection).
+func NewMigrator(engine config.DatabaseEngine, db *sql.DB) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, string(engine))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations of %s: %w", engine, err)
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var driver migratedb.Driver
+	closeDB := false
+	switch engine {
+	case config.DatabaseEngineSQLite:
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	case config.DatabaseEnginePostgres:
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+		closeDB = true
+	default:
+		return nil, fmt.Errorf("%s engine has no schema to migrate", engine)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s migration driver: %w", engine, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, string(engine), driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{m: m, latest: latest, closeDB: closeDB}, nil
+}
+
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read migrations: %w", err)
+		}
+
+		version = next
+	}
+}
+
+// Latest returns version of the newest embedded migration.
+func (m *Migrator) Latest() uint {
+	return m.latest
+}
+
+// Version returns current schema version, zero means no migrations applied.
+// Dirty schema has a failed migration and must be fixed and forced.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down rolls back all migrations dropping the whole schema.
+func (m *Migrator) Down() error {
+	return ignoreNoChange(m.m.Down())
+}
+
+// Steps applies n migrations, negative n rolls back -n migrations.
+func (m *Migrator) Steps(n int) error {
+	return ignoreNoChange(m.m.Steps(n))
+}
+
+// Goto migrates up or down to the version, zero rolls back all migrations.
+func (m *Migrator) Goto(version uint) error {
+	if version == 0 {
+		return m.Down()
+	}
+
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force sets schema version and clears dirty state without running
+// migrations, -1 means no version.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Check reports ErrSchemaVersion unless schema is clean and at the latest version.
+func (m *Migrator) Check() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d is dirty, fix it and run migrator force", ErrSchemaVersion, version)
+	}
+	if version != m.latest {
+		return fmt.Errorf("%w: database is at %d, expected %d", ErrSchemaVersion, version, m.latest)
+	}
+
+	return nil
+}
+
+func (m *Migrator) Close() error {
+	if !m.closeDB {
+		return nil
+	}
+
+	srcErr, dbErr := m.m.Close()
+
+	return errors.Join(srcErr, dbErr)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CurrentUserSetting is a transaction-local setting row-level security
+// policies compare user_id columns with.
+const CurrentUserSetting = "app.user_id"
+
+// PgxDriver is a pgx connection pool used by repositories. Statements
+// are prepared once per connection and cached, see
+// database.statement_cache_capacity.
+type PgxDriver struct {
+	Pool         *pgxpool.Pool
+	queryTimeout time.Duration
+}
+
+// NewPgxDriver opens connection pool, password is read on every new
+// connection, so rotated password is used without reopening the pool.
+func NewPgxDriver(conf *config.Database, password *secrets.Secret) (*PgxDriver, error) {
+	poolConf, err := pgxpool.ParseConfig(conf.GetDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	poolConf.MinConns = int32(conf.MinConns)
+	poolConf.MaxConns = int32(conf.MaxConns)
+	poolConf.ConnConfig.StatementCacheCapacity = conf.StatementCacheCapacity
+	if conf.StatementCacheCapacity == 0 {
+		poolConf.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+	poolConf.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+		cc.Password = string(password.Bytes())
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(conf.ConnTimeout)*time.Millisecond,
+	)
+
+	defer cancel()
+	pool, err := pgxpool.NewWithConfig(ctx, poolConf)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return &PgxDriver{
+		Pool:         pool,
+		queryTimeout: time.Duration(conf.QueryTimeout) * time.Millisecond,
+	}, nil
+}
+
+// WithQueryTimeout derives a context limited by configured query timeout.
+// Cancel func must be called once the statement result is consumed.
+func (d *PgxDriver) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withQueryTimeout(ctx, d.queryTimeout)
+}
+
+// WithUser runs fn in a transaction bound to userID, row-level security
+// policies limit its statements to rows of that user. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+func (d *PgxDriver) WithUser(ctx context.Context, userID int, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, d.Pool, func(tx pgx.Tx) error {
+		if err := SetCurrentUser(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// SetCurrentUser binds transaction tx to userID, see CurrentUserSetting.
+func SetCurrentUser(ctx context.Context, tx pgx.Tx, userID int) error {
+	_, err := tx.Exec(
+		ctx,
+		"SELECT set_config($1, $2, true);",
+		CurrentUserSetting,
+		strconv.Itoa(userID),
+	)
+
+	return err
+}
+
+func (d *PgxDriver) Close() {
+	d.Pool.Close()
+}
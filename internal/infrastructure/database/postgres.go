@@ -3,21 +3,23 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"time"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
-	_ "github.com/lib/pq"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+	"github.com/lib/pq"
 )
 
 type SQLDriver struct {
-	Conn *sql.DB
+	Conn         *sql.DB
+	queryTimeout time.Duration
 }
 
-func NewSQLDriver(conf *config.Database) (*SQLDriver, error) {
-	db, err := sql.Open("postgres", conf.GetDSN())
-	if err != nil {
-		return nil, err
-	}
+// NewSQLDriver opens connection pool, password is read on every new
+// connection, so rotated password is used without reopening the pool.
+func NewSQLDriver(conf *config.Database, password *secrets.Secret) (*SQLDriver, error) {
+	db := sql.OpenDB(&connector{conf: *conf, password: password})
 
 	ctx, cancel := context.WithTimeout(
 		context.Background(),
@@ -26,8 +28,48 @@ func NewSQLDriver(conf *config.Database) (*SQLDriver, error) {
 
 	defer cancel()
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLDriver{
+		Conn:         db,
+		queryTimeout: time.Duration(conf.QueryTimeout) * time.Millisecond,
+	}, nil
+}
+
+// WithQueryTimeout derives a context limited by configured query timeout.
+// Cancel func must be called once the statement result is consumed.
+func (d *SQLDriver) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withQueryTimeout(ctx, d.queryTimeout)
+}
+
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+type connector struct {
+	conf     config.Database
+	password *secrets.Secret
+}
+
+var _ driver.Connector = (*connector)(nil)
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conf := c.conf
+	conf.Password = string(c.password.Bytes())
+	pqConnector, err := pq.NewConnector(conf.GetDSN())
+	if err != nil {
 		return nil, err
 	}
 
-	return &SQLDriver{Conn: db}, nil
+	return pqConnector.Connect(ctx)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
 }
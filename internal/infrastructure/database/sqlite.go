@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteMemoryPath = ":memory:"
+
+type SQLiteDriver struct {
+	Conn         *sql.DB
+	queryTimeout time.Duration
+}
+
+// NewSQLiteDriver opens SQLite database file with foreign keys enforced
+// and WAL journal, writers wait for locks up to the connect timeout.
+// Transactions begin IMMEDIATE, taking the write lock up front, so a
+// transaction that reads before writing doesn't act on a stale snapshot.
+func NewSQLiteDriver(conf *config.Database) (*SQLiteDriver, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", conf.ConnTimeout))
+	pragmas.Add("_txlock", "immediate")
+	if conf.Path != sqliteMemoryPath {
+		pragmas.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sql.Open("sqlite", "file:"+conf.Path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if conf.Path == sqliteMemoryPath {
+		// every connection gets its own in-memory database
+		db.SetMaxOpenConns(1)
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(conf.ConnTimeout)*time.Millisecond,
+	)
+
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteDriver{
+		Conn:         db,
+		queryTimeout: time.Duration(conf.QueryTimeout) * time.Millisecond,
+	}, nil
+}
+
+// WithQueryTimeout derives a context limited by configured query timeout.
+// Cancel func must be called once the statement result is consumed.
+func (d *SQLiteDriver) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withQueryTimeout(ctx, d.queryTimeout)
+}
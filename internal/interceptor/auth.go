@@ -2,9 +2,13 @@ package interceptor
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/logger"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -22,7 +26,9 @@ func (w *wrappedServerStream) Context() context.Context {
 	return w.ctx
 }
 
-func validateToken(m metadata.MD, secret []byte) (int, error) {
+// validateToken accepts tokens signed with current or, during its grace
+// period, previous secret, so sessions survive secret rotation.
+func validateToken(m metadata.MD, secret *secrets.Secret) (int, error) {
 	token := m.Get("authorization")
 	var tokenString string
 	if len(token) == 0 {
@@ -31,15 +37,19 @@ func validateToken(m metadata.MD, secret []byte) (int, error) {
 		tokenString = token[0]
 	}
 
-	claims, err := utils.CheckJWTToken(tokenString, secret)
-	if err != nil {
-		return 0, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	var err error
+	for _, key := range secret.Candidates() {
+		var claims *utils.MyClaims
+		claims, err = utils.CheckJWTToken(tokenString, key)
+		if err == nil {
+			return claims.UserID, nil
+		}
 	}
 
-	return claims.UserID, nil
+	return 0, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
 }
 
-func StreamAuthInterceptor(secret []byte) grpc.StreamServerInterceptor {
+func StreamAuthInterceptor(secret *secrets.Secret) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		md, ok := metadata.FromIncomingContext(ss.Context())
 		if !ok {
@@ -51,6 +61,8 @@ func StreamAuthInterceptor(secret []byte) grpc.StreamServerInterceptor {
 			return err
 		}
 
+		trace.SpanFromContext(ss.Context()).SetAttributes(tracing.UserID(userID))
+		logger.AddFields(ss.Context(), zap.Int("user_id", userID))
 		newCtx := context.WithValue(ss.Context(), UserIDKey("userID"), userID)
 		wrappedSrvStreamCtx := &wrappedServerStream{
 			ServerStream: ss,
@@ -61,14 +73,13 @@ func StreamAuthInterceptor(secret []byte) grpc.StreamServerInterceptor {
 	}
 }
 
-func UnaryAuthInterceptor(secret []byte) grpc.UnaryServerInterceptor {
+func UnaryAuthInterceptor(secret *secrets.Secret) grpc.UnaryServerInterceptor {
 	var authEntrypointsToSkip = map[string]struct{}{
 		"/auth.AuthService/Register":     {},
 		"/auth.AuthService/Authenticate": {},
 	}
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		fmt.Printf("intercepting method: %s\n", info.FullMethod)
 		if _, ok := authEntrypointsToSkip[info.FullMethod]; ok {
 			return handler(ctx, req)
 		}
@@ -83,6 +94,8 @@ func UnaryAuthInterceptor(secret []byte) grpc.UnaryServerInterceptor {
 			return nil, err
 		}
 
+		trace.SpanFromContext(ctx).SetAttributes(tracing.UserID(userID))
+		logger.AddFields(ctx, zap.Int("user_id", userID))
 		ctx = context.WithValue(ctx, UserIDKey("userID"), userID)
 
 		return handler(ctx, req)
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const internalErrorMessage = "internal server error"
+
+// toStatusError maps handler error to gRPC status error:
+//   - *apperror.AppError (also wrapped) is converted with rich status details;
+//   - gRPC status errors and context errors are passed as is;
+//   - any other error is logged and reduced to opaque Internal error.
+func toStatusError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		if appErr.GRPCStatus == codes.Internal || appErr.GRPCStatus == codes.Unknown {
+			logger.FromContext(ctx).Error("request failed", zap.Error(err))
+		}
+
+		return appErr.Status().Err()
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	logger.FromContext(ctx).Error("unexpected error", zap.Error(err))
+
+	return status.Error(codes.Internal, internalErrorMessage)
+}
+
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		resp, err = handler(ctx, req)
+		if err != nil {
+			return nil, toStatusError(ctx, err)
+		}
+
+		return resp, nil
+	}
+}
+
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatusError(ss.Context(), handler(srv, ss))
+	}
+}
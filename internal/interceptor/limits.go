@@ -0,0 +1,162 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const listDataBlocksMethod = "/storage.StorageService/ListDataBlocks"
+
+// Limiter enforces per-method request size limits and concurrent
+// stream limits. It must be chained after authentication interceptors
+// as stream limits are counted per user.
+type Limiter struct {
+	mu            sync.Mutex
+	conf          config.Limits
+	userStreams   map[int]int
+	clientStreams map[string]int
+}
+
+func NewLimiter(conf config.Limits) *Limiter {
+	return &Limiter{
+		conf:          conf,
+		userStreams:   make(map[int]int),
+		clientStreams: make(map[string]int),
+	}
+}
+
+// Update replaces limits at runtime, streams over new limits
+// are kept until finished.
+func (l *Limiter) Update(conf config.Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.conf = conf
+}
+
+func (l *Limiter) checkMessageSize(method string, msg any) error {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	l.mu.Lock()
+	limit := l.conf.RecvMsgSize(method)
+	l.mu.Unlock()
+	if limit > 0 && proto.Size(m) > limit {
+		return apperror.LimitMessageSizeError
+	}
+
+	return nil
+}
+
+// acquire increments counter under key if it's below the limit,
+// returned release func must be called once the stream is finished.
+func acquire[K comparable](l *Limiter, counters map[K]int, key K, limit int, limitErr error) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit > 0 && counters[key] >= limit {
+		return nil, limitErr
+	}
+
+	counters[key]++
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		counters[key]--
+		if counters[key] <= 0 {
+			delete(counters, key)
+		}
+	}, nil
+}
+
+func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if err := l.checkMessageSize(info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		userID, ok := ss.Context().Value(UserIDKey("userID")).(int)
+		if ok {
+			l.mu.Lock()
+			limit := l.conf.MaxStreamsPerUser
+			l.mu.Unlock()
+
+			release, err := acquire(l, l.userStreams, userID, limit, apperror.LimitStreamsPerUserError)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+
+		limited := &limitedServerStream{
+			ServerStream: ss,
+			limiter:      l,
+			method:       info.FullMethod,
+			userID:       userID,
+		}
+		defer limited.release()
+
+		return handler(srv, limited)
+	}
+}
+
+// limitedServerStream checks received messages, ListDataBlocks client ID
+// is known only after the request message is received.
+type limitedServerStream struct {
+	grpc.ServerStream
+	limiter  *Limiter
+	method   string
+	userID   int
+	releases []func()
+}
+
+func (s *limitedServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := s.limiter.checkMessageSize(s.method, m); err != nil {
+		return err
+	}
+
+	req, ok := m.(*storage.ListDataBlocksRequest)
+	if !ok || s.method != listDataBlocksMethod {
+		return nil
+	}
+
+	s.limiter.mu.Lock()
+	limit := s.limiter.conf.MaxListStreamsPerClient
+	s.limiter.mu.Unlock()
+
+	key := fmt.Sprintf("%d/%s", s.userID, req.GetClientId())
+	release, err := acquire(s.limiter, s.limiter.clientStreams, key, limit, apperror.LimitStreamsPerClientError)
+	if err != nil {
+		return err
+	}
+
+	s.releases = append(s.releases, release)
+
+	return nil
+}
+
+func (s *limitedServerStream) release() {
+	for _, release := range s.releases {
+		release()
+	}
+}
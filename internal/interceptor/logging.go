@@ -0,0 +1,84 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDHeader    = "x-request-id"
+	maxRequestIDLength = 64
+)
+
+// requestID takes client provided request ID from metadata
+// or generates a new one.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 && ids[0] != "" && len(ids[0]) <= maxRequestIDLength {
+			return ids[0]
+		}
+	}
+
+	return uuid.NewString()
+}
+
+func newRequestContext(ctx context.Context, l *zap.Logger, method string) (context.Context, string) {
+	id := requestID(ctx)
+	fields := []zap.Field{
+		zap.String("request_id", id),
+		zap.String("method", method),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, zap.String("peer", p.Addr.String()))
+	}
+
+	return logger.NewContext(ctx, l.With(fields...)), id
+}
+
+func logRequest(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	l := logger.FromContext(ctx).With(
+		zap.String("code", code.String()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	if err != nil {
+		l.Warn("request failed", zap.String("status", status.Convert(err).Message()))
+		return
+	}
+
+	l.Info("request handled")
+}
+
+func UnaryLoggingInterceptor(l *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := time.Now()
+		ctx, id := newRequestContext(ctx, l, info.FullMethod)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+		resp, err = handler(ctx, req)
+		logRequest(ctx, start, err)
+
+		return resp, err
+	}
+}
+
+func StreamLoggingInterceptor(l *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, id := newRequestContext(ss.Context(), l, info.FullMethod)
+		_ = ss.SetHeader(metadata.Pairs(requestIDHeader, id))
+
+		err := handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+		logRequest(ctx, start, err)
+
+		return err
+	}
+}
@@ -0,0 +1,30 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func UnaryMetricsInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := time.Now()
+		resp, err = handler(ctx, req)
+		m.ObserveRPC(info.FullMethod, metrics.RPCTypeUnary, status.Code(err), time.Since(start))
+
+		return resp, err
+	}
+}
+
+func StreamMetricsInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.ObserveRPC(info.FullMethod, metrics.RPCTypeStream, status.Code(err), time.Since(start))
+
+		return err
+	}
+}
@@ -0,0 +1,48 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func recoverPanic(ctx context.Context, p any) error {
+	logger.FromContext(ctx).Error(
+		"handler panic recovered",
+		zap.Any("panic", p),
+		zap.String("stacktrace", string(debug.Stack())),
+	)
+
+	return status.Error(codes.Internal, internalErrorMessage)
+}
+
+// UnaryRecoveryInterceptor converts handler panics into Internal errors,
+// so a single failing request doesn't crash the whole server.
+func UnaryRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, recoverPanic(ctx, p)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverPanic(ss.Context(), p)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// scope holds request logger, it's shared by all contexts derived
+// from the request context, so fields added by inner interceptors
+// (e.g. user ID after authentication) are visible to outer ones.
+type scope struct {
+	mu     sync.RWMutex
+	logger *zap.Logger
+}
+
+// NewContext returns context carrying request scoped logger.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &scope{logger: l})
+}
+
+// FromContext returns request scoped logger or no-op logger
+// if context has none.
+func FromContext(ctx context.Context) *zap.Logger {
+	s, ok := ctx.Value(ctxKey{}).(*scope)
+	if !ok {
+		return zap.NewNop()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.logger
+}
+
+// AddFields attaches fields to the request scoped logger.
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	s, ok := ctx.Value(ctxKey{}).(*scope)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger = s.logger.With(fields...)
+}
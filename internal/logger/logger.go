@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New builds production JSON logger with given level wrapped into
+// redaction core, so secrets never reach log output.
+// Returned atomic level may be used to change verbosity at runtime.
+func New(level string) (*zap.Logger, zap.AtomicLevel, error) {
+	atomicLevel := zap.NewAtomicLevel()
+	if level != "" {
+		if err := atomicLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, atomicLevel, err
+		}
+	}
+
+	conf := zap.NewProductionConfig()
+	conf.Level = atomicLevel
+	l, err := conf.Build(
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return NewRedactingCore(core)
+		}),
+	)
+	if err != nil {
+		return nil, atomicLevel, err
+	}
+
+	return l, atomicLevel, nil
+}
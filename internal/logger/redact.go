@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as trailing words of field keys, so
+// "db_password" and "secretKey" are redacted while "previous_secret_ttl" or
+// "keys" are not.
+var sensitiveKeys = []string{
+	"password",
+	"password_hash",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"salt",
+	"nonce",
+	"cipher",
+	"ciphertext",
+	"plaintext",
+	"dsn",
+	"key",
+}
+
+type redactingCore struct {
+	zapcore.Core
+}
+
+// NewRedactingCore wraps core replacing values of sensitive fields:
+//   - fields with keys like password, token, salt, nonce, ciphertext;
+//   - any binary fields ([]byte payloads are considered to be encrypted data);
+//   - domain models carrying secrets (blocks and users) logged via zap.Any.
+func NewRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(Redact(fields))}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, Redact(fields))
+}
+
+// Redact returns copy of fields with sensitive values replaced.
+func Redact(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+
+	return out
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	if isSensitiveKey(f.Key) {
+		return zap.String(f.Key, redacted)
+	}
+
+	switch f.Type {
+	case zapcore.BinaryType, zapcore.ByteStringType:
+		return zap.String(f.Key, redacted)
+	case zapcore.ReflectType, zapcore.StringerType:
+		switch v := f.Interface.(type) {
+		case []byte:
+			return zap.String(f.Key, redacted)
+		case model.Block:
+			return zap.Object(f.Key, safeBlock(&v))
+		case *model.Block:
+			return zap.Object(f.Key, safeBlock(v))
+		case []*model.Block:
+			return zap.Int(f.Key+"_count", len(v))
+		case model.User:
+			return zap.Object(f.Key, safeUser(&v))
+		case *model.User:
+			return zap.Object(f.Key, safeUser(v))
+		}
+	}
+
+	return f
+}
+
+func isSensitiveKey(key string) bool {
+	key = snakeCase(key)
+	for _, s := range sensitiveKeys {
+		if key == s || strings.HasSuffix(key, "_"+s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// snakeCase lower-cases key separating its words with underscores, both
+// camelCase (acronyms included) and other separators split words.
+func snakeCase(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// "jwtSecret" and "JWTSecret" start a word at "S"
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// blockMarshaler logs only block identifiers.
+type blockMarshaler struct {
+	block *model.Block
+}
+
+func safeBlock(b *model.Block) zapcore.ObjectMarshaler {
+	return blockMarshaler{block: b}
+}
+
+func (m blockMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if m.block == nil {
+		return nil
+	}
+
+	enc.AddInt("id", m.block.ID)
+	enc.AddInt("user_id", m.block.UserID)
+	enc.AddInt("type_id", m.block.TypeID)
+
+	return nil
+}
+
+// userMarshaler logs user without password hash.
+type userMarshaler struct {
+	user *model.User
+}
+
+func safeUser(u *model.User) zapcore.ObjectMarshaler {
+	return userMarshaler{user: u}
+}
+
+func (m userMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if m.user == nil {
+		return nil
+	}
+
+	enc.AddInt("id", m.user.ID)
+	enc.AddString("username", m.user.Username)
+
+	return nil
+}
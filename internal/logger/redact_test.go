@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedactSensitiveKeys(t *testing.T) {
+	for _, key := range []string{
+		"password",
+		"db_password",
+		"password_hash",
+		"ciphertext",
+		"plaintext",
+		"token",
+		"access-token",
+		"salt",
+		"nonce",
+		"secret",
+		"secret_key",
+		"secretKey",
+		"JWTSecret",
+		"wrapped_key",
+		"database.dsn",
+		"jwtSecret",
+	} {
+		t.Run(key, func(t *testing.T) {
+			got := Redact([]zapcore.Field{zap.String(key, "value")})[0]
+			if got.String != redacted {
+				t.Errorf("%s is logged as %q, want %q", key, got.String, redacted)
+			}
+		})
+	}
+}
+
+// TestRedactKeepsOtherKeys checks that keys merely containing a sensitive
+// word are logged.
+func TestRedactKeepsOtherKeys(t *testing.T) {
+	for _, key := range []string{
+		"keys",
+		"previous_secret_ttl",
+		"token_count",
+		"monkey",
+		"user_id",
+	} {
+		t.Run(key, func(t *testing.T) {
+			got := Redact([]zapcore.Field{zap.String(key, "value")})[0]
+			if got.String != "value" {
+				t.Errorf("%s is logged as %q, want %q", key, got.String, "value")
+			}
+		})
+	}
+}
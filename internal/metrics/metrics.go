@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+)
+
+const namespace = "gophkeeper"
+
+const (
+	RPCTypeUnary  = "unary"
+	RPCTypeStream = "stream"
+)
+
+// SubscriberCounter is implemented by storages keeping track of active
+// subscriptions, e.g. the in-memory subscription repository.
+type SubscriberCounter interface {
+	CountSubscribers() (users int, clients int)
+}
+
+type Metrics struct {
+	registry             *prometheus.Registry
+	rpcRequests          *prometheus.CounterVec
+	rpcDuration          *prometheus.HistogramVec
+	droppedNotifications prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "grpc",
+				Name:      "requests_total",
+				Help:      "Total number of handled RPCs by method, type and status code.",
+			},
+			[]string{"method", "type", "code"},
+		),
+		rpcDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "grpc",
+				Name:      "request_duration_seconds",
+				Help:      "RPC handling latency by method and type.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"method", "type"},
+		),
+		droppedNotifications: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "subscription",
+				Name:      "dropped_notifications_total",
+				Help:      "Notifications skipped because the subscriber channel was full.",
+			},
+		),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcRequests,
+		m.rpcDuration,
+		m.droppedNotifications,
+	)
+
+	return m
+}
+
+// Handler returns HTTP handler exposing collected metrics in Prometheus format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRPC(method, rpcType string, code codes.Code, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.rpcRequests.WithLabelValues(method, rpcType, code.String()).Inc()
+	m.rpcDuration.WithLabelValues(method, rpcType).Observe(duration.Seconds())
+}
+
+func (m *Metrics) NotificationDropped() {
+	if m == nil {
+		return
+	}
+
+	m.droppedNotifications.Inc()
+}
+
+// RegisterSubscribers exposes gauges for active subscriptions,
+// values are read from the counter on every scrape.
+func (m *Metrics) RegisterSubscribers(counter SubscriberCounter) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "subscription",
+				Name:      "active_users",
+				Help:      "Number of users having at least one active subscriber.",
+			},
+			func() float64 {
+				users, _ := counter.CountSubscribers()
+				return float64(users)
+			},
+		),
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "subscription",
+				Name:      "active_clients",
+				Help:      "Number of active subscribed client apps.",
+			},
+			func() float64 {
+				_, clients := counter.CountSubscribers()
+				return float64(clients)
+			},
+		),
+	)
+}
+
+// RegisterDBStats exposes sql.DB pool statistics.
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// RegisterPoolStats exposes pgx connection pool statistics.
+func (m *Metrics) RegisterPoolStats(pool *pgxpool.Pool, dbName string) {
+	m.registry.MustRegister(newPoolCollector(pool, dbName))
+}
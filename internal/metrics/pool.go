@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exposes pgx pool statistics read on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*poolCollector)(nil)
+
+func newPoolCollector(pool *pgxpool.Pool, dbName string) *poolCollector {
+	labels := prometheus.Labels{"db_name": dbName}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, labels)
+	}
+
+	return &poolCollector{
+		pool:            pool,
+		acquiredConns:   desc("acquired_connections", "Number of connections currently in use."),
+		idleConns:       desc("idle_connections", "Number of idle connections."),
+		totalConns:      desc("total_connections", "Total number of open connections."),
+		maxConns:        desc("max_connections", "Maximum size of the pool."),
+		acquireCount:    desc("acquires_total", "Total number of successful connection acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquire:    desc("empty_acquires_total", "Total number of acquires which waited for a connection."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquire
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+}
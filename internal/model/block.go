@@ -2,6 +2,7 @@ package model
 
 type Block struct {
 	ID      int
+	UID     string
 	UserID  int
 	TypeID  int
 	Title   string
@@ -9,5 +10,7 @@ type Block struct {
 	Nonce   []byte
 	Salt    []byte
 	Profile string
+	Cipher  string
+	Format  int
 	Type    *Type
 }
@@ -0,0 +1,24 @@
+package model
+
+// Usage is storage consumption of a user and quotas applied to it,
+// sizes are in bytes of encrypted payload and zero limits mean unlimited.
+type Usage struct {
+	Bytes      int
+	Blocks     int
+	MaxBytes   int
+	MaxBlocks  int
+	TypeQuotas []*TypeQuota
+}
+
+// BlockSize is the stored payload size of a block and its type name.
+type BlockSize struct {
+	ID       int
+	TypeName string
+	Size     int
+}
+
+// TypeQuota limits payload size of a single block of the type.
+type TypeQuota struct {
+	Type         *Type
+	MaxBlockSize int
+}
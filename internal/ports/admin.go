@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+type AdminService interface {
+	// SetSetting overrides runtime setting for ttl (zero means until
+	// the next config reload) and returns its previous value.
+	SetSetting(ctx context.Context, userID int, key, value string, ttl time.Duration) (string, error)
+}
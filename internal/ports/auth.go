@@ -1,6 +1,8 @@
 package ports
 
+import "context"
+
 type AuthService interface {
-	Authenticate(username, password string) (string, error)
-	Register(username, password string) (string, error)
+	Authenticate(ctx context.Context, username, password string) (string, error)
+	Register(ctx context.Context, username, password string) (string, error)
 }
@@ -1,15 +1,46 @@
 package ports
 
-import "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+import (
+	"context"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+)
 
 type StorageService interface {
-	SaveDataBlock(userID int, block *model.Block) (*model.Block, error)
-	ListDataBlocks(userID int) ([]*model.Block, error)
-	GetBlockTypes() ([]*model.Type, error)
+	SaveDataBlock(ctx context.Context, userID int, block *model.Block) (*model.Block, error)
+	UpdateDataBlock(ctx context.Context, userID int, block *model.Block) error
+	UpdateDataBlocks(ctx context.Context, userID int, blocks []*model.Block) error
+	ListDataBlocks(ctx context.Context, userID int) ([]*model.Block, error)
+	GetBlockTypes(ctx context.Context) ([]*model.Type, error)
+	GetUsage(ctx context.Context, userID int) (*model.Usage, error)
+	GetVaultKey(ctx context.Context, userID int) ([]byte, error)
+	SetVaultKey(ctx context.Context, userID int, wrapped, expected []byte) error
 }
 
 type StorageRepository interface {
-	CreateBlock(block *model.Block) (*model.Block, error)
-	ReadUserBlocks(userID int) ([]*model.Block, error)
-	ReadBlockTypes() ([]*model.Type, error)
+	// CreateBlock inserts the block unless the user would have more than
+	// maxBlocks blocks or maxBytes of payload with it, zero limits are
+	// unlimited. Usage is checked in the insert's transaction, inserts of
+	// the same user are serialized. DBErrorQuotaBlocks or DBErrorQuotaBytes
+	// is returned if the block doesn't fit
+	CreateBlock(ctx context.Context, block *model.Block, maxBlocks, maxBytes int) (*model.Block, error)
+	CreateBlocks(ctx context.Context, blocks []*model.Block) (int64, error)
+	// UpdateBlockPayloads replaces encrypted payload, profile, cipher and format
+	// of user blocks at once, DBErrorNoRows is returned and nothing is updated
+	// if the user has no such block
+	UpdateBlockPayloads(ctx context.Context, userID int, blocks []*model.Block) error
+	ReadUserBlocks(ctx context.Context, userID int) ([]*model.Block, error)
+	// ReadBlockSizes returns payload sizes of the given user blocks without
+	// loading payloads, blocks the user has no are left out
+	ReadBlockSizes(ctx context.Context, userID int, ids []int) ([]*model.BlockSize, error)
+	ReadBlockTypes(ctx context.Context) ([]*model.Type, error)
+	// ReadUserUsage returns number and total payload size of user blocks,
+	// quota fields are left zero
+	ReadUserUsage(ctx context.Context, userID int) (*model.Usage, error)
+	// ReadVaultKey returns wrapped vault key of the user, DBErrorNoRows is
+	// returned if there is none
+	ReadVaultKey(ctx context.Context, userID int) ([]byte, error)
+	// WriteVaultKey replaces wrapped vault key if the stored one equals
+	// expected, nil expected creates it. DBErrorNoRows is returned otherwise
+	WriteVaultKey(ctx context.Context, userID int, wrapped, expected []byte) error
 }
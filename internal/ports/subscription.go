@@ -13,4 +13,5 @@ type SubscriptionRepository interface {
 	Subscribe(userID int, clientID string)
 	Unsubscribe(userID int, clientID string)
 	GetUserSubscribers(userID int) map[string]chan []*model.Block
+	CountSubscribers() (users int, clients int)
 }
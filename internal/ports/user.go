@@ -1,12 +1,16 @@
 package ports
 
-import "github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+import (
+	"context"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+)
 
 type UserRepositoryReader interface {
-	ReadUserByID(id int32) (*model.User, error)
-	ReadUserByUsername(username string) (*model.User, error)
+	ReadUserByID(ctx context.Context, id int32) (*model.User, error)
+	ReadUserByUsername(ctx context.Context, username string) (*model.User, error)
 }
 
 type UserRepositoryWriter interface {
-	CreateUser(user *model.User) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
 }
@@ -0,0 +1,332 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.4
+// source: internal/proto/admin/admin.proto
+
+package admin
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SetSettingRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Key         *string                `protobuf:"bytes,1,opt,name=key"`
+	xxx_hidden_Value       *string                `protobuf:"bytes,2,opt,name=value"`
+	xxx_hidden_TtlSeconds  int64                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SetSettingRequest) Reset() {
+	*x = SetSettingRequest{}
+	mi := &file_internal_proto_admin_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSettingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSettingRequest) ProtoMessage() {}
+
+func (x *SetSettingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_admin_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetSettingRequest) GetKey() string {
+	if x != nil {
+		if x.xxx_hidden_Key != nil {
+			return *x.xxx_hidden_Key
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SetSettingRequest) GetValue() string {
+	if x != nil {
+		if x.xxx_hidden_Value != nil {
+			return *x.xxx_hidden_Value
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SetSettingRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.xxx_hidden_TtlSeconds
+	}
+	return 0
+}
+
+func (x *SetSettingRequest) SetKey(v string) {
+	x.xxx_hidden_Key = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *SetSettingRequest) SetValue(v string) {
+	x.xxx_hidden_Value = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *SetSettingRequest) SetTtlSeconds(v int64) {
+	x.xxx_hidden_TtlSeconds = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *SetSettingRequest) HasKey() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetSettingRequest) HasValue() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *SetSettingRequest) HasTtlSeconds() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *SetSettingRequest) ClearKey() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Key = nil
+}
+
+func (x *SetSettingRequest) ClearValue() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Value = nil
+}
+
+func (x *SetSettingRequest) ClearTtlSeconds() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_TtlSeconds = 0
+}
+
+type SetSettingRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// key is a config key, e.g. server.log_level.
+	Key   *string
+	Value *string
+	// ttl_seconds reverts the setting to the configured value after the period,
+	// zero keeps it until the next config reload.
+	TtlSeconds *int64
+}
+
+func (b0 SetSettingRequest_builder) Build() *SetSettingRequest {
+	m0 := &SetSettingRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Key != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Key = b.Key
+	}
+	if b.Value != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Value = b.Value
+	}
+	if b.TtlSeconds != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_TtlSeconds = *b.TtlSeconds
+	}
+	return m0
+}
+
+type SetSettingResponse struct {
+	state                    protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_PreviousValue *string                `protobuf:"bytes,1,opt,name=previous_value,json=previousValue"`
+	xxx_hidden_Value         *string                `protobuf:"bytes,2,opt,name=value"`
+	XXX_raceDetectHookData   protoimpl.RaceDetectHookData
+	XXX_presence             [1]uint32
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *SetSettingResponse) Reset() {
+	*x = SetSettingResponse{}
+	mi := &file_internal_proto_admin_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSettingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSettingResponse) ProtoMessage() {}
+
+func (x *SetSettingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_admin_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetSettingResponse) GetPreviousValue() string {
+	if x != nil {
+		if x.xxx_hidden_PreviousValue != nil {
+			return *x.xxx_hidden_PreviousValue
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SetSettingResponse) GetValue() string {
+	if x != nil {
+		if x.xxx_hidden_Value != nil {
+			return *x.xxx_hidden_Value
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SetSettingResponse) SetPreviousValue(v string) {
+	x.xxx_hidden_PreviousValue = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *SetSettingResponse) SetValue(v string) {
+	x.xxx_hidden_Value = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *SetSettingResponse) HasPreviousValue() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetSettingResponse) HasValue() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *SetSettingResponse) ClearPreviousValue() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_PreviousValue = nil
+}
+
+func (x *SetSettingResponse) ClearValue() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Value = nil
+}
+
+type SetSettingResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	PreviousValue *string
+	Value         *string
+}
+
+func (b0 SetSettingResponse_builder) Build() *SetSettingResponse {
+	m0 := &SetSettingResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.PreviousValue != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_PreviousValue = b.PreviousValue
+	}
+	if b.Value != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Value = b.Value
+	}
+	return m0
+}
+
+var File_internal_proto_admin_admin_proto protoreflect.FileDescriptor
+
+const file_internal_proto_admin_admin_proto_rawDesc = "" +
+	"\n" +
+	" internal/proto/admin/admin.proto\x12\x05admin\"\\\n" +
+	"\x11SetSettingRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\"Q\n" +
+	"\x12SetSettingResponse\x12%\n" +
+	"\x0eprevious_value\x18\x01 \x01(\tR\rpreviousValue\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value2Q\n" +
+	"\fAdminService\x12A\n" +
+	"\n" +
+	"SetSetting\x12\x18.admin.SetSettingRequest\x1a\x19.admin.SetSettingResponseB\x16Z\x14internal/proto/adminb\beditionsp\xe8\a"
+
+var file_internal_proto_admin_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_internal_proto_admin_admin_proto_goTypes = []any{
+	(*SetSettingRequest)(nil),  // 0: admin.SetSettingRequest
+	(*SetSettingResponse)(nil), // 1: admin.SetSettingResponse
+}
+var file_internal_proto_admin_admin_proto_depIdxs = []int32{
+	0, // 0: admin.AdminService.SetSetting:input_type -> admin.SetSettingRequest
+	1, // 1: admin.AdminService.SetSetting:output_type -> admin.SetSettingResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_admin_admin_proto_init() }
+func file_internal_proto_admin_admin_proto_init() {
+	if File_internal_proto_admin_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_admin_admin_proto_rawDesc), len(file_internal_proto_admin_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_proto_admin_admin_proto_goTypes,
+		DependencyIndexes: file_internal_proto_admin_admin_proto_depIdxs,
+		MessageInfos:      file_internal_proto_admin_admin_proto_msgTypes,
+	}.Build()
+	File_internal_proto_admin_admin_proto = out.File
+	file_internal_proto_admin_admin_proto_goTypes = nil
+	file_internal_proto_admin_admin_proto_depIdxs = nil
+}
@@ -20,12 +20,16 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// EncProfile selects key derivation parameters, V1..V3 are scrypt profiles.
 type EncProfile int32
 
 const (
-	EncProfile_PROFILE_V1 EncProfile = 0
-	EncProfile_PROFILE_V2 EncProfile = 1
-	EncProfile_PROFILE_V3 EncProfile = 2
+	EncProfile_PROFILE_V1          EncProfile = 0
+	EncProfile_PROFILE_V2          EncProfile = 1
+	EncProfile_PROFILE_V3          EncProfile = 2
+	EncProfile_PROFILE_ARGON2ID_V1 EncProfile = 3
+	EncProfile_PROFILE_ARGON2ID_V2 EncProfile = 4
+	EncProfile_PROFILE_ARGON2ID_V3 EncProfile = 5
 )
 
 // Enum value maps for EncProfile.
@@ -34,11 +38,17 @@ var (
 		0: "PROFILE_V1",
 		1: "PROFILE_V2",
 		2: "PROFILE_V3",
+		3: "PROFILE_ARGON2ID_V1",
+		4: "PROFILE_ARGON2ID_V2",
+		5: "PROFILE_ARGON2ID_V3",
 	}
 	EncProfile_value = map[string]int32{
-		"PROFILE_V1": 0,
-		"PROFILE_V2": 1,
-		"PROFILE_V3": 2,
+		"PROFILE_V1":          0,
+		"PROFILE_V2":          1,
+		"PROFILE_V3":          2,
+		"PROFILE_ARGON2ID_V1": 3,
+		"PROFILE_ARGON2ID_V2": 4,
+		"PROFILE_ARGON2ID_V3": 5,
 	}
 )
 
@@ -64,6 +74,48 @@ func (x EncProfile) Number() protoreflect.EnumNumber {
 	return protoreflect.EnumNumber(x)
 }
 
+// Cipher is the AEAD a block payload is sealed with.
+type Cipher int32
+
+const (
+	Cipher_CIPHER_AES_256_GCM        Cipher = 0
+	Cipher_CIPHER_XCHACHA20_POLY1305 Cipher = 1
+)
+
+// Enum value maps for Cipher.
+var (
+	Cipher_name = map[int32]string{
+		0: "CIPHER_AES_256_GCM",
+		1: "CIPHER_XCHACHA20_POLY1305",
+	}
+	Cipher_value = map[string]int32{
+		"CIPHER_AES_256_GCM":        0,
+		"CIPHER_XCHACHA20_POLY1305": 1,
+	}
+)
+
+func (x Cipher) Enum() *Cipher {
+	p := new(Cipher)
+	*p = x
+	return p
+}
+
+func (x Cipher) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Cipher) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_storage_storage_proto_enumTypes[1].Descriptor()
+}
+
+func (Cipher) Type() protoreflect.EnumType {
+	return &file_internal_proto_storage_storage_proto_enumTypes[1]
+}
+
+func (x Cipher) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
 type ListDataBlocksRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_ClientId    *string                `protobuf:"bytes,1,opt,name=client_id,json=clientId"`
@@ -151,6 +203,9 @@ type DataBlock struct {
 	xxx_hidden_Nonce       []byte                 `protobuf:"bytes,5,opt,name=nonce"`
 	xxx_hidden_Profile     EncProfile             `protobuf:"varint,6,opt,name=profile,enum=storage.EncProfile"`
 	xxx_hidden_Type        *BlockType             `protobuf:"bytes,7,opt,name=type"`
+	xxx_hidden_Cipher      Cipher                 `protobuf:"varint,8,opt,name=cipher,enum=storage.Cipher"`
+	xxx_hidden_BlockUid    *string                `protobuf:"bytes,9,opt,name=block_uid,json=blockUid"`
+	xxx_hidden_Format      int32                  `protobuf:"varint,10,opt,name=format"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -236,14 +291,40 @@ func (x *DataBlock) GetType() *BlockType {
 	return nil
 }
 
+func (x *DataBlock) GetCipher() Cipher {
+	if x != nil {
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 7) {
+			return x.xxx_hidden_Cipher
+		}
+	}
+	return Cipher_CIPHER_AES_256_GCM
+}
+
+func (x *DataBlock) GetBlockUid() string {
+	if x != nil {
+		if x.xxx_hidden_BlockUid != nil {
+			return *x.xxx_hidden_BlockUid
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *DataBlock) GetFormat() int32 {
+	if x != nil {
+		return x.xxx_hidden_Format
+	}
+	return 0
+}
+
 func (x *DataBlock) SetBlockId(v int32) {
 	x.xxx_hidden_BlockId = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 10)
 }
 
 func (x *DataBlock) SetTitle(v string) {
 	x.xxx_hidden_Title = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 10)
 }
 
 func (x *DataBlock) SetChiphertext(v []byte) {
@@ -251,7 +332,7 @@ func (x *DataBlock) SetChiphertext(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Chiphertext = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 10)
 }
 
 func (x *DataBlock) SetSalt(v []byte) {
@@ -259,7 +340,7 @@ func (x *DataBlock) SetSalt(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Salt = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 10)
 }
 
 func (x *DataBlock) SetNonce(v []byte) {
@@ -267,18 +348,33 @@ func (x *DataBlock) SetNonce(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Nonce = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 10)
 }
 
 func (x *DataBlock) SetProfile(v EncProfile) {
 	x.xxx_hidden_Profile = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 7)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 10)
 }
 
 func (x *DataBlock) SetType(v *BlockType) {
 	x.xxx_hidden_Type = v
 }
 
+func (x *DataBlock) SetCipher(v Cipher) {
+	x.xxx_hidden_Cipher = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 7, 10)
+}
+
+func (x *DataBlock) SetBlockUid(v string) {
+	x.xxx_hidden_BlockUid = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 8, 10)
+}
+
+func (x *DataBlock) SetFormat(v int32) {
+	x.xxx_hidden_Format = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 9, 10)
+}
+
 func (x *DataBlock) HasBlockId() bool {
 	if x == nil {
 		return false
@@ -328,6 +424,27 @@ func (x *DataBlock) HasType() bool {
 	return x.xxx_hidden_Type != nil
 }
 
+func (x *DataBlock) HasCipher() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 7)
+}
+
+func (x *DataBlock) HasBlockUid() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 8)
+}
+
+func (x *DataBlock) HasFormat() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 9)
+}
+
 func (x *DataBlock) ClearBlockId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_BlockId = 0
@@ -362,6 +479,21 @@ func (x *DataBlock) ClearType() {
 	x.xxx_hidden_Type = nil
 }
 
+func (x *DataBlock) ClearCipher() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 7)
+	x.xxx_hidden_Cipher = Cipher_CIPHER_AES_256_GCM
+}
+
+func (x *DataBlock) ClearBlockUid() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 8)
+	x.xxx_hidden_BlockUid = nil
+}
+
+func (x *DataBlock) ClearFormat() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 9)
+	x.xxx_hidden_Format = 0
+}
+
 type DataBlock_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -372,6 +504,12 @@ type DataBlock_builder struct {
 	Nonce       []byte
 	Profile     *EncProfile
 	Type        *BlockType
+	Cipher      *Cipher
+	// block_uid is a client generated block identifier bound to the ciphertext
+	BlockUid *string
+	// format is a version of the payload format, 0 has no associated data,
+	// 2 is a self-describing envelope and salt, nonce, profile and cipher are empty or informational
+	Format *int32
 }
 
 func (b0 DataBlock_builder) Build() *DataBlock {
@@ -379,30 +517,42 @@ func (b0 DataBlock_builder) Build() *DataBlock {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.BlockId != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 10)
 		x.xxx_hidden_BlockId = *b.BlockId
 	}
 	if b.Title != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 10)
 		x.xxx_hidden_Title = b.Title
 	}
 	if b.Chiphertext != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 10)
 		x.xxx_hidden_Chiphertext = b.Chiphertext
 	}
 	if b.Salt != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 10)
 		x.xxx_hidden_Salt = b.Salt
 	}
 	if b.Nonce != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 10)
 		x.xxx_hidden_Nonce = b.Nonce
 	}
 	if b.Profile != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 7)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 10)
 		x.xxx_hidden_Profile = *b.Profile
 	}
 	x.xxx_hidden_Type = b.Type
+	if b.Cipher != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 7, 10)
+		x.xxx_hidden_Cipher = *b.Cipher
+	}
+	if b.BlockUid != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 8, 10)
+		x.xxx_hidden_BlockUid = b.BlockUid
+	}
+	if b.Format != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 9, 10)
+		x.xxx_hidden_Format = *b.Format
+	}
 	return m0
 }
 
@@ -414,6 +564,9 @@ type SaveDataBlockRequest struct {
 	xxx_hidden_Nonce       []byte                 `protobuf:"bytes,4,opt,name=nonce"`
 	xxx_hidden_Profile     EncProfile             `protobuf:"varint,5,opt,name=profile,enum=storage.EncProfile"`
 	xxx_hidden_TypeId      int32                  `protobuf:"varint,6,opt,name=type_id,json=typeId"`
+	xxx_hidden_Cipher      Cipher                 `protobuf:"varint,7,opt,name=cipher,enum=storage.Cipher"`
+	xxx_hidden_BlockUid    *string                `protobuf:"bytes,8,opt,name=block_uid,json=blockUid"`
+	xxx_hidden_Format      int32                  `protobuf:"varint,9,opt,name=format"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -492,9 +645,35 @@ func (x *SaveDataBlockRequest) GetTypeId() int32 {
 	return 0
 }
 
+func (x *SaveDataBlockRequest) GetCipher() Cipher {
+	if x != nil {
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 6) {
+			return x.xxx_hidden_Cipher
+		}
+	}
+	return Cipher_CIPHER_AES_256_GCM
+}
+
+func (x *SaveDataBlockRequest) GetBlockUid() string {
+	if x != nil {
+		if x.xxx_hidden_BlockUid != nil {
+			return *x.xxx_hidden_BlockUid
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *SaveDataBlockRequest) GetFormat() int32 {
+	if x != nil {
+		return x.xxx_hidden_Format
+	}
+	return 0
+}
+
 func (x *SaveDataBlockRequest) SetTitle(v string) {
 	x.xxx_hidden_Title = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 9)
 }
 
 func (x *SaveDataBlockRequest) SetChiphertext(v []byte) {
@@ -502,7 +681,7 @@ func (x *SaveDataBlockRequest) SetChiphertext(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Chiphertext = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 9)
 }
 
 func (x *SaveDataBlockRequest) SetSalt(v []byte) {
@@ -510,7 +689,7 @@ func (x *SaveDataBlockRequest) SetSalt(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Salt = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 9)
 }
 
 func (x *SaveDataBlockRequest) SetNonce(v []byte) {
@@ -518,17 +697,32 @@ func (x *SaveDataBlockRequest) SetNonce(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Nonce = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 9)
 }
 
 func (x *SaveDataBlockRequest) SetProfile(v EncProfile) {
 	x.xxx_hidden_Profile = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 9)
 }
 
 func (x *SaveDataBlockRequest) SetTypeId(v int32) {
 	x.xxx_hidden_TypeId = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 9)
+}
+
+func (x *SaveDataBlockRequest) SetCipher(v Cipher) {
+	x.xxx_hidden_Cipher = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 6, 9)
+}
+
+func (x *SaveDataBlockRequest) SetBlockUid(v string) {
+	x.xxx_hidden_BlockUid = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 7, 9)
+}
+
+func (x *SaveDataBlockRequest) SetFormat(v int32) {
+	x.xxx_hidden_Format = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 8, 9)
 }
 
 func (x *SaveDataBlockRequest) HasTitle() bool {
@@ -573,6 +767,27 @@ func (x *SaveDataBlockRequest) HasTypeId() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
 }
 
+func (x *SaveDataBlockRequest) HasCipher() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 6)
+}
+
+func (x *SaveDataBlockRequest) HasBlockUid() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 7)
+}
+
+func (x *SaveDataBlockRequest) HasFormat() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 8)
+}
+
 func (x *SaveDataBlockRequest) ClearTitle() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Title = nil
@@ -603,6 +818,21 @@ func (x *SaveDataBlockRequest) ClearTypeId() {
 	x.xxx_hidden_TypeId = 0
 }
 
+func (x *SaveDataBlockRequest) ClearCipher() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 6)
+	x.xxx_hidden_Cipher = Cipher_CIPHER_AES_256_GCM
+}
+
+func (x *SaveDataBlockRequest) ClearBlockUid() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 7)
+	x.xxx_hidden_BlockUid = nil
+}
+
+func (x *SaveDataBlockRequest) ClearFormat() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 8)
+	x.xxx_hidden_Format = 0
+}
+
 type SaveDataBlockRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -612,6 +842,9 @@ type SaveDataBlockRequest_builder struct {
 	Nonce       []byte
 	Profile     *EncProfile
 	TypeId      *int32
+	Cipher      *Cipher
+	BlockUid    *string
+	Format      *int32
 }
 
 func (b0 SaveDataBlockRequest_builder) Build() *SaveDataBlockRequest {
@@ -619,29 +852,41 @@ func (b0 SaveDataBlockRequest_builder) Build() *SaveDataBlockRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Title != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 9)
 		x.xxx_hidden_Title = b.Title
 	}
 	if b.Chiphertext != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 9)
 		x.xxx_hidden_Chiphertext = b.Chiphertext
 	}
 	if b.Salt != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 9)
 		x.xxx_hidden_Salt = b.Salt
 	}
 	if b.Nonce != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 9)
 		x.xxx_hidden_Nonce = b.Nonce
 	}
 	if b.Profile != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 9)
 		x.xxx_hidden_Profile = *b.Profile
 	}
 	if b.TypeId != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 9)
 		x.xxx_hidden_TypeId = *b.TypeId
 	}
+	if b.Cipher != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 6, 9)
+		x.xxx_hidden_Cipher = *b.Cipher
+	}
+	if b.BlockUid != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 7, 9)
+		x.xxx_hidden_BlockUid = b.BlockUid
+	}
+	if b.Format != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 8, 9)
+		x.xxx_hidden_Format = *b.Format
+	}
 	return m0
 }
 
@@ -688,27 +933,37 @@ func (b0 SaveDataBlockResponse_builder) Build() *SaveDataBlockResponse {
 	return m0
 }
 
-type ListDataBlocksResponse struct {
-	state                 protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_DataBlocks *[]*DataBlock          `protobuf:"bytes,1,rep,name=data_blocks,json=dataBlocks"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+// UpdateDataBlockRequest replaces encrypted payload of a block,
+// title and type stay unchanged.
+type UpdateDataBlockRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_BlockId     int32                  `protobuf:"varint,1,opt,name=block_id,json=blockId"`
+	xxx_hidden_Chiphertext []byte                 `protobuf:"bytes,2,opt,name=chiphertext"`
+	xxx_hidden_Salt        []byte                 `protobuf:"bytes,3,opt,name=salt"`
+	xxx_hidden_Nonce       []byte                 `protobuf:"bytes,4,opt,name=nonce"`
+	xxx_hidden_Profile     EncProfile             `protobuf:"varint,5,opt,name=profile,enum=storage.EncProfile"`
+	xxx_hidden_Cipher      Cipher                 `protobuf:"varint,6,opt,name=cipher,enum=storage.Cipher"`
+	xxx_hidden_Format      int32                  `protobuf:"varint,7,opt,name=format"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
-func (x *ListDataBlocksResponse) Reset() {
-	*x = ListDataBlocksResponse{}
+func (x *UpdateDataBlockRequest) Reset() {
+	*x = UpdateDataBlockRequest{}
 	mi := &file_internal_proto_storage_storage_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListDataBlocksResponse) String() string {
+func (x *UpdateDataBlockRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListDataBlocksResponse) ProtoMessage() {}
+func (*UpdateDataBlockRequest) ProtoMessage() {}
 
-func (x *ListDataBlocksResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateDataBlockRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_storage_storage_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -720,116 +975,555 @@ func (x *ListDataBlocksResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *ListDataBlocksResponse) GetDataBlocks() []*DataBlock {
+func (x *UpdateDataBlockRequest) GetBlockId() int32 {
 	if x != nil {
-		if x.xxx_hidden_DataBlocks != nil {
-			return *x.xxx_hidden_DataBlocks
-		}
+		return x.xxx_hidden_BlockId
 	}
-	return nil
-}
-
-func (x *ListDataBlocksResponse) SetDataBlocks(v []*DataBlock) {
-	x.xxx_hidden_DataBlocks = &v
-}
-
-type ListDataBlocksResponse_builder struct {
-	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
-
-	DataBlocks []*DataBlock
-}
-
-func (b0 ListDataBlocksResponse_builder) Build() *ListDataBlocksResponse {
-	m0 := &ListDataBlocksResponse{}
-	b, x := &b0, m0
-	_, _ = b, x
-	x.xxx_hidden_DataBlocks = &b.DataBlocks
-	return m0
-}
-
-type BlockType struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id          int32                  `protobuf:"varint,1,opt,name=id"`
-	xxx_hidden_TypeName    *string                `protobuf:"bytes,2,opt,name=type_name,json=typeName"`
-	xxx_hidden_Description *string                `protobuf:"bytes,3,opt,name=description"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
-}
-
-func (x *BlockType) Reset() {
-	*x = BlockType{}
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+	return 0
 }
 
-func (x *BlockType) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *UpdateDataBlockRequest) GetChiphertext() []byte {
+	if x != nil {
+		return x.xxx_hidden_Chiphertext
+	}
+	return nil
 }
 
-func (*BlockType) ProtoMessage() {}
-
-func (x *BlockType) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[5]
+func (x *UpdateDataBlockRequest) GetSalt() []byte {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.xxx_hidden_Salt
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-func (x *BlockType) GetId() int32 {
+func (x *UpdateDataBlockRequest) GetNonce() []byte {
 	if x != nil {
-		return x.xxx_hidden_Id
+		return x.xxx_hidden_Nonce
 	}
-	return 0
+	return nil
 }
 
-func (x *BlockType) GetTypeName() string {
+func (x *UpdateDataBlockRequest) GetProfile() EncProfile {
 	if x != nil {
-		if x.xxx_hidden_TypeName != nil {
-			return *x.xxx_hidden_TypeName
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 4) {
+			return x.xxx_hidden_Profile
 		}
-		return ""
 	}
-	return ""
+	return EncProfile_PROFILE_V1
 }
 
-func (x *BlockType) GetDescription() string {
+func (x *UpdateDataBlockRequest) GetCipher() Cipher {
 	if x != nil {
-		if x.xxx_hidden_Description != nil {
-			return *x.xxx_hidden_Description
+		if protoimpl.X.Present(&(x.XXX_presence[0]), 5) {
+			return x.xxx_hidden_Cipher
 		}
-		return ""
 	}
-	return ""
+	return Cipher_CIPHER_AES_256_GCM
 }
 
-func (x *BlockType) SetId(v int32) {
-	x.xxx_hidden_Id = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+func (x *UpdateDataBlockRequest) GetFormat() int32 {
+	if x != nil {
+		return x.xxx_hidden_Format
+	}
+	return 0
 }
 
-func (x *BlockType) SetTypeName(v string) {
-	x.xxx_hidden_TypeName = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+func (x *UpdateDataBlockRequest) SetBlockId(v int32) {
+	x.xxx_hidden_BlockId = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
 }
 
-func (x *BlockType) SetDescription(v string) {
-	x.xxx_hidden_Description = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+func (x *UpdateDataBlockRequest) SetChiphertext(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Chiphertext = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
 }
 
-func (x *BlockType) HasId() bool {
-	if x == nil {
-		return false
+func (x *UpdateDataBlockRequest) SetSalt(v []byte) {
+	if v == nil {
+		v = []byte{}
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Salt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 7)
+}
+
+func (x *UpdateDataBlockRequest) SetNonce(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Nonce = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 7)
+}
+
+func (x *UpdateDataBlockRequest) SetProfile(v EncProfile) {
+	x.xxx_hidden_Profile = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 7)
+}
+
+func (x *UpdateDataBlockRequest) SetCipher(v Cipher) {
+	x.xxx_hidden_Cipher = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 7)
+}
+
+func (x *UpdateDataBlockRequest) SetFormat(v int32) {
+	x.xxx_hidden_Format = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 6, 7)
+}
+
+func (x *UpdateDataBlockRequest) HasBlockId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UpdateDataBlockRequest) HasChiphertext() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *UpdateDataBlockRequest) HasSalt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *UpdateDataBlockRequest) HasNonce() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *UpdateDataBlockRequest) HasProfile() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *UpdateDataBlockRequest) HasCipher() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *UpdateDataBlockRequest) HasFormat() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 6)
+}
+
+func (x *UpdateDataBlockRequest) ClearBlockId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_BlockId = 0
+}
+
+func (x *UpdateDataBlockRequest) ClearChiphertext() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Chiphertext = nil
+}
+
+func (x *UpdateDataBlockRequest) ClearSalt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Salt = nil
+}
+
+func (x *UpdateDataBlockRequest) ClearNonce() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Nonce = nil
+}
+
+func (x *UpdateDataBlockRequest) ClearProfile() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_Profile = EncProfile_PROFILE_V1
+}
+
+func (x *UpdateDataBlockRequest) ClearCipher() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Cipher = Cipher_CIPHER_AES_256_GCM
+}
+
+func (x *UpdateDataBlockRequest) ClearFormat() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 6)
+	x.xxx_hidden_Format = 0
+}
+
+type UpdateDataBlockRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	BlockId     *int32
+	Chiphertext []byte
+	Salt        []byte
+	Nonce       []byte
+	Profile     *EncProfile
+	Cipher      *Cipher
+	Format      *int32
+}
+
+func (b0 UpdateDataBlockRequest_builder) Build() *UpdateDataBlockRequest {
+	m0 := &UpdateDataBlockRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.BlockId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
+		x.xxx_hidden_BlockId = *b.BlockId
+	}
+	if b.Chiphertext != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
+		x.xxx_hidden_Chiphertext = b.Chiphertext
+	}
+	if b.Salt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 7)
+		x.xxx_hidden_Salt = b.Salt
+	}
+	if b.Nonce != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 7)
+		x.xxx_hidden_Nonce = b.Nonce
+	}
+	if b.Profile != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 7)
+		x.xxx_hidden_Profile = *b.Profile
+	}
+	if b.Cipher != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 7)
+		x.xxx_hidden_Cipher = *b.Cipher
+	}
+	if b.Format != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 6, 7)
+		x.xxx_hidden_Format = *b.Format
+	}
+	return m0
+}
+
+type UpdateDataBlockResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDataBlockResponse) Reset() {
+	*x = UpdateDataBlockResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDataBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDataBlockResponse) ProtoMessage() {}
+
+func (x *UpdateDataBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type UpdateDataBlockResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 UpdateDataBlockResponse_builder) Build() *UpdateDataBlockResponse {
+	m0 := &UpdateDataBlockResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// UpdateDataBlocksRequest replaces encrypted payloads of up to 100 blocks,
+// either all of them are updated or none.
+type UpdateDataBlocksRequest struct {
+	state             protoimpl.MessageState     `protogen:"opaque.v1"`
+	xxx_hidden_Blocks *[]*UpdateDataBlockRequest `protobuf:"bytes,1,rep,name=blocks"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UpdateDataBlocksRequest) Reset() {
+	*x = UpdateDataBlocksRequest{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDataBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDataBlocksRequest) ProtoMessage() {}
+
+func (x *UpdateDataBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UpdateDataBlocksRequest) GetBlocks() []*UpdateDataBlockRequest {
+	if x != nil {
+		if x.xxx_hidden_Blocks != nil {
+			return *x.xxx_hidden_Blocks
+		}
+	}
+	return nil
+}
+
+func (x *UpdateDataBlocksRequest) SetBlocks(v []*UpdateDataBlockRequest) {
+	x.xxx_hidden_Blocks = &v
+}
+
+type UpdateDataBlocksRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Blocks []*UpdateDataBlockRequest
+}
+
+func (b0 UpdateDataBlocksRequest_builder) Build() *UpdateDataBlocksRequest {
+	m0 := &UpdateDataBlocksRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Blocks = &b.Blocks
+	return m0
+}
+
+type UpdateDataBlocksResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Updated     int32                  `protobuf:"varint,1,opt,name=updated"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UpdateDataBlocksResponse) Reset() {
+	*x = UpdateDataBlocksResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDataBlocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDataBlocksResponse) ProtoMessage() {}
+
+func (x *UpdateDataBlocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UpdateDataBlocksResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.xxx_hidden_Updated
+	}
+	return 0
+}
+
+func (x *UpdateDataBlocksResponse) SetUpdated(v int32) {
+	x.xxx_hidden_Updated = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *UpdateDataBlocksResponse) HasUpdated() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UpdateDataBlocksResponse) ClearUpdated() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Updated = 0
+}
+
+type UpdateDataBlocksResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Updated *int32
+}
+
+func (b0 UpdateDataBlocksResponse_builder) Build() *UpdateDataBlocksResponse {
+	m0 := &UpdateDataBlocksResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Updated != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Updated = *b.Updated
+	}
+	return m0
+}
+
+type ListDataBlocksResponse struct {
+	state                 protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_DataBlocks *[]*DataBlock          `protobuf:"bytes,1,rep,name=data_blocks,json=dataBlocks"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ListDataBlocksResponse) Reset() {
+	*x = ListDataBlocksResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataBlocksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataBlocksResponse) ProtoMessage() {}
+
+func (x *ListDataBlocksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListDataBlocksResponse) GetDataBlocks() []*DataBlock {
+	if x != nil {
+		if x.xxx_hidden_DataBlocks != nil {
+			return *x.xxx_hidden_DataBlocks
+		}
+	}
+	return nil
+}
+
+func (x *ListDataBlocksResponse) SetDataBlocks(v []*DataBlock) {
+	x.xxx_hidden_DataBlocks = &v
+}
+
+type ListDataBlocksResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	DataBlocks []*DataBlock
+}
+
+func (b0 ListDataBlocksResponse_builder) Build() *ListDataBlocksResponse {
+	m0 := &ListDataBlocksResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_DataBlocks = &b.DataBlocks
+	return m0
+}
+
+type BlockType struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          int32                  `protobuf:"varint,1,opt,name=id"`
+	xxx_hidden_TypeName    *string                `protobuf:"bytes,2,opt,name=type_name,json=typeName"`
+	xxx_hidden_Description *string                `protobuf:"bytes,3,opt,name=description"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *BlockType) Reset() {
+	*x = BlockType{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockType) ProtoMessage() {}
+
+func (x *BlockType) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *BlockType) GetId() int32 {
+	if x != nil {
+		return x.xxx_hidden_Id
+	}
+	return 0
+}
+
+func (x *BlockType) GetTypeName() string {
+	if x != nil {
+		if x.xxx_hidden_TypeName != nil {
+			return *x.xxx_hidden_TypeName
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *BlockType) GetDescription() string {
+	if x != nil {
+		if x.xxx_hidden_Description != nil {
+			return *x.xxx_hidden_Description
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *BlockType) SetId(v int32) {
+	x.xxx_hidden_Id = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *BlockType) SetTypeName(v string) {
+	x.xxx_hidden_TypeName = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *BlockType) SetDescription(v string) {
+	x.xxx_hidden_Description = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *BlockType) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
 func (x *BlockType) HasTypeName() bool {
@@ -839,76 +1533,505 @@ func (x *BlockType) HasTypeName() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
 }
 
-func (x *BlockType) HasDescription() bool {
+func (x *BlockType) HasDescription() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *BlockType) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = 0
+}
+
+func (x *BlockType) ClearTypeName() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_TypeName = nil
+}
+
+func (x *BlockType) ClearDescription() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Description = nil
+}
+
+type BlockType_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id          *int32
+	TypeName    *string
+	Description *string
+}
+
+func (b0 BlockType_builder) Build() *BlockType {
+	m0 := &BlockType{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Id = *b.Id
+	}
+	if b.TypeName != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_TypeName = b.TypeName
+	}
+	if b.Description != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_Description = b.Description
+	}
+	return m0
+}
+
+type GetBlockTypesRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockTypesRequest) Reset() {
+	*x = GetBlockTypesRequest{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockTypesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockTypesRequest) ProtoMessage() {}
+
+func (x *GetBlockTypesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type GetBlockTypesRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 GetBlockTypesRequest_builder) Build() *GetBlockTypesRequest {
+	m0 := &GetBlockTypesRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type GetBlockTypesResponse struct {
+	state                 protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_BlockTypes *[]*BlockType          `protobuf:"bytes,1,rep,name=block_types,json=blockTypes"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *GetBlockTypesResponse) Reset() {
+	*x = GetBlockTypesResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockTypesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockTypesResponse) ProtoMessage() {}
+
+func (x *GetBlockTypesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetBlockTypesResponse) GetBlockTypes() []*BlockType {
+	if x != nil {
+		if x.xxx_hidden_BlockTypes != nil {
+			return *x.xxx_hidden_BlockTypes
+		}
+	}
+	return nil
+}
+
+func (x *GetBlockTypesResponse) SetBlockTypes(v []*BlockType) {
+	x.xxx_hidden_BlockTypes = &v
+}
+
+type GetBlockTypesResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	BlockTypes []*BlockType
+}
+
+func (b0 GetBlockTypesResponse_builder) Build() *GetBlockTypesResponse {
+	m0 := &GetBlockTypesResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_BlockTypes = &b.BlockTypes
+	return m0
+}
+
+type GetUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageRequest) Reset() {
+	*x = GetUsageRequest{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageRequest) ProtoMessage() {}
+
+func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type GetUsageRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 GetUsageRequest_builder) Build() *GetUsageRequest {
+	m0 := &GetUsageRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// BlockTypeQuota limits encrypted payload size of a single block of the type.
+type BlockTypeQuota struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Type         *BlockType             `protobuf:"bytes,1,opt,name=type"`
+	xxx_hidden_MaxBlockSize int64                  `protobuf:"varint,2,opt,name=max_block_size,json=maxBlockSize"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *BlockTypeQuota) Reset() {
+	*x = BlockTypeQuota{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockTypeQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockTypeQuota) ProtoMessage() {}
+
+func (x *BlockTypeQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *BlockTypeQuota) GetType() *BlockType {
+	if x != nil {
+		return x.xxx_hidden_Type
+	}
+	return nil
+}
+
+func (x *BlockTypeQuota) GetMaxBlockSize() int64 {
+	if x != nil {
+		return x.xxx_hidden_MaxBlockSize
+	}
+	return 0
+}
+
+func (x *BlockTypeQuota) SetType(v *BlockType) {
+	x.xxx_hidden_Type = v
+}
+
+func (x *BlockTypeQuota) SetMaxBlockSize(v int64) {
+	x.xxx_hidden_MaxBlockSize = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *BlockTypeQuota) HasType() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Type != nil
+}
+
+func (x *BlockTypeQuota) HasMaxBlockSize() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *BlockTypeQuota) ClearType() {
+	x.xxx_hidden_Type = nil
+}
+
+func (x *BlockTypeQuota) ClearMaxBlockSize() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_MaxBlockSize = 0
+}
+
+type BlockTypeQuota_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Type         *BlockType
+	MaxBlockSize *int64
+}
+
+func (b0 BlockTypeQuota_builder) Build() *BlockTypeQuota {
+	m0 := &BlockTypeQuota{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Type = b.Type
+	if b.MaxBlockSize != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_MaxBlockSize = *b.MaxBlockSize
+	}
+	return m0
+}
+
+// GetUsageResponse reports user storage consumption, sizes are in bytes of
+// encrypted payload and zero limits mean unlimited.
+type GetUsageResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_UsedBytes   int64                  `protobuf:"varint,1,opt,name=used_bytes,json=usedBytes"`
+	xxx_hidden_MaxBytes    int64                  `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes"`
+	xxx_hidden_BlockCount  int32                  `protobuf:"varint,3,opt,name=block_count,json=blockCount"`
+	xxx_hidden_MaxBlocks   int32                  `protobuf:"varint,4,opt,name=max_blocks,json=maxBlocks"`
+	xxx_hidden_TypeQuotas  *[]*BlockTypeQuota     `protobuf:"bytes,5,rep,name=type_quotas,json=typeQuotas"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetUsageResponse) Reset() {
+	*x = GetUsageResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageResponse) ProtoMessage() {}
+
+func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetUsageResponse) GetUsedBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_UsedBytes
+	}
+	return 0
+}
+
+func (x *GetUsageResponse) GetMaxBytes() int64 {
+	if x != nil {
+		return x.xxx_hidden_MaxBytes
+	}
+	return 0
+}
+
+func (x *GetUsageResponse) GetBlockCount() int32 {
+	if x != nil {
+		return x.xxx_hidden_BlockCount
+	}
+	return 0
+}
+
+func (x *GetUsageResponse) GetMaxBlocks() int32 {
+	if x != nil {
+		return x.xxx_hidden_MaxBlocks
+	}
+	return 0
+}
+
+func (x *GetUsageResponse) GetTypeQuotas() []*BlockTypeQuota {
+	if x != nil {
+		if x.xxx_hidden_TypeQuotas != nil {
+			return *x.xxx_hidden_TypeQuotas
+		}
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) SetUsedBytes(v int64) {
+	x.xxx_hidden_UsedBytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+}
+
+func (x *GetUsageResponse) SetMaxBytes(v int64) {
+	x.xxx_hidden_MaxBytes = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+}
+
+func (x *GetUsageResponse) SetBlockCount(v int32) {
+	x.xxx_hidden_BlockCount = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+}
+
+func (x *GetUsageResponse) SetMaxBlocks(v int32) {
+	x.xxx_hidden_MaxBlocks = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+}
+
+func (x *GetUsageResponse) SetTypeQuotas(v []*BlockTypeQuota) {
+	x.xxx_hidden_TypeQuotas = &v
+}
+
+func (x *GetUsageResponse) HasUsedBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetUsageResponse) HasMaxBytes() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetUsageResponse) HasBlockCount() bool {
 	if x == nil {
 		return false
 	}
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
-func (x *BlockType) ClearId() {
+func (x *GetUsageResponse) HasMaxBlocks() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *GetUsageResponse) ClearUsedBytes() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_Id = 0
+	x.xxx_hidden_UsedBytes = 0
 }
 
-func (x *BlockType) ClearTypeName() {
+func (x *GetUsageResponse) ClearMaxBytes() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
-	x.xxx_hidden_TypeName = nil
+	x.xxx_hidden_MaxBytes = 0
 }
 
-func (x *BlockType) ClearDescription() {
+func (x *GetUsageResponse) ClearBlockCount() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
-	x.xxx_hidden_Description = nil
+	x.xxx_hidden_BlockCount = 0
 }
 
-type BlockType_builder struct {
+func (x *GetUsageResponse) ClearMaxBlocks() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_MaxBlocks = 0
+}
+
+type GetUsageResponse_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Id          *int32
-	TypeName    *string
-	Description *string
+	UsedBytes  *int64
+	MaxBytes   *int64
+	BlockCount *int32
+	MaxBlocks  *int32
+	TypeQuotas []*BlockTypeQuota
 }
 
-func (b0 BlockType_builder) Build() *BlockType {
-	m0 := &BlockType{}
+func (b0 GetUsageResponse_builder) Build() *GetUsageResponse {
+	m0 := &GetUsageResponse{}
 	b, x := &b0, m0
 	_, _ = b, x
-	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
-		x.xxx_hidden_Id = *b.Id
+	if b.UsedBytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
+		x.xxx_hidden_UsedBytes = *b.UsedBytes
 	}
-	if b.TypeName != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
-		x.xxx_hidden_TypeName = b.TypeName
+	if b.MaxBytes != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
+		x.xxx_hidden_MaxBytes = *b.MaxBytes
 	}
-	if b.Description != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
-		x.xxx_hidden_Description = b.Description
+	if b.BlockCount != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		x.xxx_hidden_BlockCount = *b.BlockCount
+	}
+	if b.MaxBlocks != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		x.xxx_hidden_MaxBlocks = *b.MaxBlocks
 	}
+	x.xxx_hidden_TypeQuotas = &b.TypeQuotas
 	return m0
 }
 
-type GetBlockTypesRequest struct {
+type GetVaultKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"opaque.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlockTypesRequest) Reset() {
-	*x = GetBlockTypesRequest{}
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[6]
+func (x *GetVaultKeyRequest) Reset() {
+	*x = GetVaultKeyRequest{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlockTypesRequest) String() string {
+func (x *GetVaultKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlockTypesRequest) ProtoMessage() {}
+func (*GetVaultKeyRequest) ProtoMessage() {}
 
-func (x *GetBlockTypesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[6]
+func (x *GetVaultKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -919,40 +2042,44 @@ func (x *GetBlockTypesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-type GetBlockTypesRequest_builder struct {
+type GetVaultKeyRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 }
 
-func (b0 GetBlockTypesRequest_builder) Build() *GetBlockTypesRequest {
-	m0 := &GetBlockTypesRequest{}
+func (b0 GetVaultKeyRequest_builder) Build() *GetVaultKeyRequest {
+	m0 := &GetVaultKeyRequest{}
 	b, x := &b0, m0
 	_, _ = b, x
 	return m0
 }
 
-type GetBlockTypesResponse struct {
-	state                 protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_BlockTypes *[]*BlockType          `protobuf:"bytes,1,rep,name=block_types,json=blockTypes"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+// GetVaultKeyResponse carries the user's vault data key wrapped by a key
+// derived from the master password, as a ciphertext envelope.
+type GetVaultKeyResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_WrappedKey  []byte                 `protobuf:"bytes,1,opt,name=wrapped_key,json=wrappedKey"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
-func (x *GetBlockTypesResponse) Reset() {
-	*x = GetBlockTypesResponse{}
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[7]
+func (x *GetVaultKeyResponse) Reset() {
+	*x = GetVaultKeyResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlockTypesResponse) String() string {
+func (x *GetVaultKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlockTypesResponse) ProtoMessage() {}
+func (*GetVaultKeyResponse) ProtoMessage() {}
 
-func (x *GetBlockTypesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_storage_storage_proto_msgTypes[7]
+func (x *GetVaultKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,30 +2090,203 @@ func (x *GetBlockTypesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-func (x *GetBlockTypesResponse) GetBlockTypes() []*BlockType {
+func (x *GetVaultKeyResponse) GetWrappedKey() []byte {
 	if x != nil {
-		if x.xxx_hidden_BlockTypes != nil {
-			return *x.xxx_hidden_BlockTypes
+		return x.xxx_hidden_WrappedKey
+	}
+	return nil
+}
+
+func (x *GetVaultKeyResponse) SetWrappedKey(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_WrappedKey = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *GetVaultKeyResponse) HasWrappedKey() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetVaultKeyResponse) ClearWrappedKey() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_WrappedKey = nil
+}
+
+type GetVaultKeyResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	WrappedKey []byte
+}
+
+func (b0 GetVaultKeyResponse_builder) Build() *GetVaultKeyResponse {
+	m0 := &GetVaultKeyResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.WrappedKey != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_WrappedKey = b.WrappedKey
+	}
+	return m0
+}
+
+// SetVaultKeyRequest replaces the wrapped vault key if the stored one equals
+// expected_wrapped_key, an empty one sets up the first key.
+type SetVaultKeyRequest struct {
+	state                         protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_WrappedKey         []byte                 `protobuf:"bytes,1,opt,name=wrapped_key,json=wrappedKey"`
+	xxx_hidden_ExpectedWrappedKey []byte                 `protobuf:"bytes,2,opt,name=expected_wrapped_key,json=expectedWrappedKey"`
+	XXX_raceDetectHookData        protoimpl.RaceDetectHookData
+	XXX_presence                  [1]uint32
+	unknownFields                 protoimpl.UnknownFields
+	sizeCache                     protoimpl.SizeCache
+}
+
+func (x *SetVaultKeyRequest) Reset() {
+	*x = SetVaultKeyRequest{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetVaultKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetVaultKeyRequest) ProtoMessage() {}
+
+func (x *SetVaultKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *SetVaultKeyRequest) GetWrappedKey() []byte {
+	if x != nil {
+		return x.xxx_hidden_WrappedKey
 	}
 	return nil
 }
 
-func (x *GetBlockTypesResponse) SetBlockTypes(v []*BlockType) {
-	x.xxx_hidden_BlockTypes = &v
+func (x *SetVaultKeyRequest) GetExpectedWrappedKey() []byte {
+	if x != nil {
+		return x.xxx_hidden_ExpectedWrappedKey
+	}
+	return nil
 }
 
-type GetBlockTypesResponse_builder struct {
+func (x *SetVaultKeyRequest) SetWrappedKey(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_WrappedKey = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *SetVaultKeyRequest) SetExpectedWrappedKey(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_ExpectedWrappedKey = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *SetVaultKeyRequest) HasWrappedKey() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *SetVaultKeyRequest) HasExpectedWrappedKey() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *SetVaultKeyRequest) ClearWrappedKey() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_WrappedKey = nil
+}
+
+func (x *SetVaultKeyRequest) ClearExpectedWrappedKey() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_ExpectedWrappedKey = nil
+}
+
+type SetVaultKeyRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	BlockTypes []*BlockType
+	WrappedKey         []byte
+	ExpectedWrappedKey []byte
 }
 
-func (b0 GetBlockTypesResponse_builder) Build() *GetBlockTypesResponse {
-	m0 := &GetBlockTypesResponse{}
+func (b0 SetVaultKeyRequest_builder) Build() *SetVaultKeyRequest {
+	m0 := &SetVaultKeyRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.WrappedKey != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_WrappedKey = b.WrappedKey
+	}
+	if b.ExpectedWrappedKey != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_ExpectedWrappedKey = b.ExpectedWrappedKey
+	}
+	return m0
+}
+
+type SetVaultKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetVaultKeyResponse) Reset() {
+	*x = SetVaultKeyResponse{}
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetVaultKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetVaultKeyResponse) ProtoMessage() {}
+
+func (x *SetVaultKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_storage_storage_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type SetVaultKeyResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 SetVaultKeyResponse_builder) Build() *SetVaultKeyResponse {
+	m0 := &SetVaultKeyResponse{}
 	b, x := &b0, m0
 	_, _ = b, x
-	x.xxx_hidden_BlockTypes = &b.BlockTypes
 	return m0
 }
 
@@ -996,7 +2296,7 @@ const file_internal_proto_storage_storage_proto_rawDesc = "" +
 	"\n" +
 	"$internal/proto/storage/storage.proto\x12\astorage\"4\n" +
 	"\x15ListDataBlocksRequest\x12\x1b\n" +
-	"\tclient_id\x18\x01 \x01(\tR\bclientId\"\xdf\x01\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\"\xbd\x02\n" +
 	"\tDataBlock\x12\x19\n" +
 	"\bblock_id\x18\x01 \x01(\x05R\ablockId\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
@@ -1004,15 +2304,35 @@ const file_internal_proto_storage_storage_proto_rawDesc = "" +
 	"\x04salt\x18\x04 \x01(\fR\x04salt\x12\x14\n" +
 	"\x05nonce\x18\x05 \x01(\fR\x05nonce\x12-\n" +
 	"\aprofile\x18\x06 \x01(\x0e2\x13.storage.EncProfileR\aprofile\x12&\n" +
-	"\x04type\x18\a \x01(\v2\x12.storage.BlockTypeR\x04type\"\xc0\x01\n" +
+	"\x04type\x18\a \x01(\v2\x12.storage.BlockTypeR\x04type\x12'\n" +
+	"\x06cipher\x18\b \x01(\x0e2\x0f.storage.CipherR\x06cipher\x12\x1b\n" +
+	"\tblock_uid\x18\t \x01(\tR\bblockUid\x12\x16\n" +
+	"\x06format\x18\n" +
+	" \x01(\x05R\x06format\"\x9e\x02\n" +
 	"\x14SaveDataBlockRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
 	"\vchiphertext\x18\x02 \x01(\fR\vchiphertext\x12\x12\n" +
 	"\x04salt\x18\x03 \x01(\fR\x04salt\x12\x14\n" +
 	"\x05nonce\x18\x04 \x01(\fR\x05nonce\x12-\n" +
 	"\aprofile\x18\x05 \x01(\x0e2\x13.storage.EncProfileR\aprofile\x12\x17\n" +
-	"\atype_id\x18\x06 \x01(\x05R\x06typeId\"\x17\n" +
-	"\x15SaveDataBlockResponse\"M\n" +
+	"\atype_id\x18\x06 \x01(\x05R\x06typeId\x12'\n" +
+	"\x06cipher\x18\a \x01(\x0e2\x0f.storage.CipherR\x06cipher\x12\x1b\n" +
+	"\tblock_uid\x18\b \x01(\tR\bblockUid\x12\x16\n" +
+	"\x06format\x18\t \x01(\x05R\x06format\"\x17\n" +
+	"\x15SaveDataBlockResponse\"\xef\x01\n" +
+	"\x16UpdateDataBlockRequest\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\x05R\ablockId\x12 \n" +
+	"\vchiphertext\x18\x02 \x01(\fR\vchiphertext\x12\x12\n" +
+	"\x04salt\x18\x03 \x01(\fR\x04salt\x12\x14\n" +
+	"\x05nonce\x18\x04 \x01(\fR\x05nonce\x12-\n" +
+	"\aprofile\x18\x05 \x01(\x0e2\x13.storage.EncProfileR\aprofile\x12'\n" +
+	"\x06cipher\x18\x06 \x01(\x0e2\x0f.storage.CipherR\x06cipher\x12\x16\n" +
+	"\x06format\x18\a \x01(\x05R\x06format\"\x19\n" +
+	"\x17UpdateDataBlockResponse\"R\n" +
+	"\x17UpdateDataBlocksRequest\x127\n" +
+	"\x06blocks\x18\x01 \x03(\v2\x1f.storage.UpdateDataBlockRequestR\x06blocks\"4\n" +
+	"\x18UpdateDataBlocksResponse\x12\x18\n" +
+	"\aupdated\x18\x01 \x01(\x05R\aupdated\"M\n" +
 	"\x16ListDataBlocksResponse\x123\n" +
 	"\vdata_blocks\x18\x01 \x03(\v2\x12.storage.DataBlockR\n" +
 	"dataBlocks\"Z\n" +
@@ -1023,7 +2343,30 @@ const file_internal_proto_storage_storage_proto_rawDesc = "" +
 	"\x14GetBlockTypesRequest\"L\n" +
 	"\x15GetBlockTypesResponse\x123\n" +
 	"\vblock_types\x18\x01 \x03(\v2\x12.storage.BlockTypeR\n" +
-	"blockTypes*<\n" +
+	"blockTypes\"\x11\n" +
+	"\x0fGetUsageRequest\"^\n" +
+	"\x0eBlockTypeQuota\x12&\n" +
+	"\x04type\x18\x01 \x01(\v2\x12.storage.BlockTypeR\x04type\x12$\n" +
+	"\x0emax_block_size\x18\x02 \x01(\x03R\fmaxBlockSize\"\xc8\x01\n" +
+	"\x10GetUsageResponse\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x01 \x01(\x03R\tusedBytes\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x03R\bmaxBytes\x12\x1f\n" +
+	"\vblock_count\x18\x03 \x01(\x05R\n" +
+	"blockCount\x12\x1d\n" +
+	"\n" +
+	"max_blocks\x18\x04 \x01(\x05R\tmaxBlocks\x128\n" +
+	"\vtype_quotas\x18\x05 \x03(\v2\x17.storage.BlockTypeQuotaR\n" +
+	"typeQuotas\"\x14\n" +
+	"\x12GetVaultKeyRequest\"6\n" +
+	"\x13GetVaultKeyResponse\x12\x1f\n" +
+	"\vwrapped_key\x18\x01 \x01(\fR\n" +
+	"wrappedKey\"g\n" +
+	"\x12SetVaultKeyRequest\x12\x1f\n" +
+	"\vwrapped_key\x18\x01 \x01(\fR\n" +
+	"wrappedKey\x120\n" +
+	"\x14expected_wrapped_key\x18\x02 \x01(\fR\x12expectedWrappedKey\"\x15\n" +
+	"\x13SetVaultKeyResponse*\x87\x01\n" +
 	"\n" +
 	"EncProfile\x12\x0e\n" +
 	"\n" +
@@ -1031,42 +2374,82 @@ const file_internal_proto_storage_storage_proto_rawDesc = "" +
 	"\n" +
 	"PROFILE_V2\x10\x01\x12\x0e\n" +
 	"\n" +
-	"PROFILE_V3\x10\x022\x86\x02\n" +
+	"PROFILE_V3\x10\x02\x12\x17\n" +
+	"\x13PROFILE_ARGON2ID_V1\x10\x03\x12\x17\n" +
+	"\x13PROFILE_ARGON2ID_V2\x10\x04\x12\x17\n" +
+	"\x13PROFILE_ARGON2ID_V3\x10\x05*?\n" +
+	"\x06Cipher\x12\x16\n" +
+	"\x12CIPHER_AES_256_GCM\x10\x00\x12\x1d\n" +
+	"\x19CIPHER_XCHACHA20_POLY1305\x10\x012\x8a\x05\n" +
 	"\x0eStorageService\x12N\n" +
-	"\rSaveDataBlock\x12\x1d.storage.SaveDataBlockRequest\x1a\x1e.storage.SaveDataBlockResponse\x12S\n" +
+	"\rSaveDataBlock\x12\x1d.storage.SaveDataBlockRequest\x1a\x1e.storage.SaveDataBlockResponse\x12T\n" +
+	"\x0fUpdateDataBlock\x12\x1f.storage.UpdateDataBlockRequest\x1a .storage.UpdateDataBlockResponse\x12W\n" +
+	"\x10UpdateDataBlocks\x12 .storage.UpdateDataBlocksRequest\x1a!.storage.UpdateDataBlocksResponse\x12S\n" +
 	"\x0eListDataBlocks\x12\x1e.storage.ListDataBlocksRequest\x1a\x1f.storage.ListDataBlocksResponse0\x01\x12O\n" +
-	"\x0eListBlockTypes\x12\x1d.storage.GetBlockTypesRequest\x1a\x1e.storage.GetBlockTypesResponseB\x18Z\x16internal/proto/storageb\beditionsp\xe9\a"
+	"\x0eListBlockTypes\x12\x1d.storage.GetBlockTypesRequest\x1a\x1e.storage.GetBlockTypesResponse\x12?\n" +
+	"\bGetUsage\x12\x18.storage.GetUsageRequest\x1a\x19.storage.GetUsageResponse\x12H\n" +
+	"\vGetVaultKey\x12\x1b.storage.GetVaultKeyRequest\x1a\x1c.storage.GetVaultKeyResponse\x12H\n" +
+	"\vSetVaultKey\x12\x1b.storage.SetVaultKeyRequest\x1a\x1c.storage.SetVaultKeyResponseB\x18Z\x16internal/proto/storageb\beditionsp\xe9\a"
 
-var file_internal_proto_storage_storage_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_proto_storage_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_proto_storage_storage_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_internal_proto_storage_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_internal_proto_storage_storage_proto_goTypes = []any{
-	(EncProfile)(0),                // 0: storage.EncProfile
-	(*ListDataBlocksRequest)(nil),  // 1: storage.ListDataBlocksRequest
-	(*DataBlock)(nil),              // 2: storage.DataBlock
-	(*SaveDataBlockRequest)(nil),   // 3: storage.SaveDataBlockRequest
-	(*SaveDataBlockResponse)(nil),  // 4: storage.SaveDataBlockResponse
-	(*ListDataBlocksResponse)(nil), // 5: storage.ListDataBlocksResponse
-	(*BlockType)(nil),              // 6: storage.BlockType
-	(*GetBlockTypesRequest)(nil),   // 7: storage.GetBlockTypesRequest
-	(*GetBlockTypesResponse)(nil),  // 8: storage.GetBlockTypesResponse
+	(EncProfile)(0),                  // 0: storage.EncProfile
+	(Cipher)(0),                      // 1: storage.Cipher
+	(*ListDataBlocksRequest)(nil),    // 2: storage.ListDataBlocksRequest
+	(*DataBlock)(nil),                // 3: storage.DataBlock
+	(*SaveDataBlockRequest)(nil),     // 4: storage.SaveDataBlockRequest
+	(*SaveDataBlockResponse)(nil),    // 5: storage.SaveDataBlockResponse
+	(*UpdateDataBlockRequest)(nil),   // 6: storage.UpdateDataBlockRequest
+	(*UpdateDataBlockResponse)(nil),  // 7: storage.UpdateDataBlockResponse
+	(*UpdateDataBlocksRequest)(nil),  // 8: storage.UpdateDataBlocksRequest
+	(*UpdateDataBlocksResponse)(nil), // 9: storage.UpdateDataBlocksResponse
+	(*ListDataBlocksResponse)(nil),   // 10: storage.ListDataBlocksResponse
+	(*BlockType)(nil),                // 11: storage.BlockType
+	(*GetBlockTypesRequest)(nil),     // 12: storage.GetBlockTypesRequest
+	(*GetBlockTypesResponse)(nil),    // 13: storage.GetBlockTypesResponse
+	(*GetUsageRequest)(nil),          // 14: storage.GetUsageRequest
+	(*BlockTypeQuota)(nil),           // 15: storage.BlockTypeQuota
+	(*GetUsageResponse)(nil),         // 16: storage.GetUsageResponse
+	(*GetVaultKeyRequest)(nil),       // 17: storage.GetVaultKeyRequest
+	(*GetVaultKeyResponse)(nil),      // 18: storage.GetVaultKeyResponse
+	(*SetVaultKeyRequest)(nil),       // 19: storage.SetVaultKeyRequest
+	(*SetVaultKeyResponse)(nil),      // 20: storage.SetVaultKeyResponse
 }
 var file_internal_proto_storage_storage_proto_depIdxs = []int32{
-	0, // 0: storage.DataBlock.profile:type_name -> storage.EncProfile
-	6, // 1: storage.DataBlock.type:type_name -> storage.BlockType
-	0, // 2: storage.SaveDataBlockRequest.profile:type_name -> storage.EncProfile
-	2, // 3: storage.ListDataBlocksResponse.data_blocks:type_name -> storage.DataBlock
-	6, // 4: storage.GetBlockTypesResponse.block_types:type_name -> storage.BlockType
-	3, // 5: storage.StorageService.SaveDataBlock:input_type -> storage.SaveDataBlockRequest
-	1, // 6: storage.StorageService.ListDataBlocks:input_type -> storage.ListDataBlocksRequest
-	7, // 7: storage.StorageService.ListBlockTypes:input_type -> storage.GetBlockTypesRequest
-	4, // 8: storage.StorageService.SaveDataBlock:output_type -> storage.SaveDataBlockResponse
-	5, // 9: storage.StorageService.ListDataBlocks:output_type -> storage.ListDataBlocksResponse
-	8, // 10: storage.StorageService.ListBlockTypes:output_type -> storage.GetBlockTypesResponse
-	8, // [8:11] is the sub-list for method output_type
-	5, // [5:8] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	0,  // 0: storage.DataBlock.profile:type_name -> storage.EncProfile
+	11, // 1: storage.DataBlock.type:type_name -> storage.BlockType
+	1,  // 2: storage.DataBlock.cipher:type_name -> storage.Cipher
+	0,  // 3: storage.SaveDataBlockRequest.profile:type_name -> storage.EncProfile
+	1,  // 4: storage.SaveDataBlockRequest.cipher:type_name -> storage.Cipher
+	0,  // 5: storage.UpdateDataBlockRequest.profile:type_name -> storage.EncProfile
+	1,  // 6: storage.UpdateDataBlockRequest.cipher:type_name -> storage.Cipher
+	6,  // 7: storage.UpdateDataBlocksRequest.blocks:type_name -> storage.UpdateDataBlockRequest
+	3,  // 8: storage.ListDataBlocksResponse.data_blocks:type_name -> storage.DataBlock
+	11, // 9: storage.GetBlockTypesResponse.block_types:type_name -> storage.BlockType
+	11, // 10: storage.BlockTypeQuota.type:type_name -> storage.BlockType
+	15, // 11: storage.GetUsageResponse.type_quotas:type_name -> storage.BlockTypeQuota
+	4,  // 12: storage.StorageService.SaveDataBlock:input_type -> storage.SaveDataBlockRequest
+	6,  // 13: storage.StorageService.UpdateDataBlock:input_type -> storage.UpdateDataBlockRequest
+	8,  // 14: storage.StorageService.UpdateDataBlocks:input_type -> storage.UpdateDataBlocksRequest
+	2,  // 15: storage.StorageService.ListDataBlocks:input_type -> storage.ListDataBlocksRequest
+	12, // 16: storage.StorageService.ListBlockTypes:input_type -> storage.GetBlockTypesRequest
+	14, // 17: storage.StorageService.GetUsage:input_type -> storage.GetUsageRequest
+	17, // 18: storage.StorageService.GetVaultKey:input_type -> storage.GetVaultKeyRequest
+	19, // 19: storage.StorageService.SetVaultKey:input_type -> storage.SetVaultKeyRequest
+	5,  // 20: storage.StorageService.SaveDataBlock:output_type -> storage.SaveDataBlockResponse
+	7,  // 21: storage.StorageService.UpdateDataBlock:output_type -> storage.UpdateDataBlockResponse
+	9,  // 22: storage.StorageService.UpdateDataBlocks:output_type -> storage.UpdateDataBlocksResponse
+	10, // 23: storage.StorageService.ListDataBlocks:output_type -> storage.ListDataBlocksResponse
+	13, // 24: storage.StorageService.ListBlockTypes:output_type -> storage.GetBlockTypesResponse
+	16, // 25: storage.StorageService.GetUsage:output_type -> storage.GetUsageResponse
+	18, // 26: storage.StorageService.GetVaultKey:output_type -> storage.GetVaultKeyResponse
+	20, // 27: storage.StorageService.SetVaultKey:output_type -> storage.SetVaultKeyResponse
+	20, // [20:28] is the sub-list for method output_type
+	12, // [12:20] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_storage_storage_proto_init() }
@@ -1079,8 +2462,8 @@ func file_internal_proto_storage_storage_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_storage_storage_proto_rawDesc), len(file_internal_proto_storage_storage_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   8,
+			NumEnums:      2,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	StorageService_SaveDataBlock_FullMethodName  = "/storage.StorageService/SaveDataBlock"
-	StorageService_ListDataBlocks_FullMethodName = "/storage.StorageService/ListDataBlocks"
-	StorageService_ListBlockTypes_FullMethodName = "/storage.StorageService/ListBlockTypes"
+	StorageService_SaveDataBlock_FullMethodName    = "/storage.StorageService/SaveDataBlock"
+	StorageService_UpdateDataBlock_FullMethodName  = "/storage.StorageService/UpdateDataBlock"
+	StorageService_UpdateDataBlocks_FullMethodName = "/storage.StorageService/UpdateDataBlocks"
+	StorageService_ListDataBlocks_FullMethodName   = "/storage.StorageService/ListDataBlocks"
+	StorageService_ListBlockTypes_FullMethodName   = "/storage.StorageService/ListBlockTypes"
+	StorageService_GetUsage_FullMethodName         = "/storage.StorageService/GetUsage"
+	StorageService_GetVaultKey_FullMethodName      = "/storage.StorageService/GetVaultKey"
+	StorageService_SetVaultKey_FullMethodName      = "/storage.StorageService/SetVaultKey"
 )
 
 // StorageServiceClient is the client API for StorageService service.
@@ -32,10 +37,20 @@ const (
 type StorageServiceClient interface {
 	// SaveDataBlock saves a data block with encrypted payload for the user.
 	SaveDataBlock(ctx context.Context, in *SaveDataBlockRequest, opts ...grpc.CallOption) (*SaveDataBlockResponse, error)
+	// UpdateDataBlock re-encrypts a data block of the user, e.g. to upgrade its payload format.
+	UpdateDataBlock(ctx context.Context, in *UpdateDataBlockRequest, opts ...grpc.CallOption) (*UpdateDataBlockResponse, error)
+	// UpdateDataBlocks re-encrypts several data blocks of the user atomically, e.g. to rotate keys.
+	UpdateDataBlocks(ctx context.Context, in *UpdateDataBlocksRequest, opts ...grpc.CallOption) (*UpdateDataBlocksResponse, error)
 	// ListDataBlocks returns a list of data blocks stored for the user with encrypted payload.
 	ListDataBlocks(ctx context.Context, in *ListDataBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListDataBlocksResponse], error)
 	// ListBlockTypes returns a list of available block types.
 	ListBlockTypes(ctx context.Context, in *GetBlockTypesRequest, opts ...grpc.CallOption) (*GetBlockTypesResponse, error)
+	// GetUsage returns storage consumption and quotas of the user.
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
+	// GetVaultKey returns the wrapped vault key of the user, NotFound if it's not set up.
+	GetVaultKey(ctx context.Context, in *GetVaultKeyRequest, opts ...grpc.CallOption) (*GetVaultKeyResponse, error)
+	// SetVaultKey sets up or rewraps the vault key of the user.
+	SetVaultKey(ctx context.Context, in *SetVaultKeyRequest, opts ...grpc.CallOption) (*SetVaultKeyResponse, error)
 }
 
 type storageServiceClient struct {
@@ -56,6 +71,26 @@ func (c *storageServiceClient) SaveDataBlock(ctx context.Context, in *SaveDataBl
 	return out, nil
 }
 
+func (c *storageServiceClient) UpdateDataBlock(ctx context.Context, in *UpdateDataBlockRequest, opts ...grpc.CallOption) (*UpdateDataBlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateDataBlockResponse)
+	err := c.cc.Invoke(ctx, StorageService_UpdateDataBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) UpdateDataBlocks(ctx context.Context, in *UpdateDataBlocksRequest, opts ...grpc.CallOption) (*UpdateDataBlocksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateDataBlocksResponse)
+	err := c.cc.Invoke(ctx, StorageService_UpdateDataBlocks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *storageServiceClient) ListDataBlocks(ctx context.Context, in *ListDataBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListDataBlocksResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[0], StorageService_ListDataBlocks_FullMethodName, cOpts...)
@@ -85,6 +120,36 @@ func (c *storageServiceClient) ListBlockTypes(ctx context.Context, in *GetBlockT
 	return out, nil
 }
 
+func (c *storageServiceClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, StorageService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetVaultKey(ctx context.Context, in *GetVaultKeyRequest, opts ...grpc.CallOption) (*GetVaultKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVaultKeyResponse)
+	err := c.cc.Invoke(ctx, StorageService_GetVaultKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) SetVaultKey(ctx context.Context, in *SetVaultKeyRequest, opts ...grpc.CallOption) (*SetVaultKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetVaultKeyResponse)
+	err := c.cc.Invoke(ctx, StorageService_SetVaultKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StorageServiceServer is the server API for StorageService service.
 // All implementations must embed UnimplementedStorageServiceServer
 // for forward compatibility.
@@ -93,10 +158,20 @@ func (c *storageServiceClient) ListBlockTypes(ctx context.Context, in *GetBlockT
 type StorageServiceServer interface {
 	// SaveDataBlock saves a data block with encrypted payload for the user.
 	SaveDataBlock(context.Context, *SaveDataBlockRequest) (*SaveDataBlockResponse, error)
+	// UpdateDataBlock re-encrypts a data block of the user, e.g. to upgrade its payload format.
+	UpdateDataBlock(context.Context, *UpdateDataBlockRequest) (*UpdateDataBlockResponse, error)
+	// UpdateDataBlocks re-encrypts several data blocks of the user atomically, e.g. to rotate keys.
+	UpdateDataBlocks(context.Context, *UpdateDataBlocksRequest) (*UpdateDataBlocksResponse, error)
 	// ListDataBlocks returns a list of data blocks stored for the user with encrypted payload.
 	ListDataBlocks(*ListDataBlocksRequest, grpc.ServerStreamingServer[ListDataBlocksResponse]) error
 	// ListBlockTypes returns a list of available block types.
 	ListBlockTypes(context.Context, *GetBlockTypesRequest) (*GetBlockTypesResponse, error)
+	// GetUsage returns storage consumption and quotas of the user.
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
+	// GetVaultKey returns the wrapped vault key of the user, NotFound if it's not set up.
+	GetVaultKey(context.Context, *GetVaultKeyRequest) (*GetVaultKeyResponse, error)
+	// SetVaultKey sets up or rewraps the vault key of the user.
+	SetVaultKey(context.Context, *SetVaultKeyRequest) (*SetVaultKeyResponse, error)
 	mustEmbedUnimplementedStorageServiceServer()
 }
 
@@ -110,12 +185,27 @@ type UnimplementedStorageServiceServer struct{}
 func (UnimplementedStorageServiceServer) SaveDataBlock(context.Context, *SaveDataBlockRequest) (*SaveDataBlockResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SaveDataBlock not implemented")
 }
+func (UnimplementedStorageServiceServer) UpdateDataBlock(context.Context, *UpdateDataBlockRequest) (*UpdateDataBlockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateDataBlock not implemented")
+}
+func (UnimplementedStorageServiceServer) UpdateDataBlocks(context.Context, *UpdateDataBlocksRequest) (*UpdateDataBlocksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateDataBlocks not implemented")
+}
 func (UnimplementedStorageServiceServer) ListDataBlocks(*ListDataBlocksRequest, grpc.ServerStreamingServer[ListDataBlocksResponse]) error {
 	return status.Error(codes.Unimplemented, "method ListDataBlocks not implemented")
 }
 func (UnimplementedStorageServiceServer) ListBlockTypes(context.Context, *GetBlockTypesRequest) (*GetBlockTypesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListBlockTypes not implemented")
 }
+func (UnimplementedStorageServiceServer) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedStorageServiceServer) GetVaultKey(context.Context, *GetVaultKeyRequest) (*GetVaultKeyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetVaultKey not implemented")
+}
+func (UnimplementedStorageServiceServer) SetVaultKey(context.Context, *SetVaultKeyRequest) (*SetVaultKeyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetVaultKey not implemented")
+}
 func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
 func (UnimplementedStorageServiceServer) testEmbeddedByValue()                        {}
 
@@ -155,6 +245,42 @@ func _StorageService_SaveDataBlock_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_UpdateDataBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDataBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).UpdateDataBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_UpdateDataBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).UpdateDataBlock(ctx, req.(*UpdateDataBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_UpdateDataBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDataBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).UpdateDataBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_UpdateDataBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).UpdateDataBlocks(ctx, req.(*UpdateDataBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StorageService_ListDataBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ListDataBlocksRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -184,6 +310,60 @@ func _StorageService_ListBlockTypes_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVaultKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetVaultKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetVaultKey(ctx, req.(*GetVaultKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_SetVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetVaultKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).SetVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_SetVaultKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).SetVaultKey(ctx, req.(*SetVaultKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -195,10 +375,30 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SaveDataBlock",
 			Handler:    _StorageService_SaveDataBlock_Handler,
 		},
+		{
+			MethodName: "UpdateDataBlock",
+			Handler:    _StorageService_UpdateDataBlock_Handler,
+		},
+		{
+			MethodName: "UpdateDataBlocks",
+			Handler:    _StorageService_UpdateDataBlocks_Handler,
+		},
 		{
 			MethodName: "ListBlockTypes",
 			Handler:    _StorageService_ListBlockTypes_Handler,
 		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _StorageService_GetUsage_Handler,
+		},
+		{
+			MethodName: "GetVaultKey",
+			Handler:    _StorageService_GetVaultKey_Handler,
+		},
+		{
+			MethodName: "SetVaultKey",
+			Handler:    _StorageService_SetVaultKey_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
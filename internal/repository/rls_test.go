@@ -0,0 +1,148 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/pkg/pgtest"
+	"github.com/jackc/pgx/v5"
+)
+
+// TestRowLevelSecurity checks that blocks and vault keys of a user aren't
+// reachable by queries missing the user filter, from transactions bound to
+// another user or to no user at all.
+func TestRowLevelSecurity(t *testing.T) {
+	db := pgtest.Pgx(t)
+	ctx := context.Background()
+
+	bypass, err := pgtest.BypassesRLS(ctx, db)
+	if err != nil {
+		t.Fatalf("read role attributes: %v", err)
+	}
+	if bypass {
+		t.Fatal("role bypasses row-level security (superuser or BYPASSRLS), use an ordinary role")
+	}
+
+	for _, table := range []string{"blocks", "vault_keys"} {
+		var enabled, forced bool
+		err := db.Pool.QueryRow(
+			ctx,
+			`SELECT relrowsecurity, relforcerowsecurity FROM pg_class WHERE oid = $1::regclass;`,
+			table,
+		).Scan(&enabled, &forced)
+		if err != nil {
+			t.Fatalf("read %s attributes: %v", table, err)
+		}
+		if !enabled || !forced {
+			t.Errorf("row-level security of %s: enabled %t, forced %t", table, enabled, forced)
+		}
+	}
+
+	users := repository.NewUserRepository(db)
+	blocks := repository.NewStorageRepository(db)
+	owner := createRLSUser(t, db, users, "owner")
+	other := createRLSUser(t, db, users, "other")
+
+	block := newBenchBlocks(owner.ID, 1)[0]
+	if _, err := blocks.CreateBlock(ctx, block, 0, 0); err != nil {
+		t.Fatalf("create block: %v", err)
+	}
+	if err := blocks.WriteVaultKey(ctx, owner.ID, []byte("wrapped"), nil); err != nil {
+		t.Fatalf("write vault key: %v", err)
+	}
+
+	count := func(t *testing.T, bindTo int, query string) int {
+		t.Helper()
+
+		var n int
+		var err error
+		scan := func(row pgx.Row) error { return row.Scan(&n) }
+		if bindTo == 0 {
+			err = scan(db.Pool.QueryRow(ctx, query, owner.ID))
+		} else {
+			err = db.WithUser(ctx, bindTo, func(tx pgx.Tx) error {
+				return scan(tx.QueryRow(ctx, query, owner.ID))
+			})
+		}
+		if err != nil {
+			t.Fatalf("count rows: %v", err)
+		}
+
+		return n
+	}
+
+	const (
+		countBlocks    = `SELECT count(*) FROM blocks WHERE user_id = $1;`
+		countVaultKeys = `SELECT count(*) FROM vault_keys WHERE user_id = $1;`
+	)
+	tests := []struct {
+		name   string
+		bindTo int
+		query  string
+		want   int
+	}{
+		{"owner reads own block", owner.ID, countBlocks, 1},
+		{"owner reads own vault key", owner.ID, countVaultKeys, 1},
+		{"other user reads no blocks", other.ID, countBlocks, 0},
+		{"other user reads no vault key", other.ID, countVaultKeys, 0},
+		{"unbound query reads no blocks", 0, countBlocks, 0},
+		{"unbound query reads no vault key", 0, countVaultKeys, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := count(t, tt.bindTo, tt.query); got != tt.want {
+				t.Errorf("got %d rows, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("other user can't insert owner's block", func(t *testing.T) {
+		err := db.WithUser(ctx, other.ID, func(tx pgx.Tx) error {
+			_, err := tx.Exec(
+				ctx,
+				`INSERT INTO blocks (uid, user_id, type_id, title, data, salt, nonce, profile)
+				VALUES (gen_random_uuid()::text, $1, 1, 'rls', '', '', '', 'default');`,
+				owner.ID,
+			)
+			return err
+		})
+		if err == nil {
+			t.Error("insert succeeded")
+		}
+	})
+}
+
+// createRLSUser creates a user removed with its rows on test cleanup.
+func createRLSUser(t *testing.T, db *database.PgxDriver, users ports.UserRepositoryWriter, role string) *model.User {
+	t.Helper()
+
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, &model.User{
+		Username:     fmt.Sprintf("rls-%s-%d", role, time.Now().UnixNano()),
+		PasswordHash: "-",
+	})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := db.WithUser(ctx, user.ID, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `DELETE FROM blocks WHERE user_id = $1;`, user.ID); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1;`, user.ID)
+			return err
+		})
+		if err != nil {
+			t.Errorf("delete user %d: %v", user.ID, err)
+		}
+	})
+
+	return user
+}
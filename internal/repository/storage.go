@@ -1,27 +1,54 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+	"github.com/jackc/pgx/v5"
 )
 
 var _ ports.StorageRepository = (*storageRepository)(nil)
 
 type storageRepository struct {
-	db *database.SQLDriver
+	db *database.PgxDriver
 }
 
-func NewStorageRepository(db *database.SQLDriver) *storageRepository {
+func NewStorageRepository(db *database.PgxDriver) *storageRepository {
 	return &storageRepository{
 		db: db,
 	}
 }
 
-func (r *storageRepository) CreateBlock(data *model.Block) (*model.Block, error) {
+// usageSQL counts blocks and payload bytes of the user.
+const usageSQL = `
+	SELECT
+		count(*), COALESCE(sum(octet_length(data)), 0)
+	FROM blocks
+	WHERE
+		user_id = $1;`
+
+// CreateBlock inserts the block if it fits into maxBlocks and maxBytes of
+// the user. The user row is locked before usage is counted, so concurrent
+// inserts of the user wait for each other instead of all passing the check.
+func (r *storageRepository) CreateBlock(ctx context.Context, data *model.Block, maxBlocks, maxBytes int) (_ *model.Block, err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.CreateBlock",
+		"INSERT",
+		"blocks",
+		tracing.UserID(data.UserID),
+		tracing.BlockTypeID(data.TypeID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `
 		INSERT INTO
 			blocks (
@@ -31,93 +58,312 @@ func (r *storageRepository) CreateBlock(data *model.Block) (*model.Block, error)
 				data,
 				salt,
 				nonce,
-				profile
+				profile,
+				cipher,
+				uid,
+				format
 			)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id;
 	`
-	err := r.db.Conn.QueryRow(
-		sqlText,
-		data.UserID,
-		data.TypeID,
-		data.Title,
-		data.Data,
-		data.Salt,
-		data.Nonce,
-		data.Profile,
-	).Scan(&data.ID)
+	err = r.db.WithUser(ctx, data.UserID, func(tx pgx.Tx) error {
+		if maxBlocks > 0 || maxBytes > 0 {
+			if _, err := tx.Exec(ctx, `SELECT id FROM users WHERE id = $1 FOR NO KEY UPDATE;`, data.UserID); err != nil {
+				return err
+			}
+
+			var usage model.Usage
+			if err := tx.QueryRow(ctx, usageSQL, data.UserID).Scan(&usage.Blocks, &usage.Bytes); err != nil {
+				return err
+			}
+			if err := quotaError(&usage, len(data.Data), maxBlocks, maxBytes); err != nil {
+				return err
+			}
+		}
+
+		return tx.QueryRow(
+			ctx,
+			sqlText,
+			data.UserID,
+			data.TypeID,
+			data.Title,
+			data.Data,
+			data.Salt,
+			data.Nonce,
+			data.Profile,
+			data.Cipher,
+			data.UID,
+			data.Format,
+		).Scan(&data.ID)
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.DBErrorNoRows
 		}
 
 		return nil, err
 	}
 
+	span.SetAttributes(tracing.BlockID(data.ID))
+
 	return data, nil
 }
 
-func (r *storageRepository) ReadUserBlocks(userID int) ([]*model.Block, error) {
+// quotaError returns DBErrorQuotaBlocks or DBErrorQuotaBytes if a block of
+// size bytes doesn't fit into limits of the user with usage, zero limits
+// are unlimited.
+func quotaError(usage *model.Usage, size, maxBlocks, maxBytes int) error {
+	if maxBlocks > 0 && usage.Blocks >= maxBlocks {
+		return apperror.DBErrorQuotaBlocks
+	}
+	if maxBytes > 0 && usage.Bytes+size > maxBytes {
+		return apperror.DBErrorQuotaBytes
+	}
+
+	return nil
+}
+
+// CreateBlocks inserts blocks with a COPY statement per user in a single
+// transaction, it's much faster than separate INSERTs but doesn't return
+// IDs of created blocks.
+func (r *storageRepository) CreateBlocks(ctx context.Context, blocks []*model.Block) (_ int64, err error) {
+	ctx, span := startQuerySpan(ctx, "storageRepository.CreateBlocks", "COPY", "blocks")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var userIDs []int
+	byUser := make(map[int][]*model.Block)
+	for _, b := range blocks {
+		if _, ok := byUser[b.UserID]; !ok {
+			userIDs = append(userIDs, b.UserID)
+		}
+		byUser[b.UserID] = append(byUser[b.UserID], b)
+	}
+
+	var count int64
+	err = pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		for _, userID := range userIDs {
+			if err := database.SetCurrentUser(ctx, tx, userID); err != nil {
+				return err
+			}
+
+			userBlocks := byUser[userID]
+			n, err := tx.CopyFrom(
+				ctx,
+				pgx.Identifier{"blocks"},
+				[]string{"user_id", "type_id", "title", "data", "salt", "nonce", "profile", "cipher", "uid", "format"},
+				pgx.CopyFromSlice(len(userBlocks), func(i int) ([]any, error) {
+					b := userBlocks[i]
+					return []any{
+						b.UserID, b.TypeID, b.Title, b.Data, b.Salt, b.Nonce, b.Profile, b.Cipher, b.UID, b.Format,
+					}, nil
+				}),
+			)
+			if err != nil {
+				return err
+			}
+
+			count += n
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateBlockPayloads replaces encrypted payloads of user blocks in a
+// single transaction, DBErrorNoRows is returned and nothing is updated if
+// the user has no such block.
+func (r *storageRepository) UpdateBlockPayloads(ctx context.Context, userID int, blocks []*model.Block) (err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.UpdateBlockPayloads",
+		"UPDATE",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		UPDATE blocks
+		SET
+			data = $3,
+			salt = $4,
+			nonce = $5,
+			profile = $6,
+			cipher = $7,
+			format = $8,
+			updated_at = NOW()
+		WHERE
+			id = $1 AND user_id = $2;`
+
+	batch := &pgx.Batch{}
+	for _, b := range blocks {
+		batch.Queue(sqlText, b.ID, userID, b.Data, b.Salt, b.Nonce, b.Profile, b.Cipher, b.Format)
+	}
+
+	return r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for range blocks {
+			tag, err := results.Exec()
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return apperror.DBErrorNoRows
+			}
+		}
+
+		return results.Close()
+	})
+}
+
+func (r *storageRepository) ReadUserBlocks(ctx context.Context, userID int) (_ []*model.Block, err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.ReadUserBlocks",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `
 		SELECT
-			b.id, b.user_id, b.type_id, b.title, b.data, b.profile, b.salt, b.nonce,
+			b.id, b.uid, b.user_id, b.type_id, b.title, b.data, b.profile, b.cipher, b.format, b.salt, b.nonce,
 			t.id, t.type_name, t.description
 		FROM blocks b
 		INNER JOIN block_types t ON b.type_id = t.id
 		WHERE
 			user_id = $1;`
 
-	rows, err := r.db.Conn.Query(sqlText, userID)
+	var blocks []*model.Block
+	err = r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlText, userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var block model.Block
+			var t model.Type
+			err := rows.Scan(
+				&block.ID,
+				&block.UID,
+				&block.UserID,
+				&block.TypeID,
+				&block.Title,
+				&block.Data,
+				&block.Profile,
+				&block.Cipher,
+				&block.Format,
+				&block.Salt,
+				&block.Nonce,
+				&t.ID,
+				&t.TypeName,
+				&t.Description,
+			)
+
+			block.Type = &t
+			if err != nil {
+				return err
+			}
+
+			blocks = append(blocks, &block)
+		}
+
+		return rows.Err()
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.DBErrorNoRows
 		}
 
 		return nil, err
 	}
-	defer rows.Close()
 
-	var blocks []*model.Block
-	for rows.Next() {
-		var block model.Block
-		var t model.Type
-		err := rows.Scan(
-			&block.ID,
-			&block.UserID,
-			&block.TypeID,
-			&block.Title,
-			&block.Data,
-			&block.Profile,
-			&block.Salt,
-			&block.Nonce,
-			&t.ID,
-			&t.TypeName,
-			&t.Description,
-		)
+	return blocks, nil
+}
+
+// ReadBlockSizes returns payload sizes and type names of user blocks with
+// the given IDs, payloads aren't read.
+func (r *storageRepository) ReadBlockSizes(ctx context.Context, userID int, ids []int) (_ []*model.BlockSize, err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.ReadBlockSizes",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		SELECT
+			b.id, t.type_name, octet_length(b.data)
+		FROM blocks b
+		INNER JOIN block_types t ON b.type_id = t.id
+		WHERE
+			b.user_id = $1 AND b.id = ANY($2);`
 
-		block.Type = &t
+	var sizes []*model.BlockSize
+	err = r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlText, userID, ids)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		defer rows.Close()
 
-		blocks = append(blocks, &block)
-	}
-	if err = rows.Err(); err != nil {
+		for rows.Next() {
+			var size model.BlockSize
+			if err := rows.Scan(&size.ID, &size.TypeName, &size.Size); err != nil {
+				return err
+			}
+
+			sizes = append(sizes, &size)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return blocks, nil
+	return sizes, nil
 }
 
-func (r *storageRepository) ReadBlockTypes() ([]*model.Type, error) {
+func (r *storageRepository) ReadBlockTypes(ctx context.Context) (_ []*model.Type, err error) {
+	ctx, span := startQuerySpan(ctx, "storageRepository.ReadBlockTypes", "SELECT", "block_types")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `
 		SELECT
 			id, type_name, description
 		FROM block_types;`
 
-	rows, err := r.db.Conn.Query(sqlText)
+	rows, err := r.db.Pool.Query(ctx, sqlText)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.DBErrorNoRows
 		}
 
@@ -145,3 +391,108 @@ func (r *storageRepository) ReadBlockTypes() ([]*model.Type, error) {
 
 	return types, nil
 }
+
+func (r *storageRepository) ReadUserUsage(ctx context.Context, userID int) (_ *model.Usage, err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.ReadUserUsage",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var usage model.Usage
+	err = r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, usageSQL, userID).Scan(&usage.Blocks, &usage.Bytes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// ReadVaultKey returns wrapped vault key of the user, DBErrorNoRows is
+// returned if the user has none yet.
+func (r *storageRepository) ReadVaultKey(ctx context.Context, userID int) (_ []byte, err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.ReadVaultKey",
+		"SELECT",
+		"vault_keys",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		SELECT
+			wrapped_key
+		FROM vault_keys
+		WHERE
+			user_id = $1;`
+
+	var wrapped []byte
+	err = r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, sqlText, userID).Scan(&wrapped)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.DBErrorNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapped, nil
+}
+
+// WriteVaultKey stores wrapped vault key of the user if the stored one is
+// still expected, nil expected creates the first key. DBErrorNoRows is
+// returned if the stored key has changed meanwhile.
+func (r *storageRepository) WriteVaultKey(ctx context.Context, userID int, wrapped, expected []byte) (err error) {
+	ctx, span := startQuerySpan(
+		ctx,
+		"storageRepository.WriteVaultKey",
+		"UPSERT",
+		"vault_keys",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		UPDATE vault_keys
+		SET
+			wrapped_key = $2,
+			updated_at = NOW()
+		WHERE
+			user_id = $1 AND wrapped_key = $3;`
+	args := []any{userID, wrapped, expected}
+	if expected == nil {
+		sqlText = `
+			INSERT INTO vault_keys (user_id, wrapped_key)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id) DO NOTHING;`
+		args = args[:2]
+	}
+
+	return r.db.WithUser(ctx, userID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, sqlText, args...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return apperror.DBErrorNoRows
+		}
+
+		return nil
+	})
+}
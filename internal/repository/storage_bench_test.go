@@ -0,0 +1,240 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/pkg/pgtest"
+	"github.com/google/uuid"
+)
+
+// benchBlocks is the number of blocks seeded for the benchmark user and
+// inserted per iteration of insert benchmarks.
+const benchBlocks = 50
+
+// benchEnv compares hot path queries of the database/sql lib/pq driver
+// used before with the pgx pool used by repositories.
+type benchEnv struct {
+	sql   *sql.DB
+	users interface {
+		ports.UserRepositoryReader
+		ports.UserRepositoryWriter
+	}
+	blocks ports.StorageRepository
+	user   *model.User
+}
+
+func newBenchEnv(b *testing.B) *benchEnv {
+	b.Helper()
+
+	conf, password := pgtest.Config(b)
+	sqlDriver, err := database.NewSQLDriver(conf, password)
+	if err != nil {
+		b.Fatalf("connect with lib/pq: %v", err)
+	}
+	b.Cleanup(func() { sqlDriver.Conn.Close() })
+
+	pgxDriver, err := database.NewPgxDriver(conf, password)
+	if err != nil {
+		b.Fatalf("connect with pgx: %v", err)
+	}
+	b.Cleanup(pgxDriver.Close)
+
+	ctx := context.Background()
+	env := &benchEnv{
+		sql:    sqlDriver.Conn,
+		users:  repository.NewUserRepository(pgxDriver),
+		blocks: repository.NewStorageRepository(pgxDriver),
+	}
+	env.user, err = env.users.CreateUser(ctx, &model.User{
+		Username:     fmt.Sprintf("bench-%d", time.Now().UnixNano()),
+		PasswordHash: "-",
+	})
+	if err != nil {
+		b.Fatalf("create benchmark user: %v", err)
+	}
+	b.Cleanup(func() { env.cleanup(b) })
+
+	if _, err := env.blocks.CreateBlocks(ctx, newBenchBlocks(env.user.ID, benchBlocks)); err != nil {
+		b.Fatalf("seed blocks: %v", err)
+	}
+
+	return env
+}
+
+func BenchmarkReadUserByUsername(b *testing.B) {
+	env := newBenchEnv(b)
+	ctx := context.Background()
+
+	b.Run("libpq", func(b *testing.B) {
+		for b.Loop() {
+			var u model.User
+			err := env.sql.QueryRowContext(
+				ctx,
+				`SELECT id, username, password_hash, created_at FROM users WHERE username = $1;`,
+				env.user.Username,
+			).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pgx", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := env.users.ReadUserByUsername(ctx, env.user.Username); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkReadUserBlocks(b *testing.B) {
+	env := newBenchEnv(b)
+	ctx := context.Background()
+
+	b.Run("libpq", func(b *testing.B) {
+		for b.Loop() {
+			if err := readBlocksSQL(ctx, env.sql, env.user.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pgx", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := env.blocks.ReadUserBlocks(ctx, env.user.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkInsertBlocks(b *testing.B) {
+	env := newBenchEnv(b)
+	ctx := context.Background()
+
+	b.Run("insert", func(b *testing.B) {
+		for b.Loop() {
+			for _, block := range newBenchBlocks(env.user.ID, benchBlocks) {
+				if _, err := env.blocks.CreateBlock(ctx, block, 0, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("copy", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := env.blocks.CreateBlocks(ctx, newBenchBlocks(env.user.ID, benchBlocks)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func newBenchBlocks(userID, n int) []*model.Block {
+	blocks := make([]*model.Block, n)
+	for i := range blocks {
+		blocks[i] = &model.Block{
+			UID:     uuid.NewString(),
+			UserID:  userID,
+			TypeID:  1,
+			Title:   fmt.Sprintf("block %d", i),
+			Data:    make([]byte, 1024),
+			Salt:    []byte("salt"),
+			Nonce:   []byte("nonce"),
+			Profile: "default",
+			Cipher:  string(utils.CipherAES256GCM),
+		}
+	}
+
+	return blocks
+}
+
+// readBlocksSQL reads blocks the way the repository does, in a transaction
+// bound to the user for row-level security.
+func readBlocksSQL(ctx context.Context, db *sql.DB, userID int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setCurrentUserSQL(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			b.id, b.user_id, b.type_id, b.title, b.data, b.profile, b.salt, b.nonce,
+			t.id, t.type_name, t.description
+		FROM blocks b
+		INNER JOIN block_types t ON b.type_id = t.id
+		WHERE
+			user_id = $1;`, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var block model.Block
+		var t model.Type
+		err := rows.Scan(
+			&block.ID, &block.UserID, &block.TypeID, &block.Title, &block.Data,
+			&block.Profile, &block.Salt, &block.Nonce, &t.ID, &t.TypeName, &t.Description,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func setCurrentUserSQL(ctx context.Context, tx *sql.Tx, userID int) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`SELECT set_config($1, $2, true);`,
+		database.CurrentUserSetting,
+		strconv.Itoa(userID),
+	)
+
+	return err
+}
+
+func (env *benchEnv) cleanup(b *testing.B) {
+	ctx := context.Background()
+	tx, err := env.sql.BeginTx(ctx, nil)
+	if err != nil {
+		b.Errorf("delete benchmark blocks: %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := setCurrentUserSQL(ctx, tx, env.user.ID); err != nil {
+		b.Errorf("delete benchmark blocks: %v", err)
+		return
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blocks WHERE user_id = $1;`, env.user.ID); err != nil {
+		b.Errorf("delete benchmark blocks: %v", err)
+		return
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1;`, env.user.ID); err != nil {
+		b.Errorf("delete benchmark user: %v", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		b.Errorf("delete benchmark user: %v", err)
+	}
+}
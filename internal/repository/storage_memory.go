@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+)
+
+var _ ports.StorageRepository = (*memoryStorageRepository)(nil)
+
+// memoryStorageRepository keeps blocks in process memory, it's meant
+// for tests and throwaway servers. Stored and returned blocks are copies,
+// so callers can't change repository state by mutating them.
+type memoryStorageRepository struct {
+	mu     sync.RWMutex
+	lastID int
+	blocks map[int][]*model.Block
+	types  []*model.Type
+	vault  map[int][]byte
+}
+
+func NewMemoryStorageRepository() *memoryStorageRepository {
+	return &memoryStorageRepository{
+		blocks: make(map[int][]*model.Block),
+		vault:  make(map[int][]byte),
+		// same as seeded by migrations
+		types: []*model.Type{
+			{ID: 1, TypeName: string(model.TypeNameText), Description: "Raw text data block"},
+			{ID: 2, TypeName: string(model.TypeNameFile), Description: "File data block"},
+			{ID: 3, TypeName: string(model.TypeNameCredentials), Description: "Credentials data block"},
+			{ID: 4, TypeName: string(model.TypeNameCard), Description: "Bank card data block"},
+		},
+	}
+}
+
+func (r *memoryStorageRepository) CreateBlock(_ context.Context, data *model.Block, maxBlocks, maxBytes int) (*model.Block, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := quotaError(r.usage(data.UserID), len(data.Data), maxBlocks, maxBytes); err != nil {
+		return nil, err
+	}
+	if err := r.insert(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *memoryStorageRepository) CreateBlocks(_ context.Context, blocks []*model.Block) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range blocks {
+		if _, err := r.blockType(b.TypeID); err != nil {
+			return 0, err
+		}
+	}
+	for _, b := range blocks {
+		if err := r.insert(b); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(blocks)), nil
+}
+
+// insert stores a copy of the block and sets its ID, mu must be held.
+func (r *memoryStorageRepository) insert(data *model.Block) error {
+	t, err := r.blockType(data.TypeID)
+	if err != nil {
+		return err
+	}
+
+	r.lastID++
+	data.ID = r.lastID
+	stored := cloneBlock(data)
+	stored.Type = t
+	r.blocks[data.UserID] = append(r.blocks[data.UserID], stored)
+
+	return nil
+}
+
+func (r *memoryStorageRepository) blockType(id int) (*model.Type, error) {
+	i := slices.IndexFunc(r.types, func(t *model.Type) bool { return t.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("block type %d does not exist", id)
+	}
+
+	return r.types[i], nil
+}
+
+func (r *memoryStorageRepository) UpdateBlockPayloads(_ context.Context, userID int, blocks []*model.Block) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]*model.Block, len(blocks))
+	for i, data := range blocks {
+		j := slices.IndexFunc(r.blocks[userID], func(b *model.Block) bool { return b.ID == data.ID })
+		if j < 0 {
+			return apperror.DBErrorNoRows
+		}
+		stored[i] = r.blocks[userID][j]
+	}
+
+	for i, data := range blocks {
+		stored[i].Data = slices.Clone(data.Data)
+		stored[i].Salt = slices.Clone(data.Salt)
+		stored[i].Nonce = slices.Clone(data.Nonce)
+		stored[i].Profile = data.Profile
+		stored[i].Cipher = data.Cipher
+		stored[i].Format = data.Format
+	}
+
+	return nil
+}
+
+func (r *memoryStorageRepository) ReadUserBlocks(_ context.Context, userID int) ([]*model.Block, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var blocks []*model.Block
+	for _, b := range r.blocks[userID] {
+		blocks = append(blocks, cloneBlock(b))
+	}
+
+	return blocks, nil
+}
+
+func (r *memoryStorageRepository) ReadBlockSizes(_ context.Context, userID int, ids []int) ([]*model.BlockSize, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sizes []*model.BlockSize
+	for _, b := range r.blocks[userID] {
+		if slices.Contains(ids, b.ID) {
+			sizes = append(sizes, &model.BlockSize{ID: b.ID, TypeName: b.Type.TypeName, Size: len(b.Data)})
+		}
+	}
+
+	return sizes, nil
+}
+
+func (r *memoryStorageRepository) ReadBlockTypes(_ context.Context) ([]*model.Type, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]*model.Type, 0, len(r.types))
+	for _, t := range r.types {
+		copied := *t
+		types = append(types, &copied)
+	}
+
+	return types, nil
+}
+
+func (r *memoryStorageRepository) ReadUserUsage(_ context.Context, userID int) (*model.Usage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.usage(userID), nil
+}
+
+// usage counts blocks and payload bytes of the user, mu must be held.
+func (r *memoryStorageRepository) usage(userID int) *model.Usage {
+	usage := &model.Usage{Blocks: len(r.blocks[userID])}
+	for _, b := range r.blocks[userID] {
+		usage.Bytes += len(b.Data)
+	}
+
+	return usage
+}
+
+func cloneBlock(b *model.Block) *model.Block {
+	copied := *b
+	copied.Data = slices.Clone(b.Data)
+	copied.Salt = slices.Clone(b.Salt)
+	copied.Nonce = slices.Clone(b.Nonce)
+	if b.Type != nil {
+		t := *b.Type
+		copied.Type = &t
+	}
+
+	return &copied
+}
+
+func (r *memoryStorageRepository) ReadVaultKey(_ context.Context, userID int) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wrapped, ok := r.vault[userID]
+	if !ok {
+		return nil, apperror.DBErrorNoRows
+	}
+
+	return slices.Clone(wrapped), nil
+}
+
+func (r *memoryStorageRepository) WriteVaultKey(_ context.Context, userID int, wrapped, expected []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vault[userID]
+	if ok != (expected != nil) || !bytes.Equal(stored, expected) {
+		return apperror.DBErrorNoRows
+	}
+	r.vault[userID] = slices.Clone(wrapped)
+
+	return nil
+}
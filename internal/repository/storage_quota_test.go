@@ -0,0 +1,170 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/pkg/pgtest"
+)
+
+// storageEngines create a storage repository of every engine and a user of
+// it, each call starts with a user without blocks.
+var storageEngines = []struct {
+	name  string
+	setup func(t *testing.T) (ports.StorageRepository, int)
+}{
+	{"memory", func(t *testing.T) (ports.StorageRepository, int) {
+		user, err := repository.NewMemoryUserRepository().CreateUser(context.Background(), &model.User{
+			Username:     "quota",
+			PasswordHash: "-",
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+
+		return repository.NewMemoryStorageRepository(), user.ID
+	}},
+	{"sqlite", func(t *testing.T) (ports.StorageRepository, int) {
+		conf, err := config.Defaults()
+		if err != nil {
+			t.Fatalf("config defaults: %v", err)
+		}
+		conf.Database.Engine = config.DatabaseEngineSQLite
+		conf.Database.Path = filepath.Join(t.TempDir(), "gophkeeper.db")
+
+		db, err := database.NewSQLiteDriver(&conf.Database)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { db.Conn.Close() })
+
+		migrator, err := database.NewMigrator(config.DatabaseEngineSQLite, db.Conn)
+		if err != nil {
+			t.Fatalf("create migrator: %v", err)
+		}
+		if err := migrator.Up(); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+
+		user, err := repository.NewSQLiteUserRepository(db).CreateUser(context.Background(), &model.User{
+			Username:     "quota",
+			PasswordHash: "-",
+		})
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+
+		return repository.NewSQLiteStorageRepository(db), user.ID
+	}},
+	{"postgres", func(t *testing.T) (ports.StorageRepository, int) {
+		db := pgtest.Pgx(t)
+		user := createRLSUser(t, db, repository.NewUserRepository(db), "quota")
+
+		return repository.NewStorageRepository(db), user.ID
+	}},
+}
+
+// TestCreateBlockQuota saves more blocks than allowed at once, the quota
+// must hold however inserts interleave.
+func TestCreateBlockQuota(t *testing.T) {
+	const (
+		saves = 50
+		limit = 5
+		// size of newBenchBlocks payloads
+		blockSize = 1024
+	)
+
+	tests := []struct {
+		name      string
+		maxBlocks int
+		maxBytes  int
+		wantErr   error
+	}{
+		{"blocks", limit, 0, apperror.DBErrorQuotaBlocks},
+		{"bytes", 0, limit * blockSize, apperror.DBErrorQuotaBytes},
+	}
+	for _, engine := range storageEngines {
+		for _, tt := range tests {
+			t.Run(engine.name+"/"+tt.name, func(t *testing.T) {
+				repo, userID := engine.setup(t)
+				ctx := context.Background()
+
+				var wg sync.WaitGroup
+				errs := make([]error, saves)
+				for i, block := range newBenchBlocks(userID, saves) {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, errs[i] = repo.CreateBlock(ctx, block, tt.maxBlocks, tt.maxBytes)
+					}()
+				}
+				wg.Wait()
+
+				created := 0
+				for _, err := range errs {
+					switch {
+					case err == nil:
+						created++
+					case !errors.Is(err, tt.wantErr):
+						t.Errorf("CreateBlock: got %v, want %v", err, tt.wantErr)
+					}
+				}
+				if created != limit {
+					t.Errorf("created %d blocks, want %d", created, limit)
+				}
+
+				usage, err := repo.ReadUserUsage(ctx, userID)
+				if err != nil {
+					t.Fatalf("ReadUserUsage: %v", err)
+				}
+				if usage.Blocks != limit {
+					t.Errorf("user has %d blocks, want %d", usage.Blocks, limit)
+				}
+			})
+		}
+	}
+}
+
+func TestReadBlockSizes(t *testing.T) {
+	for _, engine := range storageEngines {
+		t.Run(engine.name, func(t *testing.T) {
+			repo, userID := engine.setup(t)
+			ctx := context.Background()
+
+			var ids []int
+			for i, block := range newBenchBlocks(userID, 3) {
+				block.Data = block.Data[:100*(i+1)]
+				created, err := repo.CreateBlock(ctx, block, 0, 0)
+				if err != nil {
+					t.Fatalf("CreateBlock: %v", err)
+				}
+				ids = append(ids, created.ID)
+			}
+
+			// the first block isn't asked for, the last ID doesn't exist
+			sizes, err := repo.ReadBlockSizes(ctx, userID, []int{ids[1], ids[2], ids[2] + 1000})
+			if err != nil {
+				t.Fatalf("ReadBlockSizes: %v", err)
+			}
+			got := make(map[int]int)
+			for _, s := range sizes {
+				if s.TypeName != string(model.TypeNameText) {
+					t.Errorf("block %d type is %q, want %q", s.ID, s.TypeName, model.TypeNameText)
+				}
+				got[s.ID] = s.Size
+			}
+			if len(got) != 2 || got[ids[1]] != 200 || got[ids[2]] != 300 {
+				t.Errorf("sizes are %v, want %d: 200, %d: 300", got, ids[1], ids[2])
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+)
+
+var _ ports.StorageRepository = (*sqliteStorageRepository)(nil)
+
+type sqliteStorageRepository struct {
+	db *database.SQLiteDriver
+}
+
+func NewSQLiteStorageRepository(db *database.SQLiteDriver) *sqliteStorageRepository {
+	return &sqliteStorageRepository{
+		db: db,
+	}
+}
+
+const sqliteInsertBlock = `
+	INSERT INTO
+		blocks (
+			user_id,
+			type_id,
+			title,
+			data,
+			salt,
+			nonce,
+			profile,
+			cipher,
+			uid,
+			format
+		)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id;`
+
+const sqliteUsage = `
+	SELECT
+		count(*), COALESCE(sum(length(data)), 0)
+	FROM blocks
+	WHERE
+		user_id = ?;`
+
+// CreateBlock inserts the block if it fits into maxBlocks and maxBytes of
+// the user, usage is counted in the insert's transaction. Transactions
+// take the write lock when they begin, so concurrent inserts wait for it.
+func (r *sqliteStorageRepository) CreateBlock(ctx context.Context, data *model.Block, maxBlocks, maxBytes int) (_ *model.Block, err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.CreateBlock",
+		"INSERT",
+		"blocks",
+		tracing.UserID(data.UserID),
+		tracing.BlockTypeID(data.TypeID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if maxBlocks > 0 || maxBytes > 0 {
+		var usage model.Usage
+		if err = tx.QueryRowContext(ctx, sqliteUsage, data.UserID).Scan(&usage.Blocks, &usage.Bytes); err != nil {
+			return nil, err
+		}
+		if err = quotaError(&usage, len(data.Data), maxBlocks, maxBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		sqliteInsertBlock,
+		data.UserID,
+		data.TypeID,
+		data.Title,
+		data.Data,
+		data.Salt,
+		data.Nonce,
+		data.Profile,
+		data.Cipher,
+		data.UID,
+		data.Format,
+	).Scan(&data.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.DBErrorNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(tracing.BlockID(data.ID))
+
+	return data, nil
+}
+
+// CreateBlocks inserts blocks in a single transaction with a prepared statement,
+// SQLite has no COPY and a transaction avoids per-row fsync.
+func (r *sqliteStorageRepository) CreateBlocks(ctx context.Context, blocks []*model.Block) (_ int64, err error) {
+	ctx, span := startSQLiteQuerySpan(ctx, "sqliteStorageRepository.CreateBlocks", "INSERT", "blocks")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, sqliteInsertBlock)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, b := range blocks {
+		var id int
+		err = stmt.QueryRowContext(
+			ctx, b.UserID, b.TypeID, b.Title, b.Data, b.Salt, b.Nonce, b.Profile, b.Cipher, b.UID, b.Format,
+		).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(blocks)), nil
+}
+
+func (r *sqliteStorageRepository) UpdateBlockPayloads(ctx context.Context, userID int, blocks []*model.Block) (err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.UpdateBlockPayloads",
+		"UPDATE",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		UPDATE blocks
+		SET
+			data = ?,
+			salt = ?,
+			nonce = ?,
+			profile = ?,
+			cipher = ?,
+			format = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE
+			id = ? AND user_id = ?;`
+
+	tx, err := r.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, sqlText)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, b := range blocks {
+		res, err := stmt.ExecContext(ctx, b.Data, b.Salt, b.Nonce, b.Profile, b.Cipher, b.Format, b.ID, userID)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return apperror.DBErrorNoRows
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqliteStorageRepository) ReadUserBlocks(ctx context.Context, userID int) (_ []*model.Block, err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.ReadUserBlocks",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		SELECT
+			b.id, b.uid, b.user_id, b.type_id, b.title, b.data, b.profile, b.cipher, b.format, b.salt, b.nonce,
+			t.id, t.type_name, t.description
+		FROM blocks b
+		INNER JOIN block_types t ON b.type_id = t.id
+		WHERE
+			user_id = ?;`
+
+	rows, err := r.db.Conn.QueryContext(ctx, sqlText, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*model.Block
+	for rows.Next() {
+		var block model.Block
+		var t model.Type
+		err := rows.Scan(
+			&block.ID,
+			&block.UID,
+			&block.UserID,
+			&block.TypeID,
+			&block.Title,
+			&block.Data,
+			&block.Profile,
+			&block.Cipher,
+			&block.Format,
+			&block.Salt,
+			&block.Nonce,
+			&t.ID,
+			&t.TypeName,
+			&t.Description,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		block.Type = &t
+		blocks = append(blocks, &block)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ReadBlockSizes returns payload sizes and type names of user blocks with
+// the given IDs, payloads aren't read.
+func (r *sqliteStorageRepository) ReadBlockSizes(ctx context.Context, userID int, ids []int) (_ []*model.BlockSize, err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.ReadBlockSizes",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// SQLite has no arrays, IDs are bound one by one
+	sqlText := `
+		SELECT
+			b.id, t.type_name, length(b.data)
+		FROM blocks b
+		INNER JOIN block_types t ON b.type_id = t.id
+		WHERE
+			b.user_id = ? AND b.id IN (?` + strings.Repeat(", ?", len(ids)-1) + `);`
+
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, userID)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.Conn.QueryContext(ctx, sqlText, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sizes []*model.BlockSize
+	for rows.Next() {
+		var size model.BlockSize
+		if err := rows.Scan(&size.ID, &size.TypeName, &size.Size); err != nil {
+			return nil, err
+		}
+
+		sizes = append(sizes, &size)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sizes, nil
+}
+
+func (r *sqliteStorageRepository) ReadBlockTypes(ctx context.Context) (_ []*model.Type, err error) {
+	ctx, span := startSQLiteQuerySpan(ctx, "sqliteStorageRepository.ReadBlockTypes", "SELECT", "block_types")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Conn.QueryContext(ctx, `SELECT id, type_name, description FROM block_types;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []*model.Type
+	for rows.Next() {
+		var t model.Type
+		if err := rows.Scan(&t.ID, &t.TypeName, &t.Description); err != nil {
+			return nil, err
+		}
+
+		types = append(types, &t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+func (r *sqliteStorageRepository) ReadUserUsage(ctx context.Context, userID int) (_ *model.Usage, err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.ReadUserUsage",
+		"SELECT",
+		"blocks",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var usage model.Usage
+	err = r.db.Conn.QueryRowContext(ctx, sqliteUsage, userID).Scan(&usage.Blocks, &usage.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+func (r *sqliteStorageRepository) ReadVaultKey(ctx context.Context, userID int) (_ []byte, err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.ReadVaultKey",
+		"SELECT",
+		"vault_keys",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		SELECT
+			wrapped_key
+		FROM vault_keys
+		WHERE
+			user_id = ?;`
+
+	var wrapped []byte
+	err = r.db.Conn.QueryRowContext(ctx, sqlText, userID).Scan(&wrapped)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.DBErrorNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapped, nil
+}
+
+func (r *sqliteStorageRepository) WriteVaultKey(ctx context.Context, userID int, wrapped, expected []byte) (err error) {
+	ctx, span := startSQLiteQuerySpan(
+		ctx,
+		"sqliteStorageRepository.WriteVaultKey",
+		"UPSERT",
+		"vault_keys",
+		tracing.UserID(userID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := r.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		UPDATE vault_keys
+		SET
+			wrapped_key = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE
+			user_id = ? AND wrapped_key = ?;`
+	args := []any{wrapped, userID, expected}
+	if expected == nil {
+		sqlText = `
+			INSERT INTO vault_keys (user_id, wrapped_key)
+			VALUES (?, ?)
+			ON CONFLICT (user_id) DO NOTHING;`
+		args = []any{userID, wrapped}
+	}
+
+	res, err := r.db.Conn.ExecContext(ctx, sqlText, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperror.DBErrorNoRows
+	}
+
+	return nil
+}
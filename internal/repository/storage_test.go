@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+)
+
+// TestEnvelopeBlocks saves, upgrades and reads envelope format blocks,
+// they have no salt and nonce outside of the envelope.
+func TestEnvelopeBlocks(t *testing.T) {
+	keys := utils.EnvelopeKeys{VaultKey: bytes.Repeat([]byte{1}, 32)}
+	textType := &model.Type{ID: 1, TypeName: string(model.TypeNameText)}
+
+	for _, engine := range storageEngines {
+		t.Run(engine.name, func(t *testing.T) {
+			repo, userID := engine.setup(t)
+			ctx := context.Background()
+
+			blocks := newBenchBlocks(userID, 3)
+			payloads := make(map[string][]byte)
+			for i, block := range blocks {
+				block.Type = textType
+				payloads[block.UID] = []byte(block.Title)
+				if i == 0 {
+					// legacy block, upgraded below
+					continue
+				}
+				if err := utils.SealBlock(block, keys, payloads[block.UID], utils.ProfileArgon2idLow, utils.CipherAES256GCM); err != nil {
+					t.Fatalf("SealBlock: %v", err)
+				}
+			}
+
+			legacy, err := repo.CreateBlock(ctx, blocks[0], 0, 0)
+			if err != nil {
+				t.Fatalf("CreateBlock legacy: %v", err)
+			}
+			if _, err := repo.CreateBlock(ctx, blocks[1], 0, 0); err != nil {
+				t.Fatalf("CreateBlock: %v", err)
+			}
+			if _, err := repo.CreateBlocks(ctx, blocks[2:]); err != nil {
+				t.Fatalf("CreateBlocks: %v", err)
+			}
+
+			legacy.Type = textType
+			if err := utils.SealBlock(legacy, keys, payloads[legacy.UID], utils.ProfileArgon2idLow, utils.CipherXChaCha20Poly1305); err != nil {
+				t.Fatalf("SealBlock: %v", err)
+			}
+			if err := repo.UpdateBlockPayloads(ctx, userID, []*model.Block{legacy}); err != nil {
+				t.Fatalf("UpdateBlockPayloads: %v", err)
+			}
+
+			stored, err := repo.ReadUserBlocks(ctx, userID)
+			if err != nil {
+				t.Fatalf("ReadUserBlocks: %v", err)
+			}
+			if len(stored) != len(blocks) {
+				t.Fatalf("user has %d blocks, want %d", len(stored), len(blocks))
+			}
+			for _, block := range stored {
+				if block.Format != utils.BlockFormatCurrent {
+					t.Errorf("block %q format is %d, want %d", block.Title, block.Format, utils.BlockFormatCurrent)
+				}
+				if len(block.Salt) != 0 || len(block.Nonce) != 0 {
+					t.Errorf("block %q has salt or nonce outside of the envelope", block.Title)
+				}
+				got, err := utils.OpenBlock(block, keys)
+				if err != nil {
+					t.Fatalf("OpenBlock %q: %v", block.Title, err)
+				}
+				if want := payloads[block.UID]; !bytes.Equal(got, want) {
+					t.Errorf("OpenBlock = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -49,3 +49,20 @@ func (r *subscriptionRepository) GetUserSubscribers(userID int) map[string]chan
 
 	return r.subscribers[userID]
 }
+
+func (r *subscriptionRepository) CountSubscribers() (int, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users, clients int
+	for _, userClients := range r.subscribers {
+		if len(userClients) == 0 {
+			continue
+		}
+
+		users++
+		clients += len(userClients)
+	}
+
+	return users, clients
+}
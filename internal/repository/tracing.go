@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository")
+
+// startQuerySpan starts a client span for a single PostgreSQL statement.
+// Query arguments are not recorded as they may contain user secrets.
+func startQuerySpan(
+	ctx context.Context,
+	name string,
+	operation string,
+	collection string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return startDBSpan(ctx, semconv.DBSystemNamePostgreSQL, name, operation, collection, attrs...)
+}
+
+// startSQLiteQuerySpan is startQuerySpan for SQLite repositories.
+func startSQLiteQuerySpan(
+	ctx context.Context,
+	name string,
+	operation string,
+	collection string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	return startDBSpan(ctx, semconv.DBSystemNameSQLite, name, operation, collection, attrs...)
+}
+
+func startDBSpan(
+	ctx context.Context,
+	system attribute.KeyValue,
+	name string,
+	operation string,
+	collection string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	attrs = append(
+		attrs,
+		system,
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName(collection),
+	)
+
+	return tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
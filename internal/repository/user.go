@@ -1,63 +1,89 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+	"github.com/jackc/pgx/v5"
 )
 
 type userRepository struct {
-	db *database.SQLDriver
+	db *database.PgxDriver
 }
 
 var _ ports.UserRepositoryReader = (*userRepository)(nil)
 var _ ports.UserRepositoryWriter = (*userRepository)(nil)
 
-func NewUserRepository(db *database.SQLDriver) *userRepository {
+func NewUserRepository(db *database.PgxDriver) *userRepository {
 	return &userRepository{
 		db: db,
 	}
 }
 
-func (u *userRepository) ReadUserByID(userID int32) (*model.User, error) {
+func (u *userRepository) ReadUserByID(ctx context.Context, userID int32) (_ *model.User, err error) {
+	ctx, span := startQuerySpan(ctx, "userRepository.ReadUserByID", "SELECT", "users", tracing.UserID(int(userID)))
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := u.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `SELECT id, username, password_hash, created_at FROM users WHERE id = $1;`
 	var user model.User
-	err := u.db.Conn.QueryRow(sqlText, userID).Scan(
+	err = u.db.Pool.QueryRow(ctx, sqlText, userID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.PasswordHash,
 		&user.CreatedAt,
 	)
-	if err != nil {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apperror.DBErrorNoRows
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	return &user, nil
 }
 
-func (u userRepository) ReadUserByUsername(username string) (*model.User, error) {
+func (u userRepository) ReadUserByUsername(ctx context.Context, username string) (_ *model.User, err error) {
+	ctx, span := startQuerySpan(ctx, "userRepository.ReadUserByUsername", "SELECT", "users")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := u.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `SELECT id, username, password_hash, created_at FROM users WHERE username = $1;`
 	var user model.User
-	err := u.db.Conn.QueryRow(sqlText, username).Scan(
+	err = u.db.Pool.QueryRow(ctx, sqlText, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.PasswordHash,
 		&user.CreatedAt,
 	)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apperror.DBErrorNoRows
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	span.SetAttributes(tracing.UserID(user.ID))
+
 	return &user, nil
 }
 
-func (u *userRepository) CreateUser(user *model.User) (*model.User, error) {
+func (u *userRepository) CreateUser(ctx context.Context, user *model.User) (_ *model.User, err error) {
+	ctx, span := startQuerySpan(ctx, "userRepository.CreateUser", "INSERT", "users")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := u.db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sqlText := `
 		INSERT INTO
 			users (
@@ -72,17 +98,20 @@ func (u *userRepository) CreateUser(user *model.User) (*model.User, error) {
 		)
 		RETURNING id;`
 
-	err := u.db.Conn.QueryRow(
+	err = u.db.Pool.QueryRow(
+		ctx,
 		sqlText,
 		user.Username,
 		user.PasswordHash,
 	).Scan(&user.ID)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apperror.DBErrorNoRows
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	span.SetAttributes(tracing.UserID(user.ID))
+
 	return user, nil
 }
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+)
+
+type memoryUserRepository struct {
+	mu         sync.RWMutex
+	lastID     int
+	byID       map[int]*model.User
+	byUsername map[string]*model.User
+}
+
+var _ ports.UserRepositoryReader = (*memoryUserRepository)(nil)
+var _ ports.UserRepositoryWriter = (*memoryUserRepository)(nil)
+
+func NewMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{
+		byID:       make(map[int]*model.User),
+		byUsername: make(map[string]*model.User),
+	}
+}
+
+func (u *memoryUserRepository) ReadUserByID(_ context.Context, userID int32) (*model.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	user, ok := u.byID[int(userID)]
+	if !ok {
+		return nil, apperror.DBErrorNoRows
+	}
+
+	copied := *user
+
+	return &copied, nil
+}
+
+func (u *memoryUserRepository) ReadUserByUsername(_ context.Context, username string) (*model.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	user, ok := u.byUsername[username]
+	if !ok {
+		return nil, apperror.DBErrorNoRows
+	}
+
+	copied := *user
+
+	return &copied, nil
+}
+
+func (u *memoryUserRepository) CreateUser(_ context.Context, user *model.User) (*model.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.byUsername[user.Username]; ok {
+		return nil, fmt.Errorf("username %q already exists", user.Username)
+	}
+
+	u.lastID++
+	user.ID = u.lastID
+	user.CreatedAt = time.Now().UTC()
+	stored := *user
+	u.byID[stored.ID] = &stored
+	u.byUsername[stored.Username] = &stored
+
+	return user, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+)
+
+type sqliteUserRepository struct {
+	db *database.SQLiteDriver
+}
+
+var _ ports.UserRepositoryReader = (*sqliteUserRepository)(nil)
+var _ ports.UserRepositoryWriter = (*sqliteUserRepository)(nil)
+
+func NewSQLiteUserRepository(db *database.SQLiteDriver) *sqliteUserRepository {
+	return &sqliteUserRepository{
+		db: db,
+	}
+}
+
+func (u *sqliteUserRepository) ReadUserByID(ctx context.Context, userID int32) (_ *model.User, err error) {
+	ctx, span := startSQLiteQuerySpan(ctx, "sqliteUserRepository.ReadUserByID", "SELECT", "users", tracing.UserID(int(userID)))
+	defer func() { endSpan(span, err) }()
+
+	return u.readUser(ctx, `SELECT id, username, password_hash, created_at FROM users WHERE id = ?;`, userID)
+}
+
+func (u *sqliteUserRepository) ReadUserByUsername(ctx context.Context, username string) (_ *model.User, err error) {
+	ctx, span := startSQLiteQuerySpan(ctx, "sqliteUserRepository.ReadUserByUsername", "SELECT", "users")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.readUser(ctx, `SELECT id, username, password_hash, created_at FROM users WHERE username = ?;`, username)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(tracing.UserID(user.ID))
+
+	return user, nil
+}
+
+func (u *sqliteUserRepository) readUser(ctx context.Context, sqlText string, arg any) (*model.User, error) {
+	ctx, cancel := u.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var user model.User
+	err := u.db.Conn.QueryRowContext(ctx, sqlText, arg).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.DBErrorNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (u *sqliteUserRepository) CreateUser(ctx context.Context, user *model.User) (_ *model.User, err error) {
+	ctx, span := startSQLiteQuerySpan(ctx, "sqliteUserRepository.CreateUser", "INSERT", "users")
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := u.db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sqlText := `
+		INSERT INTO
+			users (
+				username,
+				password_hash
+			)
+		VALUES (?, ?)
+		RETURNING id;`
+
+	err = u.db.Conn.QueryRowContext(ctx, sqlText, user.Username, user.PasswordHash).Scan(&user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(tracing.UserID(user.ID))
+
+	return user, nil
+}
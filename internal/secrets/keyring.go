@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// FileKeyring is a local keyring stored as JSON object
+// mapping secret names to values:
+//
+//	{"server.jwt.secret": "...", "database.password": "..."}
+//
+// The file is re-read on every lookup, so rotated values are picked up
+// on config reload. It must not be accessible by group or others.
+type FileKeyring struct {
+	path string
+}
+
+var _ Provider = (*FileKeyring)(nil)
+
+func NewFileKeyring(path string) *FileKeyring {
+	return &FileKeyring{path: path}
+}
+
+func (k *FileKeyring) Secret(_ context.Context, name string) (string, error) {
+	values, err := k.read()
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (k *FileKeyring) read() (map[string]string, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("keyring: %s permissions %o are too open, expected 0600", k.path, info.Mode().Perm())
+	}
+
+	b, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("keyring: invalid format of %s: %w", k.path, err)
+	}
+
+	return values, nil
+}
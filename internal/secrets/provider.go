@@ -0,0 +1,15 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by providers when secret is not defined.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider is an external source of secrets, secrets are addressed
+// by config keys, e.g. server.jwt.secret or database.password.
+type Provider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// Secret holds a value rotatable at runtime. Previous value is kept for a
+// grace period to let verifiers accept data signed right before rotation
+// (e.g. JWT tokens issued with the old key).
+type Secret struct {
+	mu            sync.RWMutex
+	current       []byte
+	previous      []byte
+	previousUntil time.Time
+}
+
+func NewSecret(value []byte) *Secret {
+	return &Secret{current: value}
+}
+
+func (s *Secret) Bytes() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.current
+}
+
+// Candidates returns current and, until its grace period ends, previous
+// values.
+func (s *Secret) Candidates() [][]byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.previous == nil || !time.Now().Before(s.previousUntil) {
+		return [][]byte{s.current}
+	}
+
+	return [][]byte{s.current, s.previous}
+}
+
+// Set rotates the secret keeping the previous value for grace, it reports
+// whether the value was changed. Setting the current value again only
+// shortens the grace of the previous one, so grace 0 drops it at once
+// (e.g. when the previous value has leaked).
+func (s *Secret) Set(value []byte, grace time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until := time.Now().Add(grace)
+	if bytes.Equal(s.current, value) {
+		if until.Before(s.previousUntil) {
+			s.previousUntil = until
+		}
+
+		return false
+	}
+
+	s.previous = s.current
+	s.previousUntil = until
+	s.current = value
+
+	return true
+}
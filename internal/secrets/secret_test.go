@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecretRotation(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace time.Duration
+		// regrace is set with the current value again after rotation
+		regrace time.Duration
+		want    int
+	}{
+		{name: "previous kept for grace", grace: time.Hour, regrace: time.Hour, want: 2},
+		{name: "zero grace drops previous", grace: 0, regrace: time.Hour, want: 1},
+		{name: "shortened grace drops previous", grace: time.Hour, regrace: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSecret([]byte("old"))
+			if !s.Set([]byte("new"), tt.grace) {
+				t.Fatal("Set of a new value reported no change")
+			}
+			if s.Set([]byte("new"), tt.regrace) {
+				t.Fatal("Set of the current value reported a change")
+			}
+
+			got := s.Candidates()
+			if len(got) != tt.want {
+				t.Fatalf("got %d candidates, want %d", len(got), tt.want)
+			}
+			if string(got[0]) != "new" || string(s.Bytes()) != "new" {
+				t.Errorf("current value is %q, want %q", got[0], "new")
+			}
+		})
+	}
+}
+
+func TestSecretGraceExpires(t *testing.T) {
+	s := NewSecret([]byte("old"))
+	s.Set([]byte("new"), 10*time.Millisecond)
+	if len(s.Candidates()) != 2 {
+		t.Fatal("previous value isn't accepted during grace")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if got := s.Candidates(); len(got) != 1 {
+		t.Errorf("previous value is accepted after grace, got %d candidates", len(got))
+	}
+}
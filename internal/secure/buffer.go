@@ -0,0 +1,103 @@
+package secure
+
+import "runtime"
+
+// Buffer holds a secret (password, key, plaintext) in memory allocated
+// outside of the Go heap where possible: it isn't copied by the runtime,
+// it's locked against swapping and excluded from core dumps. Destroy
+// zeroes and releases it, a forgotten buffer is released once collected.
+type Buffer struct {
+	data    []byte
+	locked  bool
+	cleanup runtime.Cleanup
+}
+
+// New allocates a zeroed buffer of size bytes.
+func New(size int) *Buffer {
+	mem, locked := alloc(size)
+	b := &Buffer{data: mem, locked: locked}
+	if size > 0 {
+		b.cleanup = runtime.AddCleanup(b, release, region{mem: mem, locked: locked})
+	}
+
+	return b
+}
+
+// FromBytes copies src into a new buffer and wipes src.
+func FromBytes(src []byte) *Buffer {
+	b := New(len(src))
+	copy(b.data, src)
+	Wipe(src)
+
+	return b
+}
+
+// FromString copies s into a new buffer, the string itself can't be wiped,
+// so callers should drop references to it.
+func FromString(s string) *Buffer {
+	b := New(len(s))
+	copy(b.data, s)
+
+	return b
+}
+
+// Bytes returns the secret, it's nil for a nil or destroyed buffer. The
+// slice must not be used after Destroy.
+func (b *Buffer) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+
+	return b.data
+}
+
+func (b *Buffer) Len() int {
+	return len(b.Bytes())
+}
+
+// Clone copies the secret into a new buffer, it's nil for a nil buffer.
+// Workers take clones, so the original may be destroyed meanwhile.
+func (b *Buffer) Clone() *Buffer {
+	if b == nil || b.data == nil {
+		return nil
+	}
+
+	c := New(len(b.data))
+	copy(c.data, b.data)
+
+	return c
+}
+
+// Locked reports whether the buffer is locked in memory, it isn't if the
+// platform doesn't support it or RLIMIT_MEMLOCK is exhausted.
+func (b *Buffer) Locked() bool {
+	return b != nil && b.locked
+}
+
+// Destroy zeroes and releases the buffer, it's safe to call on nil and
+// destroyed buffers.
+func (b *Buffer) Destroy() {
+	if b == nil || b.data == nil {
+		return
+	}
+
+	b.cleanup.Stop()
+	release(region{mem: b.data, locked: b.locked})
+	b.data = nil
+	b.locked = false
+}
+
+// Wipe zeroes b.
+func Wipe(b []byte) {
+	clear(b)
+}
+
+type region struct {
+	mem    []byte
+	locked bool
+}
+
+func release(r region) {
+	Wipe(r.mem)
+	free(r.mem, r.locked)
+}
@@ -0,0 +1,13 @@
+package secure
+
+import "golang.org/x/sys/unix"
+
+func excludeFromDump(mem []byte) {
+	_ = unix.Madvise(mem, unix.MADV_DONTDUMP)
+}
+
+// DisableCoreDumps marks the process non-dumpable: no core dumps, and
+// other processes of the user can't ptrace it or read its memory.
+func DisableCoreDumps() error {
+	return unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+}
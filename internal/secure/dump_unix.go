@@ -0,0 +1,12 @@
+//go:build unix && !linux
+
+package secure
+
+import "golang.org/x/sys/unix"
+
+func excludeFromDump([]byte) {}
+
+// DisableCoreDumps sets core dump size limit of the process to zero.
+func DisableCoreDumps() error {
+	return unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{})
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package secure
+
+// alloc falls back to the heap, buffers are still zeroed on Destroy.
+func alloc(size int) ([]byte, bool) {
+	return make([]byte, size), false
+}
+
+func free([]byte, bool) {}
+
+// DisableCoreDumps isn't supported on the platform.
+func DisableCoreDumps() error {
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package secure
+
+import "golang.org/x/sys/unix"
+
+// alloc maps anonymous memory for the buffer and locks it, the heap is
+// used if mapping fails.
+func alloc(size int) ([]byte, bool) {
+	if size == 0 {
+		return []byte{}, false
+	}
+
+	mem, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANON)
+	if err != nil {
+		return make([]byte, size), false
+	}
+	excludeFromDump(mem)
+
+	return mem, unix.Mlock(mem) == nil
+}
+
+func free(mem []byte, locked bool) {
+	if locked {
+		_ = unix.Munlock(mem)
+	}
+	// Munmap only releases regions mapped by Mmap, the heap fallback is left to GC
+	_ = unix.Munmap(mem)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const logLevelKey = "server.log_level"
+
+type adminService struct {
+	mu     sync.Mutex
+	level  zap.AtomicLevel
+	logger *zap.SugaredLogger
+	// configLevel is the level from config, temporary overrides revert to it
+	configLevel zapcore.Level
+	revert      *time.Timer
+	// override identifies the latest temporary override, so a timer
+	// fired after being replaced doesn't revert a newer one
+	override uint64
+	admins   []int
+}
+
+type AdminServiceArgs struct {
+	// Level is the atomic level of the server logger
+	Level        zap.AtomicLevel
+	AdminUserIDs []int
+	Logger       *zap.SugaredLogger
+}
+
+var _ ports.AdminService = (*adminService)(nil)
+
+func NewAdminService(args AdminServiceArgs) *adminService {
+	return &adminService{
+		level:       args.Level,
+		logger:      args.Logger,
+		configLevel: args.Level.Level(),
+		admins:      args.AdminUserIDs,
+	}
+}
+
+func (s *adminService) SetSetting(
+	ctx context.Context,
+	userID int,
+	key, value string,
+	ttl time.Duration,
+) (_ string, err error) {
+	_, span := tracer.Start(ctx, "adminService.SetSetting")
+	defer func() { endSpan(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.admins, userID) {
+		return "", apperror.AdminPermissionDeniedError
+	}
+	if !config.IsReloadable(key) {
+		return "", apperror.AdminSettingNotReloadableError
+	}
+	if key != logLevelKey {
+		return "", apperror.AdminSettingUnsupportedError
+	}
+
+	level, err := zapcore.ParseLevel(value)
+	if err != nil {
+		return "", apperror.NewValidationError(apperror.FieldViolation{
+			Field:       "value",
+			Description: "unknown log level, expected one of debug, info, warn, error",
+		})
+	}
+	if ttl < 0 {
+		return "", apperror.NewValidationError(apperror.FieldViolation{
+			Field:       "ttl_seconds",
+			Description: "ttl must not be negative",
+		})
+	}
+
+	previous := s.level.Level()
+	s.level.SetLevel(level)
+	s.stopRevert()
+	if ttl > 0 {
+		override := s.override
+		s.revert = time.AfterFunc(ttl, func() { s.revertLevel(override) })
+	}
+
+	s.logger.Infow("log level changed", "user_id", userID, "level", level.String(), "previous", previous.String(), "ttl", ttl)
+
+	return previous.String(), nil
+}
+
+// ApplyConfig applies reloaded admin settings, configured log level
+// replaces an override set with SetSetting even if it's unchanged.
+func (s *adminService) ApplyConfig(conf *config.Server) error {
+	level, err := zapcore.ParseLevel(conf.LogLevel)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.admins = conf.Admin.UserIDs
+	s.stopRevert()
+	s.configLevel = level
+	s.level.SetLevel(level)
+
+	return nil
+}
+
+func (s *adminService) revertLevel(override uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if override != s.override {
+		return
+	}
+
+	s.level.SetLevel(s.configLevel)
+	s.revert = nil
+	s.logger.Infow("temporary log level expired", "level", s.configLevel.String())
+}
+
+func (s *adminService) stopRevert() {
+	s.override++
+	if s.revert != nil {
+		s.revert.Stop()
+		s.revert = nil
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestApplyConfigReplacesOverride(t *testing.T) {
+	const adminID = 1
+
+	for _, ttl := range []time.Duration{0, time.Hour} {
+		level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+		s := NewAdminService(AdminServiceArgs{
+			Level:        level,
+			AdminUserIDs: []int{adminID},
+			Logger:       zap.NewNop().Sugar(),
+		})
+
+		if _, err := s.SetSetting(context.Background(), adminID, logLevelKey, "debug", ttl); err != nil {
+			t.Fatalf("SetSetting: %v", err)
+		}
+		if level.Level() != zapcore.DebugLevel {
+			t.Fatalf("level is %s after override, want debug", level.Level())
+		}
+
+		// configured level is unchanged, the override is replaced anyway
+		err := s.ApplyConfig(&config.Server{
+			LogLevel: "info",
+			Admin:    config.Admin{UserIDs: []int{adminID}},
+		})
+		if err != nil {
+			t.Fatalf("ApplyConfig: %v", err)
+		}
+		if level.Level() != zapcore.InfoLevel {
+			t.Errorf("override with ttl %s: level is %s after config reload, want info", ttl, level.Level())
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
 	"go.uber.org/zap"
 )
@@ -19,13 +21,14 @@ type userRepository interface {
 type authService struct {
 	userRepository userRepository
 	logger         *zap.SugaredLogger
-	jwtSecret      []byte
+	jwtSecret      *secrets.Secret
 }
 
 type AuthServiceArgs struct {
 	UserRepository userRepository
 	Logger         *zap.SugaredLogger
-	JWTSecret      []byte
+	// JWTSecret signs issued tokens, it may be rotated at runtime
+	JWTSecret *secrets.Secret
 }
 
 var _ ports.AuthService = (*authService)(nil)
@@ -38,16 +41,21 @@ func NewAuthService(args AuthServiceArgs) *authService {
 	}
 }
 
-func (s *authService) Register(username, password string) (string, error) {
-	_, err := s.userRepository.ReadUserByUsername(username)
+func (s *authService) Register(ctx context.Context, username, password string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.Register")
+	defer func() { endSpan(span, err) }()
+
+	if err := validateCredentials(username, password); err != nil {
+		return "", err
+	}
+
+	_, err = s.userRepository.ReadUserByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, apperror.DBErrorNoRows) {
 			// TODO: app pepper from config for paswword hasing
 			passwordHash, err := utils.HashPassword([]byte(password))
 			if err != nil {
-				s.logger.Error("failed to hash password", "error", err)
-
-				return "", apperror.AuthErrorGeneric
+				return "", apperror.AuthErrorGeneric.Wrap(err)
 			}
 
 			user := &model.User{
@@ -55,52 +63,74 @@ func (s *authService) Register(username, password string) (string, error) {
 				PasswordHash: hex.EncodeToString(passwordHash),
 			}
 
-			user, err = s.userRepository.CreateUser(user)
+			user, err = s.userRepository.CreateUser(ctx, user)
 			if err != nil {
-				s.logger.Error(err)
-
-				return "", apperror.AuthCreateUserError
+				return "", apperror.AuthCreateUserError.Wrap(err)
 			}
 
-			token, err := utils.IssueJWTToken(user.ID, s.jwtSecret)
+			token, err := utils.IssueJWTToken(user.ID, s.jwtSecret.Bytes())
 			if err != nil {
-				s.logger.Error("failed to issue JWT token", "error", err)
-
-				return "", apperror.AuthErrorGeneric
+				return "", apperror.AuthErrorGeneric.Wrap(err)
 			}
 
 			return string(token), nil
 		}
 
-		return "", apperror.AuthErrorGeneric
+		return "", apperror.AuthErrorGeneric.Wrap(err)
 	}
 
 	return "", apperror.AuthUserExistsError
 }
 
-func (s *authService) Authenticate(username, password string) (string, error) {
-	user, err := s.userRepository.ReadUserByUsername(username)
+func (s *authService) Authenticate(ctx context.Context, username, password string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	if err := validateCredentials(username, password); err != nil {
+		return "", err
+	}
+
+	user, err := s.userRepository.ReadUserByUsername(ctx, username)
 	if errors.Is(err, apperror.DBErrorNoRows) {
 		return "", apperror.AuthUserNotExistsError
 	}
 	if err != nil {
-		return "", err
+		return "", apperror.AuthErrorGeneric.Wrap(err)
 	}
 
 	decoded, err := hex.DecodeString(user.PasswordHash)
 	if err != nil {
-		return "", err
+		return "", apperror.AuthErrorGeneric.Wrap(err)
 	}
 	if err := utils.ComparePassword(decoded, []byte(password)); err != nil {
 		return "", apperror.AuthInvalidCredentialsError
 	}
 
-	token, err := utils.IssueJWTToken(user.ID, s.jwtSecret)
+	token, err := utils.IssueJWTToken(user.ID, s.jwtSecret.Bytes())
 	if err != nil {
-		s.logger.Error("failed to issue JWT token", "error", err)
-
-		return "", err
+		return "", apperror.AuthErrorGeneric.Wrap(err)
 	}
 
 	return string(token), nil
 }
+
+func validateCredentials(username, password string) error {
+	var violations []apperror.FieldViolation
+	if username == "" {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       "username",
+			Description: "username must not be empty",
+		})
+	}
+	if password == "" {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       "password",
+			Description: "password must not be empty",
+		})
+	}
+	if len(violations) > 0 {
+		return apperror.NewValidationError(violations...)
+	}
+
+	return nil
+}
@@ -1,73 +1,427 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/tracing"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type storageService struct {
 	storageRepository   ports.StorageRepository
 	subscriptionService ports.SubscriptionService
+	quotas              atomic.Pointer[config.Quotas]
 	logger              *zap.SugaredLogger
 }
 
 type StorageServiceArgs struct {
 	StorageRepository   ports.StorageRepository
 	SubscriptionService ports.SubscriptionService
+	Quotas              config.Quotas
 	Logger              *zap.SugaredLogger
 }
 
 var _ ports.StorageService = (*storageService)(nil)
 
+// maxBatchBlocks bounds number of blocks updated at once.
+const maxBatchBlocks = 100
+
+// maxWrappedVaultKeySize bounds wrapped vault key, an envelope of a 32 byte
+// key is about a hundred bytes.
+const maxWrappedVaultKeySize = 1 << 10
+
+// ciphertextField names the payload in field violations, it mirrors the
+// request field name of the proto, which is spelled "chiphertext".
+const ciphertextField = "chiphertext"
+
 func NewStorageService(args StorageServiceArgs) *storageService {
-	return &storageService{
+	s := &storageService{
 		storageRepository:   args.StorageRepository,
 		subscriptionService: args.SubscriptionService,
 		logger:              args.Logger,
 	}
+	s.SetQuotas(args.Quotas)
+
+	return s
+}
+
+// SetQuotas replaces quotas checked by following saves.
+func (s *storageService) SetQuotas(quotas config.Quotas) {
+	s.quotas.Store(&quotas)
+}
+
+func (s *storageService) SaveDataBlock(ctx context.Context, userID int, in *model.Block) (_ *model.Block, err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.SaveDataBlock",
+		trace.WithAttributes(tracing.UserID(userID), tracing.BlockTypeID(in.TypeID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := validateBlock(in); err != nil {
+		return nil, err
+	}
+	quotas := s.quotas.Load()
+	if err := s.checkBlockSize(ctx, quotas, in); err != nil {
+		return nil, err
+	}
+	if in.UID == "" {
+		// clients before associated data binding don't send block UID
+		in.UID = uuid.NewString()
+	}
+
+	block, err := s.storageRepository.CreateBlock(ctx, in, quotas.MaxBlocks, quotas.MaxBytes)
+	switch {
+	case errors.Is(err, apperror.DBErrorQuotaBlocks):
+		return nil, apperror.QuotaBlocksExceededError
+	case errors.Is(err, apperror.DBErrorQuotaBytes):
+		return nil, apperror.QuotaBytesExceededError
+	case err != nil:
+		return nil, storageError(apperror.StorageCreateBlockError, err)
+	}
+
+	span.SetAttributes(tracing.BlockID(block.ID))
+	s.notify(userID)
+
+	return block, nil
 }
 
-func (s *storageService) SaveDataBlock(userID int, in *model.Block) (*model.Block, error) {
-	block, err := s.storageRepository.CreateBlock(in)
+// UpdateDataBlock replaces encrypted payload of the user block, title and
+// type are kept. Subscribers are notified about the change.
+func (s *storageService) UpdateDataBlock(ctx context.Context, userID int, in *model.Block) error {
+	return s.UpdateDataBlocks(ctx, userID, []*model.Block{in})
+}
+
+// UpdateDataBlocks replaces encrypted payloads of user blocks atomically,
+// either all of them are updated or none.
+func (s *storageService) UpdateDataBlocks(ctx context.Context, userID int, in []*model.Block) (err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.UpdateDataBlocks",
+		trace.WithAttributes(tracing.UserID(userID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := validateBatch(in); err != nil {
+		return err
+	}
+
+	if err := s.checkBatchQuotas(ctx, userID, in); err != nil {
+		return err
+	}
+
+	err = s.storageRepository.UpdateBlockPayloads(ctx, userID, in)
+	if errors.Is(err, apperror.DBErrorNoRows) {
+		return apperror.StorageErrorNotFound
+	}
 	if err != nil {
-		s.logger.Error(err)
-		return nil, &apperror.StorageCreateBlockError
+		return storageError(apperror.StorageUpdateBlockError, err)
 	}
 
-	blocks, err := s.ListDataBlocks(userID)
+	s.notify(userID)
+
+	return nil
+}
+
+// checkBatchQuotas reports whether updated blocks fit into block size and
+// total size quotas. Only sizes of stored blocks are read, not payloads.
+func (s *storageService) checkBatchQuotas(ctx context.Context, userID int, in []*model.Block) error {
+	ids := make([]int, len(in))
+	for i, block := range in {
+		ids[i] = block.ID
+	}
+
+	sizes, err := s.storageRepository.ReadBlockSizes(ctx, userID, ids)
 	if err != nil {
-		return nil, &apperror.StorageListDataBlockError
+		return storageError(apperror.StorageListDataBlockError, err)
 	}
 
-	s.subscriptionService.NotifySubscribers(userID, blocks)
+	quotas := s.quotas.Load()
+	delta := 0
+	for _, block := range in {
+		i := slices.IndexFunc(sizes, func(b *model.BlockSize) bool { return b.ID == block.ID })
+		if i < 0 {
+			return apperror.StorageErrorNotFound
+		}
+		if size := quotas.BlockSize(sizes[i].TypeName); size > 0 && len(block.Data) > size {
+			return apperror.QuotaBlockSizeExceededError
+		}
+		delta += len(block.Data) - sizes[i].Size
+	}
+	if quotas.MaxBytes == 0 || delta <= 0 {
+		return nil
+	}
 
-	return block, nil
+	usage, err := s.storageRepository.ReadUserUsage(ctx, userID)
+	if err != nil {
+		return storageError(apperror.StorageReadUsageError, err)
+	}
+	if usage.Bytes+delta > quotas.MaxBytes {
+		return apperror.QuotaBytesExceededError
+	}
+
+	return nil
+}
+
+// notify wakes list streams of the user, they reload blocks themselves,
+// so none are passed along.
+func (s *storageService) notify(userID int) {
+	s.subscriptionService.NotifySubscribers(userID, nil)
 }
 
-func (s *storageService) ListDataBlocks(userID int) ([]*model.Block, error) {
-	blocks, err := s.storageRepository.ReadUserBlocks(userID)
-	fmt.Printf("blocks retrieved from repository: %v\n", err)
+func (s *storageService) ListDataBlocks(ctx context.Context, userID int) (_ []*model.Block, err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.ListDataBlocks",
+		trace.WithAttributes(tracing.UserID(userID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	blocks, err := s.storageRepository.ReadUserBlocks(ctx, userID)
 	if err != nil && errors.Is(err, apperror.DBErrorNoRows) {
-		fmt.Printf("blocks in ListDataBlocks: %v\n", blocks)
 		return []*model.Block{}, nil
 	}
 	if err != nil {
-		return nil, &apperror.StorageListDataBlockError
+		return nil, storageError(apperror.StorageListDataBlockError, err)
 	}
 
 	return blocks, nil
 }
 
-func (s *storageService) GetBlockTypes() ([]*model.Type, error) {
-	types, err := s.storageRepository.ReadBlockTypes()
+func (s *storageService) GetBlockTypes(ctx context.Context) (_ []*model.Type, err error) {
+	ctx, span := tracer.Start(ctx, "storageService.GetBlockTypes")
+	defer func() { endSpan(span, err) }()
+
+	types, err := s.storageRepository.ReadBlockTypes(ctx)
 	if err != nil {
-		return nil, &apperror.StorageReadBlockTypeError
+		return nil, storageError(apperror.StorageReadBlockTypeError, err)
 	}
 
 	return types, nil
 }
+
+func (s *storageService) GetUsage(ctx context.Context, userID int) (_ *model.Usage, err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.GetUsage",
+		trace.WithAttributes(tracing.UserID(userID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	usage, err := s.storageRepository.ReadUserUsage(ctx, userID)
+	if err != nil {
+		return nil, storageError(apperror.StorageReadUsageError, err)
+	}
+
+	types, err := s.GetBlockTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := s.quotas.Load()
+	usage.MaxBytes = quotas.MaxBytes
+	usage.MaxBlocks = quotas.MaxBlocks
+	for _, t := range types {
+		usage.TypeQuotas = append(usage.TypeQuotas, &model.TypeQuota{
+			Type:         t,
+			MaxBlockSize: quotas.BlockSize(t.TypeName),
+		})
+	}
+
+	return usage, nil
+}
+
+func (s *storageService) GetVaultKey(ctx context.Context, userID int) (_ []byte, err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.GetVaultKey",
+		trace.WithAttributes(tracing.UserID(userID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	wrapped, err := s.storageRepository.ReadVaultKey(ctx, userID)
+	if errors.Is(err, apperror.DBErrorNoRows) {
+		return nil, apperror.StorageVaultKeyNotFoundError
+	}
+	if err != nil {
+		return nil, storageError(apperror.StorageReadVaultKeyError, err)
+	}
+
+	return wrapped, nil
+}
+
+// SetVaultKey stores wrapped vault key, replacing expected one. Empty
+// expected creates the first key, so two clients can't set up different
+// vault keys of the same user.
+func (s *storageService) SetVaultKey(ctx context.Context, userID int, wrapped, expected []byte) (err error) {
+	ctx, span := tracer.Start(
+		ctx,
+		"storageService.SetVaultKey",
+		trace.WithAttributes(tracing.UserID(userID)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if !utils.IsEnvelope(wrapped) || len(wrapped) > maxWrappedVaultKeySize {
+		return apperror.NewValidationError(apperror.FieldViolation{
+			Field:       "wrapped_key",
+			Description: "wrapped key must be a ciphertext envelope",
+		})
+	}
+	if len(expected) == 0 {
+		expected = nil
+	}
+
+	err = s.storageRepository.WriteVaultKey(ctx, userID, wrapped, expected)
+	if errors.Is(err, apperror.DBErrorNoRows) {
+		return apperror.StorageVaultKeyConflictError
+	}
+	if err != nil {
+		return storageError(apperror.StorageWriteVaultKeyError, err)
+	}
+
+	return nil
+}
+
+// checkBlockSize reports whether the block type exists and the block fits
+// into its size quota. Block count and total size quotas are checked by
+// the repository in the insert's transaction.
+func (s *storageService) checkBlockSize(ctx context.Context, quotas *config.Quotas, block *model.Block) error {
+	types, err := s.GetBlockTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(types, func(t *model.Type) bool { return t.ID == block.TypeID })
+	if i < 0 {
+		return apperror.NewValidationError(apperror.FieldViolation{
+			Field:       "type_id",
+			Description: "unknown block type",
+		})
+	}
+	if size := quotas.BlockSize(types[i].TypeName); size > 0 && len(block.Data) > size {
+		return apperror.QuotaBlockSizeExceededError
+	}
+
+	return nil
+}
+
+// storageError wraps repository error into application error,
+// statement timeouts are reported as retryable unavailability.
+func storageError(appErr *apperror.AppError, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperror.StorageUnavailableError.Wrap(err)
+	}
+
+	return appErr.Wrap(err)
+}
+
+func validateBlock(block *model.Block) error {
+	var violations []apperror.FieldViolation
+	if block.Title == "" {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       "title",
+			Description: "title must not be empty",
+		})
+	}
+	violations = append(violations, payloadViolations(block)...)
+	if block.TypeID <= 0 {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       "type_id",
+			Description: "block type is required",
+		})
+	}
+	if len(violations) > 0 {
+		return apperror.NewValidationError(violations...)
+	}
+
+	return nil
+}
+
+// validateBatch checks payloads of a batch update, field names of
+// violations are prefixed with the block index.
+func validateBatch(blocks []*model.Block) error {
+	if len(blocks) == 0 || len(blocks) > maxBatchBlocks {
+		return apperror.NewValidationError(apperror.FieldViolation{
+			Field:       "blocks",
+			Description: fmt.Sprintf("batch must have from 1 to %d blocks", maxBatchBlocks),
+		})
+	}
+
+	var violations []apperror.FieldViolation
+	seen := make(map[int]bool, len(blocks))
+	for i, block := range blocks {
+		prefix := ""
+		if len(blocks) > 1 {
+			prefix = fmt.Sprintf("blocks[%d].", i)
+		}
+		if seen[block.ID] {
+			violations = append(violations, apperror.FieldViolation{
+				Field:       prefix + "block_id",
+				Description: "block must not repeat in a batch",
+			})
+		}
+		seen[block.ID] = true
+
+		for _, v := range payloadViolations(block) {
+			v.Field = prefix + v.Field
+			violations = append(violations, v)
+		}
+	}
+	if len(violations) > 0 {
+		return apperror.NewValidationError(violations...)
+	}
+
+	return nil
+}
+
+// payloadViolations checks encrypted payload fields of the block.
+func payloadViolations(block *model.Block) []apperror.FieldViolation {
+	var violations []apperror.FieldViolation
+	if len(block.Data) == 0 {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       ciphertextField,
+			Description: "encrypted payload must not be empty",
+		})
+	}
+	if block.Format >= utils.BlockFormatEnvelope {
+		// salt and nonce are in the envelope header
+		if len(block.Data) > 0 && !utils.IsEnvelope(block.Data) {
+			violations = append(violations, apperror.FieldViolation{
+				Field:       ciphertextField,
+				Description: "payload must be a ciphertext envelope",
+			})
+		}
+	} else {
+		if len(block.Salt) == 0 {
+			violations = append(violations, apperror.FieldViolation{
+				Field:       "salt",
+				Description: "salt must not be empty",
+			})
+		}
+		if len(block.Nonce) == 0 {
+			violations = append(violations, apperror.FieldViolation{
+				Field:       "nonce",
+				Description: "nonce must not be empty",
+			})
+		}
+	}
+	if block.Format < 0 {
+		violations = append(violations, apperror.FieldViolation{
+			Field:       "format",
+			Description: "format must not be negative",
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/apperror"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/repository"
+	"go.uber.org/zap"
+)
+
+// payloadsUnreadRepository fails the test if payloads of all user blocks
+// are loaded.
+type payloadsUnreadRepository struct {
+	ports.StorageRepository
+	t *testing.T
+}
+
+func (r payloadsUnreadRepository) ReadUserBlocks(ctx context.Context, userID int) ([]*model.Block, error) {
+	r.t.Error("all user blocks were read")
+
+	return r.StorageRepository.ReadUserBlocks(ctx, userID)
+}
+
+func TestUpdateDataBlocksQuotas(t *testing.T) {
+	const userID = 1
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		// sizes are new payload sizes of the seeded blocks, by index
+		sizes   map[int]int
+		unknown bool
+		wantErr error
+	}{
+		{name: "within quotas", maxBytes: 100, sizes: map[int]int{0: 40}},
+		{name: "block size", maxBytes: 100, sizes: map[int]int{0: 41}, wantErr: apperror.QuotaBlockSizeExceededError},
+		{name: "total size", maxBytes: 100, sizes: map[int]int{0: 40, 1: 40}, wantErr: apperror.QuotaBytesExceededError},
+		{name: "shrink over quota", maxBytes: 50, sizes: map[int]int{0: 20}},
+		{name: "unknown block", maxBytes: 100, unknown: true, wantErr: apperror.StorageErrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryStorageRepository()
+			s := NewStorageService(StorageServiceArgs{
+				StorageRepository: payloadsUnreadRepository{StorageRepository: repo, t: t},
+				SubscriptionService: NewSubscriptionService(SubscriptionServiceArgs{
+					SubscriptionRepository: repository.NewSubscriptionRepository(),
+					Logger:                 zap.NewNop().Sugar(),
+				}),
+				Quotas: config.Quotas{
+					MaxBytes:         tt.maxBytes,
+					TypeMaxBlockSize: config.BlockTypeLimits{string(model.TypeNameText): 40},
+				},
+				Logger: zap.NewNop().Sugar(),
+			})
+
+			// three text blocks of 30 bytes
+			ctx := context.Background()
+			var ids []int
+			for range 3 {
+				block, err := repo.CreateBlock(ctx, newTestBlock(userID, 30), 0, 0)
+				if err != nil {
+					t.Fatalf("CreateBlock: %v", err)
+				}
+				ids = append(ids, block.ID)
+			}
+
+			var in []*model.Block
+			for i, size := range tt.sizes {
+				block := newTestBlock(userID, size)
+				block.ID = ids[i]
+				in = append(in, block)
+			}
+			if tt.unknown {
+				block := newTestBlock(userID, 10)
+				block.ID = ids[len(ids)-1] + 1
+				in = append(in, block)
+			}
+
+			err := s.UpdateDataBlocks(ctx, userID, in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateDataBlocks: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newTestBlock(userID, size int) *model.Block {
+	return &model.Block{
+		UserID: userID,
+		TypeID: 1,
+		Title:  "title",
+		Data:   bytes.Repeat([]byte{1}, size),
+		Salt:   []byte("salt"),
+		Nonce:  []byte("nonce"),
+	}
+}
@@ -1,21 +1,31 @@
 package service
 
 import (
-	"fmt"
-
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/metrics"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/ports"
+	"go.uber.org/zap"
 )
 
 type subscriptionService struct {
 	subscriptionRepository ports.SubscriptionRepository
+	metrics                *metrics.Metrics
+	logger                 *zap.SugaredLogger
+}
+
+type SubscriptionServiceArgs struct {
+	SubscriptionRepository ports.SubscriptionRepository
+	Metrics                *metrics.Metrics
+	Logger                 *zap.SugaredLogger
 }
 
 var _ ports.SubscriptionService = (*subscriptionService)(nil)
 
-func NewSubscriptionService(subscriptionRepository ports.SubscriptionRepository) *subscriptionService {
+func NewSubscriptionService(args SubscriptionServiceArgs) *subscriptionService {
 	return &subscriptionService{
-		subscriptionRepository: subscriptionRepository,
+		subscriptionRepository: args.SubscriptionRepository,
+		metrics:                args.Metrics,
+		logger:                 args.Logger,
 	}
 }
 
@@ -34,7 +44,8 @@ func (s *subscriptionService) NotifySubscribers(userID int, blocks []*model.Bloc
 		case ch <- blocks:
 		default:
 			// If the channel is full, skip sending to avoid blocking
-			fmt.Printf("Skipping subscriber %s: channel is full\n", clientID)
+			s.metrics.NotificationDropped()
+			s.logger.Debugw("skipping subscriber: channel is full", "user_id", userID, "client_id", clientID)
 		}
 	}
 }
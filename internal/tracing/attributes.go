@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Attribute keys shared by service and repository spans.
+// Only identifiers are recorded, payloads (ciphertext, salt, nonce) must never be
+// attached to spans.
+const (
+	UserIDKey      = attribute.Key("gophkeeper.user.id")
+	BlockIDKey     = attribute.Key("gophkeeper.block.id")
+	BlockTypeIDKey = attribute.Key("gophkeeper.block.type_id")
+	ClientIDKey    = attribute.Key("gophkeeper.client.id")
+)
+
+func UserID(id int) attribute.KeyValue {
+	return UserIDKey.Int(id)
+}
+
+func BlockID(id int) attribute.KeyValue {
+	return BlockIDKey.Int(id)
+}
+
+func BlockTypeID(id int) attribute.KeyValue {
+	return BlockTypeIDKey.Int(id)
+}
+
+func ClientID(id string) attribute.KeyValue {
+	return ClientIDKey.String(id)
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ShutdownFn flushes pending spans and releases exporter resources.
+type ShutdownFn func(ctx context.Context) error
+
+// Setup installs global tracer provider and W3C trace context propagator.
+// With disabled exporter only propagator is installed, so incoming
+// trace context is still passed through to outgoing calls.
+func Setup(conf config.Tracing, serviceName string) (ShutdownFn, error) {
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
+
+	exporter, closer, err := newExporter(conf)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// ratio 0 records no root traces, 1 records all of them
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+
+		return err
+	}, nil
+}
+
+func newExporter(conf config.Tracing) (sdktrace.SpanExporter, io.Closer, error) {
+	switch conf.Exporter {
+	case "", config.TracingExporterNone:
+		return nil, nil, nil
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+
+		return exporter, nil, err
+	case config.TracingExporterFile:
+		if conf.FilePath == "" {
+			return nil, nil, fmt.Errorf("tracing file path is required for %q exporter", conf.Exporter)
+		}
+
+		f, err := os.OpenFile(conf.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+
+		return exporter, f, nil
+	case config.TracingExporterOTLP:
+		exporter, err := otlptracegrpc.New(
+			context.Background(),
+			otlptracegrpc.WithEndpoint(conf.Endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+
+		return exporter, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", conf.Exporter)
+	}
+}
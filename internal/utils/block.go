@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+)
+
+const (
+	// BlockFormatLegacy payload is sealed without associated data.
+	BlockFormatLegacy = 0
+	// BlockFormatAAD payload is sealed with BlockAAD of the block.
+	BlockFormatAAD = 1
+	// BlockFormatEnvelope payload is an envelope sealed with BlockAAD, salt,
+	// nonce, profile and cipher columns are informational only.
+	BlockFormatEnvelope = 2
+	// BlockFormatCurrent is used for new blocks, older ones are upgraded.
+	BlockFormatCurrent = BlockFormatEnvelope
+)
+
+const blockAADDomain = "gophkeeper/block"
+
+// BlockAAD is block metadata bound to the ciphertext as associated data,
+// decryption fails if any of it was changed on the server.
+type BlockAAD struct {
+	Format   int
+	UID      string
+	UserID   int
+	TypeName string
+	Title    string
+}
+
+// Bytes encodes metadata with length prefixed fields, so a value can't be
+// moved from one field to another without changing the encoding.
+func (a BlockAAD) Bytes() []byte {
+	buf := []byte(blockAADDomain)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(a.Format))
+	for _, f := range []string{a.UID, strconv.Itoa(a.UserID), a.TypeName, a.Title} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(f)))
+		buf = append(buf, f...)
+	}
+
+	return buf
+}
+
+// BlockAADFor returns associated data of the block in its format, legacy
+// blocks have none.
+func BlockAADFor(block *model.Block) []byte {
+	if block.Format == BlockFormatLegacy {
+		return nil
+	}
+
+	var typeName string
+	if block.Type != nil {
+		typeName = block.Type.TypeName
+	}
+
+	return BlockAAD{
+		Format:   block.Format,
+		UID:      block.UID,
+		UserID:   block.UserID,
+		TypeName: typeName,
+		Title:    block.Title,
+	}.Bytes()
+}
+
+// SealBlock seals payload into block data in the current format, block
+// metadata must be set before as it's bound to the ciphertext.
+func SealBlock(block *model.Block, keys EnvelopeKeys, payload []byte, profile ScryptProfile, c Cipher) error {
+	block.Format = BlockFormatCurrent
+	block.Profile = string(profile)
+	block.Cipher = string(c)
+	block.Salt = nil
+	block.Nonce = nil
+
+	data, err := SealEnvelope(keys, payload, profile, c, BlockAADFor(block))
+	if err != nil {
+		return err
+	}
+	block.Data = data
+
+	return nil
+}
+
+// OpenBlock decrypts block payload of any known format, blocks before the
+// envelope format are opened with the password only.
+func OpenBlock(block *model.Block, keys EnvelopeKeys) ([]byte, error) {
+	switch block.Format {
+	case BlockFormatLegacy, BlockFormatAAD:
+		return decryptWithPassword(
+			keys.Cache,
+			block.Data,
+			block.Nonce,
+			keys.Password,
+			block.Salt,
+			ScryptProfile(block.Profile),
+			Cipher(block.Cipher),
+			BlockAADFor(block),
+		)
+	case BlockFormatEnvelope:
+		return OpenEnvelope(keys, block.Data, BlockAADFor(block))
+	default:
+		return nil, fmt.Errorf("unknown block format %d", block.Format)
+	}
+}
+
+// BlockNeedsPassword reports whether a password besides the vault key is
+// required to open the block.
+func BlockNeedsPassword(block *model.Block) bool {
+	if block.Format < BlockFormatEnvelope {
+		return true
+	}
+
+	e, err := ParseEnvelope(block.Data)
+	if err != nil {
+		// let opening report the error
+		return false
+	}
+
+	return e.NeedsPassword()
+}
+
+// BlockUsesVaultKey reports whether the block is sealed in the current
+// format with a key derived from the vault key.
+func BlockUsesVaultKey(block *model.Block) bool {
+	if block.Format != BlockFormatCurrent {
+		return false
+	}
+
+	e, err := ParseEnvelope(block.Data)
+	if err != nil {
+		return false
+	}
+
+	return e.NeedsVaultKey()
+}
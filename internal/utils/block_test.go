@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/model"
+)
+
+// TestOpenBlockNeedsMetadata checks that an envelope opens only with the
+// metadata it was sealed with, so a copy of the envelope alone isn't enough.
+func TestOpenBlockNeedsMetadata(t *testing.T) {
+	keys := EnvelopeKeys{VaultKey: bytes.Repeat([]byte{1}, 32)}
+	payload := []byte("payload")
+
+	newBlock := func() *model.Block {
+		return &model.Block{
+			UID:    "5f0c7a52-6d8e-4a47-9d1b-0b8f5e3c2a11",
+			UserID: 1,
+			Type:   &model.Type{TypeName: string(model.TypeNameText)},
+			Title:  "title",
+		}
+	}
+	sealed := newBlock()
+	if err := SealBlock(sealed, keys, payload, ProfileArgon2idLow, CipherAES256GCM); err != nil {
+		t.Fatalf("SealBlock: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(b *model.Block)
+		wantErr bool
+	}{
+		{name: "same metadata", change: func(*model.Block) {}},
+		{name: "other UID", change: func(b *model.Block) { b.UID = "other" }, wantErr: true},
+		{name: "other owner", change: func(b *model.Block) { b.UserID = 2 }, wantErr: true},
+		{name: "other type", change: func(b *model.Block) { b.Type.TypeName = string(model.TypeNameFile) }, wantErr: true},
+		{name: "other title", change: func(b *model.Block) { b.Title = "other" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBlock()
+			b.Format = sealed.Format
+			b.Data = sealed.Data
+			tt.change(b)
+
+			got, err := OpenBlock(b, keys)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("OpenBlock succeeded with changed metadata")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenBlock: %v", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("OpenBlock = %q, want %q", got, payload)
+			}
+		})
+	}
+
+	if _, err := OpenEnvelope(keys, sealed.Data, nil); err == nil {
+		t.Error("OpenEnvelope succeeded without associated data")
+	}
+}
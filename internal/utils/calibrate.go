@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// PasswordProfiles are offered for block passwords from the weakest to the
+// strongest, vault keyed envelopes with a password require argon2id.
+var PasswordProfiles = []ScryptProfile{
+	ProfileArgon2idLow,
+	ProfileArgon2idMedium,
+	ProfileArgon2idHigh,
+}
+
+// calibrationRuns is the number of derivations per profile, the fastest
+// one is taken to smooth out scheduling noise.
+const calibrationRuns = 2
+
+// ProfileTiming is key derivation time of a profile on this machine.
+type ProfileTiming struct {
+	Profile  ScryptProfile
+	Duration time.Duration
+}
+
+// CalibrateProfile benchmarks password profiles and returns the strongest
+// one deriving a key within target, along with measured timings. Profiles
+// are measured from the weakest and stronger ones are skipped once target
+// is exceeded. The weakest profile is returned if none fits.
+func CalibrateProfile(target time.Duration) (ScryptProfile, []ProfileTiming, error) {
+	password := make([]byte, 16)
+	salt := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		return "", nil, err
+	}
+	if _, err := rand.Read(salt); err != nil {
+		return "", nil, err
+	}
+
+	best := PasswordProfiles[0]
+	timings := make([]ProfileTiming, 0, len(PasswordProfiles))
+	for _, profile := range PasswordProfiles {
+		var fastest time.Duration
+		for i := range calibrationRuns {
+			start := time.Now()
+			if _, err := deriveKey(password, salt, ScryptProfiles[profile]); err != nil {
+				return "", nil, err
+			}
+			if elapsed := time.Since(start); i == 0 || elapsed < fastest {
+				fastest = elapsed
+			}
+		}
+
+		timings = append(timings, ProfileTiming{Profile: profile, Duration: fastest})
+		if fastest > target {
+			break
+		}
+		best = profile
+	}
+
+	return best, timings, nil
+}
@@ -4,8 +4,12 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"fmt"
 
 	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/scrypt"
 )
 
@@ -14,10 +18,20 @@ type ExtractedKey struct {
 	Salt []byte
 }
 
+const (
+	AlgoScrypt   = "scrypt"
+	AlgoArgon2id = "argon2id"
+)
+
+// ScryptParams are key derivation parameters of a profile, N, R and P
+// are used by scrypt and Memory (KiB), Time and Threads by argon2id.
 type ScryptParams struct {
 	N         int
 	R         int
 	P         int
+	Memory    uint32
+	Time      uint32
+	Threads   uint8
 	KeyLength int
 	Algo      string
 }
@@ -25,15 +39,60 @@ type ScryptParams struct {
 type ScryptProfile string
 
 const (
-	ProfileLow    ScryptProfile = "PROFILE_V1"
-	ProfileMedium ScryptProfile = "PROFILE_V2"
-	ProfileHigh   ScryptProfile = "PROFILE_V3"
+	ProfileLow            ScryptProfile = "PROFILE_V1"
+	ProfileMedium         ScryptProfile = "PROFILE_V2"
+	ProfileHigh           ScryptProfile = "PROFILE_V3"
+	ProfileArgon2idLow    ScryptProfile = "PROFILE_ARGON2ID_V1"
+	ProfileArgon2idMedium ScryptProfile = "PROFILE_ARGON2ID_V2"
+	ProfileArgon2idHigh   ScryptProfile = "PROFILE_ARGON2ID_V3"
 )
 
+// DefaultProfile is used for new blocks.
+const DefaultProfile = ProfileArgon2idMedium
+
 var ScryptProfiles = map[ScryptProfile]ScryptParams{
-	"PROFILE_V1": {N: 1 << 14, R: 8, P: 1, KeyLength: 32, Algo: "scrypt"},
-	"PROFILE_V2": {N: 1 << 15, R: 8, P: 1, KeyLength: 32, Algo: "scrypt"},
-	"PROFILE_V3": {N: 1 << 16, R: 8, P: 1, KeyLength: 32, Algo: "scrypt"},
+	"PROFILE_V1": {N: 1 << 14, R: 8, P: 1, KeyLength: 32, Algo: AlgoScrypt},
+	"PROFILE_V2": {N: 1 << 15, R: 8, P: 1, KeyLength: 32, Algo: AlgoScrypt},
+	"PROFILE_V3": {N: 1 << 16, R: 8, P: 1, KeyLength: 32, Algo: AlgoScrypt},
+	// OWASP minimum, RFC 9106 second recommended option and a heavier one
+	"PROFILE_ARGON2ID_V1": {Memory: 19 << 10, Time: 2, Threads: 1, KeyLength: 32, Algo: AlgoArgon2id},
+	"PROFILE_ARGON2ID_V2": {Memory: 64 << 10, Time: 3, Threads: 4, KeyLength: 32, Algo: AlgoArgon2id},
+	"PROFILE_ARGON2ID_V3": {Memory: 256 << 10, Time: 4, Threads: 4, KeyLength: 32, Algo: AlgoArgon2id},
+}
+
+// Cipher is the AEAD a block payload is sealed with, values match storage.Cipher names.
+type Cipher string
+
+const (
+	CipherAES256GCM Cipher = "CIPHER_AES_256_GCM"
+	// CipherXChaCha20Poly1305 has 192-bit nonces which are safe to pick at
+	// random for any number of blocks, it's also fast without AES-NI
+	CipherXChaCha20Poly1305 Cipher = "CIPHER_XCHACHA20_POLY1305"
+)
+
+func CipherToProto(c Cipher) storage.Cipher {
+	switch c {
+	case CipherXChaCha20Poly1305:
+		return storage.Cipher_CIPHER_XCHACHA20_POLY1305
+	default:
+		return storage.Cipher_CIPHER_AES_256_GCM
+	}
+}
+
+func newAEAD(c Cipher, key []byte) (cipher.AEAD, error) {
+	switch c {
+	case CipherAES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return cipher.NewGCM(block)
+	case CipherXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("unknown cipher %q", c)
+	}
 }
 
 func ProfileToProto(profile ScryptProfile) storage.EncProfile {
@@ -44,6 +103,12 @@ func ProfileToProto(profile ScryptProfile) storage.EncProfile {
 		return storage.EncProfile_PROFILE_V2
 	case ProfileHigh:
 		return storage.EncProfile_PROFILE_V3
+	case ProfileArgon2idLow:
+		return storage.EncProfile_PROFILE_ARGON2ID_V1
+	case ProfileArgon2idMedium:
+		return storage.EncProfile_PROFILE_ARGON2ID_V2
+	case ProfileArgon2idHigh:
+		return storage.EncProfile_PROFILE_ARGON2ID_V3
 	default:
 		return storage.EncProfile_PROFILE_V1
 	}
@@ -55,12 +120,12 @@ func ExtractKeyFromPassword(password string, profile ScryptProfile) (*ExtractedK
 		return nil, err
 	}
 
-	scryptProfile, ok := ScryptProfiles[profile]
+	params, ok := ScryptProfiles[profile]
 	if !ok {
-		scryptProfile = ScryptProfiles["PROFILE_V1"]
+		params = ScryptProfiles["PROFILE_V1"]
 	}
 
-	key, err := scrypt.Key([]byte(password), salt, scryptProfile.N, scryptProfile.R, scryptProfile.P, scryptProfile.KeyLength)
+	key, err := deriveKey([]byte(password), salt, params)
 	if err != nil {
 		return nil, err
 	}
@@ -68,17 +133,26 @@ func ExtractKeyFromPassword(password string, profile ScryptProfile) (*ExtractedK
 	return &ExtractedKey{Key: key, Salt: salt}, nil
 }
 
+// deriveKey derives encryption key from password with algorithm of the profile.
+func deriveKey(password, salt []byte, params ScryptParams) ([]byte, error) {
+	switch params.Algo {
+	case AlgoScrypt:
+		return scrypt.Key(password, salt, params.N, params.R, params.P, params.KeyLength)
+	case AlgoArgon2id:
+		return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, uint32(params.KeyLength)), nil
+	default:
+		return nil, fmt.Errorf("unknown key derivation algorithm %q", params.Algo)
+	}
+}
+
 func EncryptWithPassword(
 	passsword []byte,
 	payload []byte,
 	key *ExtractedKey,
+	c Cipher,
+	aad []byte,
 ) ([]byte, []byte, error) {
-	block, err := aes.NewCipher(key.Key)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	aead, err := cipher.NewGCM(block)
+	aead, err := newAEAD(c, key.Key)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -88,7 +162,7 @@ func EncryptWithPassword(
 		return nil, nil, err
 	}
 
-	ciphertext := aead.Seal(nil, nonce, payload, nil)
+	ciphertext := aead.Seal(nil, nonce, payload, aad)
 
 	return ciphertext, nonce, nil
 }
@@ -99,23 +173,43 @@ func DecryptWithPassword(
 	password []byte,
 	salt []byte,
 	profile ScryptProfile,
+	c Cipher,
+	aad []byte,
 ) ([]byte, error) {
-	p := ScryptProfiles[profile]
-	key, err := scrypt.Key(password, salt, p.N, p.R, p.P, p.KeyLength)
-	if err != nil {
-		return nil, err
+	return decryptWithPassword(nil, ciphertext, nonce, password, salt, profile, c, aad)
+}
+
+func decryptWithPassword(
+	cache *KeyCache,
+	ciphertext []byte,
+	nonce []byte,
+	password []byte,
+	salt []byte,
+	profile ScryptProfile,
+	c Cipher,
+	aad []byte,
+) ([]byte, error) {
+	params, ok := ScryptProfiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption profile %q", profile)
 	}
 
-	block, err := aes.NewCipher(key)
+	key, err := cache.deriveKey(password, salt, params)
 	if err != nil {
 		return nil, err
 	}
-	aead, err := cipher.NewGCM(block)
+	defer secure.Wipe(key)
+
+	aead, err := newAEAD(c, key)
 	if err != nil {
 		return nil, err
 	}
+	// Open panics on nonce of other size, e.g. of a block sealed with other cipher
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d for %s", len(nonce), c)
+	}
 
-	pt, err := aead.Open(nil, nonce, ciphertext, nil)
+	pt, err := aead.Open(nil, nonce, ciphertext, aad)
 	if err != nil {
 		return nil, err
 	}
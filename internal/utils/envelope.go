@@ -0,0 +1,382 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// Envelope layout, integers are big endian:
+//
+//	magic "GKEV" | version u8 | kdf id u8 | kdf params | cipher id u8 |
+//	salt len u8 | salt | nonce len u8 | nonce | ciphertext
+//
+// scrypt params are N u32, r u32, p u32, argon2id params are memory (KiB)
+// u32, time u32, threads u8. Vault KDF has no params, vault with a password
+// key has argon2id ones followed by key salt len u8 | key salt. Everything before the ciphertext is the
+// header, it's authenticated as associated data along with the caller's one.
+const (
+	envelopeMagic   = "GKEV"
+	EnvelopeVersion = 1
+)
+
+const (
+	kdfIDScrypt   = 1
+	kdfIDArgon2id = 2
+	// kdfIDVault derives the key from the vault key with HKDF-SHA256
+	kdfIDVault = 3
+	// kdfIDVaultPasswordKey derives it from both the vault key and a
+	// password key with HKDF over the envelope salt, neither of them alone
+	// opens the envelope. The password key is an argon2id key of the
+	// password over its own key salt, envelopes sealed in a session share
+	// the key salt, so argon2id runs once for all of them
+	kdfIDVaultPasswordKey = 4
+)
+
+const (
+	vaultKeyInfo         = "gophkeeper/vault-block-key"
+	vaultPasswordKeyInfo = "gophkeeper/vault-password-block-key"
+)
+
+const (
+	cipherIDAES256GCM         = 1
+	cipherIDXChaCha20Poly1305 = 2
+)
+
+const envelopeKeyLength = 32
+
+// saltLength is the size of envelope and password key salts.
+const saltLength = 16
+
+// Upper bounds of key derivation params accepted from an envelope, so a
+// crafted one can't make the client allocate or spin without limit.
+const (
+	maxScryptN        = 1 << 20
+	maxScryptRP       = 64
+	maxArgon2idMemory = 1 << 20
+	maxArgon2idTime   = 16
+)
+
+var (
+	ErrInvalidEnvelope  = errors.New("invalid ciphertext envelope")
+	ErrPasswordRequired = errors.New("envelope requires a password")
+	ErrVaultKeyRequired = errors.New("envelope requires the vault key")
+	ErrVaultKeyProfile  = errors.New("password of a vault keyed envelope requires an argon2id profile")
+)
+
+// EnvelopeKeys are secrets the envelope key is derived from: a password,
+// the vault key or both. Cache is optional, it keeps password keys.
+type EnvelopeKeys struct {
+	Password []byte
+	VaultKey []byte
+	Cache    *KeyCache
+}
+
+// Envelope is a parsed self-describing ciphertext, it carries KDF and
+// cipher params, salt and nonce. Keys and associated data needed for
+// decryption aren't in it, they come from the caller.
+type Envelope struct {
+	Version uint8
+	Params  ScryptParams
+	Cipher  Cipher
+	Salt    []byte
+	// KeySalt is the salt of the password key, envelopes sealed with the
+	// same password in a session share it
+	KeySalt    []byte
+	Nonce      []byte
+	Ciphertext []byte
+	kdf        uint8
+	header     []byte
+}
+
+// NeedsPassword reports whether a password is required to open the envelope.
+func (e *Envelope) NeedsPassword() bool {
+	return e.kdf != kdfIDVault
+}
+
+// NeedsVaultKey reports whether the vault key is required to open the envelope.
+func (e *Envelope) NeedsVaultKey() bool {
+	return e.kdf == kdfIDVault || e.kdf == kdfIDVaultPasswordKey
+}
+
+// IsEnvelope reports whether data starts with the envelope magic.
+func IsEnvelope(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(envelopeMagic))
+}
+
+// SealEnvelope derives a key from keys and seals payload into a new
+// envelope. Password key uses params of the profile, with the vault key
+// it's optional and the profile must be argon2id. The vault key with a
+// password reuses the key salt cached for the password, so the cached
+// password key serves every envelope of the session.
+func SealEnvelope(keys EnvelopeKeys, payload []byte, profile ScryptProfile, c Cipher, aad []byte) ([]byte, error) {
+	params, ok := ScryptProfiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption profile %q", profile)
+	}
+	params.KeyLength = envelopeKeyLength
+
+	var kdf uint8
+	switch {
+	case keys.VaultKey == nil && params.Algo == AlgoScrypt:
+		kdf = kdfIDScrypt
+	case keys.VaultKey == nil && params.Algo == AlgoArgon2id:
+		kdf = kdfIDArgon2id
+	case keys.VaultKey == nil:
+		return nil, fmt.Errorf("unknown key derivation algorithm %q", params.Algo)
+	case keys.Password == nil:
+		kdf = kdfIDVault
+	case params.Algo == AlgoArgon2id:
+		kdf = kdfIDVaultPasswordKey
+	default:
+		return nil, ErrVaultKeyProfile
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	var keySalt []byte
+	if kdf == kdfIDVaultPasswordKey {
+		var err error
+		if keySalt, err = keys.Cache.keySalt(keys.Password, params); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := envelopeKey(kdf, params, keys, salt, keySalt)
+	if err != nil {
+		return nil, err
+	}
+	defer secure.Wipe(key)
+
+	aead, err := newAEAD(c, key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header, err := envelopeHeader(kdf, params, keySalt, c, salt, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(header, nonce, payload, envelopeAAD(header, aad)), nil
+}
+
+// OpenEnvelope parses envelope and decrypts its payload with keys. aad
+// must equal the associated data it was sealed with, for blocks that's
+// BlockAAD of block metadata, which has to be kept along with a copy of
+// the envelope.
+func OpenEnvelope(keys EnvelopeKeys, envelope, aad []byte) ([]byte, error) {
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if e.NeedsPassword() && keys.Password == nil {
+		return nil, ErrPasswordRequired
+	}
+	if e.NeedsVaultKey() && keys.VaultKey == nil {
+		return nil, ErrVaultKeyRequired
+	}
+
+	key, err := envelopeKey(e.kdf, e.Params, keys, e.Salt, e.KeySalt)
+	if err != nil {
+		return nil, err
+	}
+	defer secure.Wipe(key)
+
+	aead, err := newAEAD(e.Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce size %d for %s", ErrInvalidEnvelope, len(e.Nonce), e.Cipher)
+	}
+
+	payload, err := aead.Open(nil, e.Nonce, e.Ciphertext, envelopeAAD(e.header, aad))
+	if err != nil {
+		return nil, err
+	}
+	if e.kdf == kdfIDVaultPasswordKey {
+		// envelopes sealed later reuse the already derived password key
+		keys.Cache.adoptKeySalt(keys.Password, e.Params, e.KeySalt)
+	}
+
+	return payload, nil
+}
+
+// ParseEnvelope decodes envelope header, params out of accepted bounds are
+// rejected before any key derivation.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	if !IsEnvelope(data) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidEnvelope)
+	}
+
+	r := envelopeReader{buf: data[len(envelopeMagic):]}
+	e := &Envelope{Version: r.u8()}
+	if e.Version != EnvelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, e.Version)
+	}
+
+	e.kdf = r.u8()
+	switch e.kdf {
+	case kdfIDScrypt:
+		e.Params = ScryptParams{N: int(r.u32()), R: int(r.u32()), P: int(r.u32()), Algo: AlgoScrypt}
+		if e.Params.N < 2 || e.Params.N > maxScryptN || e.Params.N&(e.Params.N-1) != 0 ||
+			e.Params.R < 1 || e.Params.R > maxScryptRP || e.Params.P < 1 || e.Params.P > maxScryptRP {
+			return nil, fmt.Errorf("%w: scrypt params out of bounds", ErrInvalidEnvelope)
+		}
+	case kdfIDVault:
+	case kdfIDArgon2id, kdfIDVaultPasswordKey:
+		e.Params = ScryptParams{Memory: r.u32(), Time: r.u32(), Threads: r.u8(), Algo: AlgoArgon2id}
+		if e.Params.Memory == 0 || e.Params.Memory > maxArgon2idMemory ||
+			e.Params.Time == 0 || e.Params.Time > maxArgon2idTime || e.Params.Threads == 0 {
+			return nil, fmt.Errorf("%w: argon2id params out of bounds", ErrInvalidEnvelope)
+		}
+		if e.kdf == kdfIDVaultPasswordKey {
+			e.KeySalt = r.bytes(int(r.u8()))
+			if !r.err && len(e.KeySalt) == 0 {
+				return nil, fmt.Errorf("%w: empty key salt", ErrInvalidEnvelope)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown kdf %d", ErrInvalidEnvelope, e.kdf)
+	}
+	e.Params.KeyLength = envelopeKeyLength
+
+	switch id := r.u8(); id {
+	case cipherIDAES256GCM:
+		e.Cipher = CipherAES256GCM
+	case cipherIDXChaCha20Poly1305:
+		e.Cipher = CipherXChaCha20Poly1305
+	default:
+		return nil, fmt.Errorf("%w: unknown cipher %d", ErrInvalidEnvelope, id)
+	}
+
+	e.Salt = r.bytes(int(r.u8()))
+	e.Nonce = r.bytes(int(r.u8()))
+	if r.err {
+		return nil, fmt.Errorf("%w: truncated header", ErrInvalidEnvelope)
+	}
+
+	headerLen := len(data) - len(r.buf)
+	e.header = data[:headerLen:headerLen]
+	e.Ciphertext = r.buf
+
+	return e, nil
+}
+
+// envelopeKey derives the envelope key, vault keyed ones are bound to the
+// salt, so every envelope gets its own key. keySalt is the password key
+// salt of kdfIDVaultPasswordKey.
+func envelopeKey(kdf uint8, params ScryptParams, keys EnvelopeKeys, salt, keySalt []byte) ([]byte, error) {
+	switch kdf {
+	case kdfIDScrypt, kdfIDArgon2id:
+		return keys.Cache.deriveKey(keys.Password, salt, params)
+	case kdfIDVault:
+		return hkdf.Key(sha256.New, keys.VaultKey, salt, vaultKeyInfo, envelopeKeyLength)
+	case kdfIDVaultPasswordKey:
+		passwordKey, err := keys.Cache.deriveKey(keys.Password, keySalt, params)
+		if err != nil {
+			return nil, err
+		}
+		defer secure.Wipe(passwordKey)
+
+		secret := secure.New(len(keys.VaultKey) + len(passwordKey))
+		defer secret.Destroy()
+		n := copy(secret.Bytes(), keys.VaultKey)
+		copy(secret.Bytes()[n:], passwordKey)
+
+		return hkdf.Key(sha256.New, secret.Bytes(), salt, vaultPasswordKeyInfo, envelopeKeyLength)
+	default:
+		return nil, fmt.Errorf("unknown kdf %d", kdf)
+	}
+}
+
+func envelopeHeader(kdf uint8, params ScryptParams, keySalt []byte, c Cipher, salt, nonce []byte) ([]byte, error) {
+	buf := []byte(envelopeMagic)
+	buf = append(buf, EnvelopeVersion, kdf)
+
+	switch kdf {
+	case kdfIDScrypt:
+		buf = binary.BigEndian.AppendUint32(buf, uint32(params.N))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(params.R))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(params.P))
+	case kdfIDArgon2id:
+		buf = binary.BigEndian.AppendUint32(buf, params.Memory)
+		buf = binary.BigEndian.AppendUint32(buf, params.Time)
+		buf = append(buf, params.Threads)
+	case kdfIDVaultPasswordKey:
+		buf = binary.BigEndian.AppendUint32(buf, params.Memory)
+		buf = binary.BigEndian.AppendUint32(buf, params.Time)
+		buf = append(buf, params.Threads, byte(len(keySalt)))
+		buf = append(buf, keySalt...)
+	}
+
+	switch c {
+	case CipherAES256GCM:
+		buf = append(buf, cipherIDAES256GCM)
+	case CipherXChaCha20Poly1305:
+		buf = append(buf, cipherIDXChaCha20Poly1305)
+	default:
+		return nil, fmt.Errorf("unknown cipher %q", c)
+	}
+
+	buf = append(buf, byte(len(salt)))
+	buf = append(buf, salt...)
+	buf = append(buf, byte(len(nonce)))
+	buf = append(buf, nonce...)
+
+	return buf, nil
+}
+
+func envelopeAAD(header, aad []byte) []byte {
+	return append(append(make([]byte, 0, len(header)+len(aad)), header...), aad...)
+}
+
+// envelopeReader reads header fields, reads past the end set err and
+// return zero values, so it's checked once after the header.
+type envelopeReader struct {
+	buf []byte
+	err bool
+}
+
+func (r *envelopeReader) bytes(n int) []byte {
+	if r.err || len(r.buf) < n {
+		r.err = true
+		return nil
+	}
+	b := r.buf[:n:n]
+	r.buf = r.buf[n:]
+
+	return b
+}
+
+func (r *envelopeReader) u8() uint8 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
+}
+
+func (r *envelopeReader) u32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(b)
+}
@@ -39,3 +39,14 @@ func CheckJWTToken(tokenString string, secret []byte) (*MyClaims, error) {
 
 	return claims, nil
 }
+
+// TokenUserID reads the user ID claim without verifying the signature, the
+// client has no secret and only uses it to bind blocks to their owner.
+func TokenUserID(tokenString string) (int, error) {
+	var claims MyClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims); err != nil {
+		return 0, err
+	}
+
+	return claims.UserID, nil
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// KeyCache keeps password derived keys in memory, so opening or sealing
+// with the same password, salt and params again doesn't re-run the KDF.
+// Keys are held in secure buffers, idle for ttl ones are destroyed. Entries are looked up by an
+// HMAC under a random per-cache secret, passwords aren't kept. A nil cache
+// derives every key.
+//
+// Password keys of vault keyed envelopes don't depend on the envelope salt,
+// they're derived over a key salt kept per password until Purge, so every
+// envelope sealed with a password in a session shares one cached key.
+type KeyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	secret  []byte
+	entries map[[sha256.Size]byte]*cachedKey
+	salts   map[[sha256.Size]byte][]byte
+	timer   *time.Timer
+}
+
+type cachedKey struct {
+	key  *secure.Buffer
+	used time.Time
+}
+
+// NewKeyCache returns a cache dropping keys idle for ttl, it's nil (no
+// caching) if ttl isn't positive or the secret can't be generated.
+func NewKeyCache(ttl time.Duration) *KeyCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil
+	}
+
+	return &KeyCache{
+		ttl:     ttl,
+		secret:  secret,
+		entries: make(map[[sha256.Size]byte]*cachedKey),
+		salts:   make(map[[sha256.Size]byte][]byte),
+	}
+}
+
+// Len returns the number of cached keys.
+func (c *KeyCache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Purge destroys every cached key and forgets key salts.
+func (c *KeyCache) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, e := range c.entries {
+		e.key.Destroy()
+		delete(c.entries, id)
+	}
+	clear(c.salts)
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
+// deriveKey returns a copy of cached key of password, salt and params or
+// derives and caches it, callers wipe the copy. The lock isn't held during
+// derivation.
+func (c *KeyCache) deriveKey(password, salt []byte, params ScryptParams) ([]byte, error) {
+	if c == nil {
+		return deriveKey(password, salt, params)
+	}
+
+	id := c.entryID(entryKindKey, password, salt, params)
+
+	c.mu.Lock()
+	if e, ok := c.entries[id]; ok {
+		e.used = time.Now()
+		key := slices.Clone(e.key.Bytes())
+		c.mu.Unlock()
+
+		return key, nil
+	}
+	c.mu.Unlock()
+
+	key, err := deriveKey(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[id]; !ok {
+		buf := secure.New(len(key))
+		copy(buf.Bytes(), key)
+		c.entries[id] = &cachedKey{key: buf, used: time.Now()}
+	}
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.ttl, c.expire)
+	}
+
+	return key, nil
+}
+
+// keySalt returns the password key salt of password and params, it's
+// generated on first use and kept until Purge. A nil cache generates a new
+// one every time.
+func (c *KeyCache) keySalt(password []byte, params ScryptParams) ([]byte, error) {
+	if c == nil {
+		return newSalt()
+	}
+
+	id := c.entryID(entryKindSalt, password, nil, params)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if salt, ok := c.salts[id]; ok {
+		return salt, nil
+	}
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	c.salts[id] = salt
+
+	return salt, nil
+}
+
+// adoptKeySalt makes salt the password key salt of password and params,
+// unless the session has one already.
+func (c *KeyCache) adoptKeySalt(password []byte, params ScryptParams, salt []byte) {
+	if c == nil {
+		return
+	}
+
+	id := c.entryID(entryKindSalt, password, nil, params)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.salts[id]; !ok {
+		c.salts[id] = slices.Clone(salt)
+	}
+}
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+// expire drops idle keys and re-arms the timer for the next one to expire.
+func (c *KeyCache) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timer = nil
+	now := time.Now()
+	var next time.Duration
+	for id, e := range c.entries {
+		left := c.ttl - now.Sub(e.used)
+		if left <= 0 {
+			e.key.Destroy()
+			delete(c.entries, id)
+
+			continue
+		}
+		if next == 0 || left < next {
+			next = left
+		}
+	}
+	if next > 0 {
+		c.timer = time.AfterFunc(next, c.expire)
+	}
+}
+
+// Entry kinds keep key and key salt IDs of the same password apart.
+const (
+	entryKindKey  byte = 'k'
+	entryKindSalt byte = 's'
+)
+
+func (c *KeyCache) entryID(kind byte, password, salt []byte, params ScryptParams) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte{kind})
+	mac.Write([]byte(params.Algo))
+	for _, v := range []uint32{
+		uint32(params.N), uint32(params.R), uint32(params.P),
+		params.Memory, params.Time, uint32(params.Threads), uint32(params.KeyLength),
+		uint32(len(salt)),
+	} {
+		mac.Write(binary.BigEndian.AppendUint32(nil, v))
+	}
+	mac.Write(salt)
+	mac.Write(password)
+
+	var id [sha256.Size]byte
+	mac.Sum(id[:0])
+
+	return id
+}
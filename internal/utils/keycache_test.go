@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestKeyCacheSharesPasswordKey seals several envelopes with the same
+// password, the password key must be derived once for all of them.
+func TestKeyCacheSharesPasswordKey(t *testing.T) {
+	cache := NewKeyCache(time.Minute)
+	t.Cleanup(cache.Purge)
+	keys := EnvelopeKeys{
+		VaultKey: bytes.Repeat([]byte{1}, 32),
+		Password: []byte("block password"),
+		Cache:    cache,
+	}
+
+	var envelopes [][]byte
+	for i := range 3 {
+		payload := fmt.Appendf(nil, "payload %d", i)
+		envelope, err := SealEnvelope(keys, payload, ProfileArgon2idLow, CipherAES256GCM, nil)
+		if err != nil {
+			t.Fatalf("SealEnvelope: %v", err)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	if n := cache.Len(); n != 1 {
+		t.Errorf("cache has %d keys after sealing, want 1", n)
+	}
+
+	for i, envelope := range envelopes {
+		want := fmt.Appendf(nil, "payload %d", i)
+		// a fresh session derives the key once too
+		for _, cache := range []*KeyCache{cache, nil} {
+			keys := keys
+			keys.Cache = cache
+			got, err := OpenEnvelope(keys, envelope, nil)
+			if err != nil {
+				t.Fatalf("OpenEnvelope: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("OpenEnvelope = %q, want %q", got, want)
+			}
+		}
+	}
+	if n := cache.Len(); n != 1 {
+		t.Errorf("cache has %d keys after opening, want 1", n)
+	}
+
+	cache.Purge()
+	if _, err := OpenEnvelope(keys, envelopes[0], nil); err != nil {
+		t.Fatalf("OpenEnvelope after purge: %v", err)
+	}
+	// the opened envelope's key salt is reused, its key is cached already
+	if _, err := SealEnvelope(keys, []byte("payload"), ProfileArgon2idLow, CipherAES256GCM, nil); err != nil {
+		t.Fatalf("SealEnvelope after purge: %v", err)
+	}
+	if n := cache.Len(); n != 1 {
+		t.Errorf("cache has %d keys after purge, want 1", n)
+	}
+}
+
+func TestVaultPasswordKeyNeedsBoth(t *testing.T) {
+	keys := EnvelopeKeys{
+		VaultKey: bytes.Repeat([]byte{1}, 32),
+		Password: []byte("block password"),
+	}
+	envelope, err := SealEnvelope(keys, []byte("payload"), ProfileArgon2idLow, CipherXChaCha20Poly1305, nil)
+	if err != nil {
+		t.Fatalf("SealEnvelope: %v", err)
+	}
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("ParseEnvelope: %v", err)
+	}
+	if !e.NeedsVaultKey() || !e.NeedsPassword() || len(e.KeySalt) == 0 {
+		t.Fatal("envelope doesn't need both the vault key and the password")
+	}
+
+	tests := []struct {
+		name    string
+		keys    EnvelopeKeys
+		wantErr error
+	}{
+		{name: "vault key only", keys: EnvelopeKeys{VaultKey: keys.VaultKey}, wantErr: ErrPasswordRequired},
+		{name: "password only", keys: EnvelopeKeys{Password: keys.Password}, wantErr: ErrVaultKeyRequired},
+		{name: "wrong password", keys: EnvelopeKeys{VaultKey: keys.VaultKey, Password: []byte("other")}},
+		{name: "wrong vault key", keys: EnvelopeKeys{VaultKey: bytes.Repeat([]byte{2}, 32), Password: keys.Password}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OpenEnvelope(tt.keys, envelope, nil)
+			if err == nil {
+				t.Fatal("OpenEnvelope succeeded")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("OpenEnvelope: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestParseEnvelopeUnknownKDF checks that only the listed KDF ids are
+// accepted.
+func TestParseEnvelopeUnknownKDF(t *testing.T) {
+	keys := EnvelopeKeys{
+		VaultKey: bytes.Repeat([]byte{1}, 32),
+		Password: []byte("block password"),
+	}
+	envelope, err := SealEnvelope(keys, []byte("payload"), ProfileArgon2idLow, CipherAES256GCM, nil)
+	if err != nil {
+		t.Fatalf("SealEnvelope: %v", err)
+	}
+
+	// the kdf id follows magic and version
+	kdfOffset := len(envelopeMagic) + 1
+	if envelope[kdfOffset] != kdfIDVaultPasswordKey {
+		t.Fatalf("envelope kdf is %d, want %d", envelope[kdfOffset], kdfIDVaultPasswordKey)
+	}
+	for _, kdf := range []byte{0, kdfIDVaultPasswordKey + 1} {
+		tampered := bytes.Clone(envelope)
+		tampered[kdfOffset] = kdf
+		if _, err := ParseEnvelope(tampered); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("ParseEnvelope with kdf %d: got %v, want %v", kdf, err, ErrInvalidEnvelope)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// RecoverySharePrefix starts the text of a vault key recovery share.
+const RecoverySharePrefix = "GKRS1"
+
+const (
+	recoveryShareVersion = 1
+	recoveryCheckDomain  = "gophkeeper/vault-recovery-check"
+	recoveryGroupSize    = 5
+	// version, user ID, set ID, threshold, index, check value, share value
+	// and CRC32
+	recoveryShareSize = 1 + 4 + 4 + 1 + 1 + 4 + VaultKeySize + 4
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryShare is a Shamir share of a user's vault key. Shares of one
+// split have the same set ID, the check value verifies the reconstructed
+// key.
+type RecoveryShare struct {
+	UserID    int
+	SetID     [4]byte
+	Threshold int
+	Index     int
+	Check     [4]byte
+	Value     []byte
+}
+
+// SplitVaultKey splits the vault key into n recovery shares, any k of them
+// recover it. Shares are returned as printable text.
+func SplitVaultKey(vaultKey []byte, userID, n, k int) ([]string, error) {
+	if len(vaultKey) != VaultKeySize {
+		return nil, fmt.Errorf("invalid vault key size %d", len(vaultKey))
+	}
+
+	values, err := SplitSecret(vaultKey, n, k)
+	if err != nil {
+		return nil, err
+	}
+
+	var setID [4]byte
+	if _, err := rand.Read(setID[:]); err != nil {
+		return nil, err
+	}
+	check := recoveryCheck(vaultKey, userID, setID)
+
+	texts := make([]string, len(values))
+	for i, v := range values {
+		share := RecoveryShare{
+			UserID:    userID,
+			SetID:     setID,
+			Threshold: k,
+			Index:     int(v[0]),
+			Check:     check,
+			Value:     v[1:],
+		}
+		texts[i] = share.String()
+		secure.Wipe(v)
+	}
+
+	return texts, nil
+}
+
+// String encodes the share as prefixed base32 in dash separated groups.
+func (s *RecoveryShare) String() string {
+	buf := make([]byte, 0, recoveryShareSize)
+	buf = append(buf, recoveryShareVersion)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(s.UserID))
+	buf = append(buf, s.SetID[:]...)
+	buf = append(buf, byte(s.Threshold), byte(s.Index))
+	buf = append(buf, s.Check[:]...)
+	buf = append(buf, s.Value...)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+
+	encoded := recoveryEncoding.EncodeToString(buf)
+	secure.Wipe(buf)
+
+	var b strings.Builder
+	b.WriteString(RecoverySharePrefix)
+	for i := 0; i < len(encoded); i += recoveryGroupSize {
+		b.WriteByte('-')
+		b.WriteString(encoded[i:min(i+recoveryGroupSize, len(encoded))])
+	}
+
+	return b.String()
+}
+
+// ParseRecoveryShare decodes a share text, case, spaces and dashes are
+// ignored. A mistyped share fails its checksum.
+func ParseRecoveryShare(text string) (*RecoveryShare, error) {
+	text = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', '-':
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(text))
+
+	encoded, ok := strings.CutPrefix(text, RecoverySharePrefix)
+	if !ok {
+		return nil, fmt.Errorf("not a recovery share")
+	}
+
+	buf, err := recoveryEncoding.DecodeString(encoded)
+	if err != nil || len(buf) != recoveryShareSize {
+		return nil, fmt.Errorf("malformed recovery share")
+	}
+
+	body, sum := buf[:len(buf)-4], binary.BigEndian.Uint32(buf[len(buf)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, fmt.Errorf("recovery share checksum mismatch, check it for typos")
+	}
+	if body[0] != recoveryShareVersion {
+		return nil, fmt.Errorf("unsupported recovery share version %d", body[0])
+	}
+
+	s := &RecoveryShare{
+		UserID:    int(binary.BigEndian.Uint32(body[1:5])),
+		Threshold: int(body[9]),
+		Index:     int(body[10]),
+		Value:     body[15:],
+	}
+	copy(s.SetID[:], body[5:9])
+	copy(s.Check[:], body[11:15])
+	if s.Index == 0 || s.Threshold < 2 {
+		return nil, fmt.Errorf("malformed recovery share")
+	}
+
+	return s, nil
+}
+
+// FindRecoveryShare returns the first share text in s, e.g. of an exported
+// share file.
+func FindRecoveryShare(s string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(strings.ToUpper(line), RecoverySharePrefix) {
+			return line, true
+		}
+	}
+
+	return "", false
+}
+
+// RecoverVaultKey reconstructs the vault key of the user from shares of
+// one split, at least its threshold of them. The key is verified with the
+// check value of the shares.
+func RecoverVaultKey(shares []*RecoveryShare, userID int) (*secure.Buffer, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no recovery shares")
+	}
+
+	first := shares[0]
+	values := make([][]byte, len(shares))
+	defer func() {
+		for _, v := range values {
+			secure.Wipe(v)
+		}
+	}()
+
+	for i, s := range shares {
+		if s.UserID != userID {
+			return nil, fmt.Errorf("recovery share %d belongs to another user", s.Index)
+		}
+		if s.SetID != first.SetID || s.Threshold != first.Threshold || s.Check != first.Check {
+			return nil, fmt.Errorf("recovery share %d is of another split", s.Index)
+		}
+		values[i] = append([]byte{byte(s.Index)}, s.Value...)
+	}
+
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%d recovery shares are required, got %d", first.Threshold, len(shares))
+	}
+
+	key, err := CombineShares(values)
+	if err != nil {
+		return nil, err
+	}
+
+	check := recoveryCheck(key.Bytes(), userID, first.SetID)
+	if !hmac.Equal(check[:], first.Check[:]) {
+		key.Destroy()
+
+		return nil, fmt.Errorf("recovered vault key doesn't match its check value")
+	}
+
+	return key, nil
+}
+
+func recoveryCheck(vaultKey []byte, userID int, setID [4]byte) [4]byte {
+	mac := hmac.New(sha256.New, vaultKey)
+	mac.Write([]byte(recoveryCheckDomain + "/" + strconv.Itoa(userID) + "/"))
+	mac.Write(setID[:])
+
+	var check [4]byte
+	copy(check[:], mac.Sum(nil))
+
+	return check
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// MaxShares is the maximum number of shares of a secret, share x
+// coordinates are non-zero bytes.
+const MaxShares = 255
+
+// SplitSecret splits secret into n shares with Shamir's scheme over
+// GF(2^8), any k of them reconstruct it and fewer reveal nothing. A share
+// is its x coordinate followed by a polynomial value per secret byte.
+func SplitSecret(secret []byte, n, k int) ([][]byte, error) {
+	if k < 2 || n < k || n > MaxShares {
+		return nil, fmt.Errorf("invalid shares %d of %d, need 2 <= threshold <= shares <= %d", k, n, MaxShares)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("empty secret")
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	// coefficients of a random polynomial per secret byte, the constant
+	// term is the secret byte itself
+	coeffs := secure.New(k)
+	defer coeffs.Destroy()
+
+	poly := coeffs.Bytes()
+	for b, s := range secret {
+		if _, err := rand.Read(poly[1:]); err != nil {
+			return nil, err
+		}
+		poly[0] = s
+		for _, share := range shares {
+			share[b+1] = gfEval(poly, share[0])
+		}
+	}
+
+	return shares, nil
+}
+
+// CombineShares reconstructs a secret from at least threshold shares made
+// by SplitSecret. Fewer shares give a wrong secret rather than an error,
+// callers check the result.
+func CombineShares(shares [][]byte) (*secure.Buffer, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("at least 2 shares are required")
+	}
+
+	size := len(shares[0])
+	seen := make(map[byte]struct{}, len(shares))
+	for _, share := range shares {
+		if len(share) < 2 || len(share) != size {
+			return nil, fmt.Errorf("shares of different secrets")
+		}
+		if share[0] == 0 {
+			return nil, fmt.Errorf("invalid share index 0")
+		}
+		if _, ok := seen[share[0]]; ok {
+			return nil, fmt.Errorf("duplicate share %d", share[0])
+		}
+		seen[share[0]] = struct{}{}
+	}
+
+	// Lagrange basis polynomials at x = 0
+	basis := make([]byte, len(shares))
+	for i, si := range shares {
+		num, den := byte(1), byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			num = gfMul(num, sj[0])
+			den = gfMul(den, sj[0]^si[0])
+		}
+		basis[i] = gfMul(num, gfInv(den))
+	}
+
+	secret := secure.New(size - 1)
+	out := secret.Bytes()
+	for b := range out {
+		var v byte
+		for i, share := range shares {
+			v ^= gfMul(share[b+1], basis[i])
+		}
+		out[b] = v
+	}
+
+	return secret, nil
+}
+
+// gfEval evaluates polynomial with coefficients from the constant term up
+// at x with Horner's method.
+func gfEval(poly []byte, x byte) byte {
+	var v byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		v = gfMul(v, x) ^ poly[i]
+	}
+
+	return v
+}
+
+// gfMul multiplies in GF(2^8) modulo the AES polynomial without lookups
+// or branches on secret data.
+func gfMul(a, b byte) byte {
+	var p byte
+	for range 8 {
+		p ^= a & -(b & 1)
+		hi := a >> 7
+		a = a<<1 ^ 0x1b&-hi
+		b >>= 1
+	}
+
+	return p
+}
+
+// gfInv returns multiplicative inverse of a non-zero a, which is a^254.
+func gfInv(a byte) byte {
+	r := a
+	for range 6 {
+		a = gfMul(a, a)
+		r = gfMul(r, a)
+	}
+
+	return gfMul(r, r)
+}
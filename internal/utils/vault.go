@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secure"
+)
+
+// VaultKeySize is the size of a user's vault data key, block keys are
+// derived from it.
+const VaultKeySize = 32
+
+const vaultKeyDomain = "gophkeeper/vault-key"
+
+// NewVaultKey generates a random vault data key in a secure buffer.
+func NewVaultKey() (*secure.Buffer, error) {
+	key := secure.New(VaultKeySize)
+	if _, err := rand.Read(key.Bytes()); err != nil {
+		key.Destroy()
+
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// WrapVaultKey seals the vault key with a key derived from the master
+// password, the wrapped key is bound to its owner.
+func WrapVaultKey(vaultKey, masterPassword []byte, userID int, profile ScryptProfile, c Cipher) ([]byte, error) {
+	return SealEnvelope(EnvelopeKeys{Password: masterPassword}, vaultKey, profile, c, vaultKeyAAD(userID))
+}
+
+// UnwrapVaultKey opens the wrapped vault key with the master password
+// into a secure buffer.
+func UnwrapVaultKey(wrapped, masterPassword []byte, userID int) (*secure.Buffer, error) {
+	key, err := OpenEnvelope(EnvelopeKeys{Password: masterPassword}, wrapped, vaultKeyAAD(userID))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != VaultKeySize {
+		secure.Wipe(key)
+
+		return nil, fmt.Errorf("invalid vault key size %d", len(key))
+	}
+
+	return secure.FromBytes(key), nil
+}
+
+func vaultKeyAAD(userID int) []byte {
+	return []byte(vaultKeyDomain + "/" + strconv.Itoa(userID))
+}
@@ -0,0 +1,9 @@
+// Package migrations embeds database schema migrations, every engine
+// has own directory with the same versions. A migration an engine has no
+// use for (like row-level security on SQLite) is an empty one there.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,73 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/migrations"
+)
+
+// TestEnginesHaveSameVersions checks that a version means the same
+// migration on every engine, so goto and force targets don't depend on it.
+func TestEnginesHaveSameVersions(t *testing.T) {
+	postgres, err := fs.Glob(migrations.FS, "postgres/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlite, err := fs.Glob(migrations.FS, "sqlite/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := func(paths []string) []string {
+		names := make([]string, len(paths))
+		for i, p := range paths {
+			names[i] = filepath.Base(p)
+		}
+
+		return names
+	}
+	if p, s := base(postgres), base(sqlite); !slices.Equal(p, s) {
+		t.Errorf("migrations differ:\npostgres: %v\nsqlite:   %v", p, s)
+	}
+}
+
+// TestSQLiteUpDown applies, rolls back and re-applies SQLite migrations.
+func TestSQLiteUpDown(t *testing.T) {
+	conf, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("config defaults: %v", err)
+	}
+	conf.Database.Engine = config.DatabaseEngineSQLite
+	conf.Database.Path = filepath.Join(t.TempDir(), "gophkeeper.db")
+
+	db, err := database.NewSQLiteDriver(&conf.Database)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Conn.Close() })
+
+	migrator, err := database.NewMigrator(config.DatabaseEngineSQLite, db.Conn)
+	if err != nil {
+		t.Fatalf("create migrator: %v", err)
+	}
+	for _, step := range []struct {
+		name string
+		run  func() error
+	}{
+		{"up", migrator.Up},
+		{"down", migrator.Down},
+		{"up again", migrator.Up},
+	} {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+	if err := migrator.Check(); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+DROP POLICY IF EXISTS blocks_owner ON blocks;
+
+ALTER TABLE blocks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE blocks DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,12 @@
+-- Blocks are visible only to the user set by the repository for the current
+-- transaction: SELECT set_config('app.user_id', '<id>', true).
+-- Without the setting the policy matches no rows, so a query missing
+-- its user_id filter returns nothing instead of other users' data.
+-- FORCE applies the policy to the table owner too; superusers and roles
+-- with BYPASSRLS are still not restricted.
+ALTER TABLE blocks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE blocks FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY blocks_owner ON blocks
+  USING (user_id = NULLIF(current_setting('app.user_id', true), '')::int)
+  WITH CHECK (user_id = NULLIF(current_setting('app.user_id', true), '')::int);
@@ -0,0 +1 @@
+ALTER TABLE blocks DROP COLUMN IF EXISTS cipher;
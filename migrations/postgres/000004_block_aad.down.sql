@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_blocks_uid;
+
+ALTER TABLE blocks DROP COLUMN IF EXISTS format;
+ALTER TABLE blocks DROP COLUMN IF EXISTS uid;
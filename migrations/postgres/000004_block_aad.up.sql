@@ -0,0 +1,8 @@
+-- uid identifies a block in encryption associated data, it's generated by
+-- clients for new blocks. format 0 marks blocks sealed without associated
+-- data, clients upgrade them to format 1 once decrypted.
+ALTER TABLE blocks ADD COLUMN uid VARCHAR(64) NOT NULL DEFAULT gen_random_uuid()::text;
+ALTER TABLE blocks ALTER COLUMN uid DROP DEFAULT;
+ALTER TABLE blocks ADD COLUMN format SMALLINT NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocks_uid ON blocks(uid);
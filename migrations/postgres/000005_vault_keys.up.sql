@@ -0,0 +1,15 @@
+-- wrapped_key is the user's vault data key sealed by the client with a key
+-- derived from the master password, the server never sees it unwrapped.
+CREATE TABLE IF NOT EXISTS vault_keys (
+  user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  wrapped_key BYTEA NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE vault_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE vault_keys FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY vault_keys_owner ON vault_keys
+  USING (user_id = NULLIF(current_setting('app.user_id', true), '')::int)
+  WITH CHECK (user_id = NULLIF(current_setting('app.user_id', true), '')::int);
@@ -0,0 +1,5 @@
+UPDATE blocks SET salt = '' WHERE salt IS NULL;
+UPDATE blocks SET nonce = '' WHERE nonce IS NULL;
+
+ALTER TABLE blocks ALTER COLUMN salt SET NOT NULL;
+ALTER TABLE blocks ALTER COLUMN nonce SET NOT NULL;
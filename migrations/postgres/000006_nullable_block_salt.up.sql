@@ -0,0 +1,4 @@
+-- envelope blocks (format 2) keep salt and nonce in the envelope itself,
+-- the columns are NULL for them.
+ALTER TABLE blocks ALTER COLUMN salt DROP NOT NULL;
+ALTER TABLE blocks ALTER COLUMN nonce DROP NOT NULL;
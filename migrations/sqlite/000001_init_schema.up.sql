@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  username TEXT UNIQUE NOT NULL,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE block_types (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  type_name TEXT NOT NULL,
+  description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS blocks (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  type_id INTEGER NOT NULL REFERENCES block_types(id),
+  data BLOB NOT NULL,
+  salt BLOB NOT NULL,
+  nonce BLOB NOT NULL,
+  profile TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blocks_user_id ON blocks(user_id);
+
+INSERT INTO block_types (type_name, description) VALUES
+  ('text', 'Raw text data block'),
+  ('file', 'File data block'),
+  ('credentials', 'Credentials data block'),
+  ('bank_card', 'Bank card data block');
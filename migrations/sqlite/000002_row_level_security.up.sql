@@ -0,0 +1,3 @@
+-- SQLite has no row-level security, the migration is empty so versions
+-- stay the same as the postgres ones. Repositories filter blocks by user.
+SELECT 1;
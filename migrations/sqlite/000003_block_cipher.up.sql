@@ -0,0 +1,2 @@
+-- existing blocks are sealed with AES-256-GCM
+ALTER TABLE blocks ADD COLUMN cipher TEXT NOT NULL DEFAULT 'CIPHER_AES_256_GCM';
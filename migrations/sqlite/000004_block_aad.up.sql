@@ -0,0 +1,8 @@
+-- uid identifies a block in encryption associated data, it's generated by
+-- clients for new blocks. format 0 marks blocks sealed without associated
+-- data, clients upgrade them to format 1 once decrypted.
+ALTER TABLE blocks ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+UPDATE blocks SET uid = lower(hex(randomblob(16))) WHERE uid = '';
+ALTER TABLE blocks ADD COLUMN format INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocks_uid ON blocks(uid);
@@ -0,0 +1,8 @@
+-- wrapped_key is the user's vault data key sealed by the client with a key
+-- derived from the master password, the server never sees it unwrapped.
+CREATE TABLE IF NOT EXISTS vault_keys (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  wrapped_key BLOB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,24 @@
+CREATE TABLE blocks_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  type_id INTEGER NOT NULL REFERENCES block_types(id),
+  data BLOB NOT NULL,
+  salt BLOB NOT NULL,
+  nonce BLOB NOT NULL,
+  profile TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  cipher TEXT NOT NULL DEFAULT 'CIPHER_AES_256_GCM',
+  uid TEXT NOT NULL DEFAULT '',
+  format INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO blocks_new (id, title, user_id, type_id, data, salt, nonce, profile, created_at, updated_at, cipher, uid, format)
+  SELECT id, title, user_id, type_id, data, coalesce(salt, X''), coalesce(nonce, X''), profile, created_at, updated_at, cipher, uid, format FROM blocks;
+
+DROP TABLE blocks;
+ALTER TABLE blocks_new RENAME TO blocks;
+
+CREATE INDEX IF NOT EXISTS idx_blocks_user_id ON blocks(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocks_uid ON blocks(uid);
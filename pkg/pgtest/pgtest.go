@@ -0,0 +1,118 @@
+// Package pgtest connects tests to the PostgreSQL database named by
+// GOPHKEEPER_TEST_DATABASE_DSN and migrates it to the latest version.
+// Tests using it are skipped when the variable isn't set. The database
+// should be a disposable one, tests create and remove their own users:
+//
+//	GOPHKEEPER_TEST_DATABASE_DSN=postgres://gophkeeper:pw@localhost:5432/gophkeeper_test go test ./...
+//
+// Row-level security is not applied to superusers and roles with
+// BYPASSRLS, so the role should be an ordinary one.
+package pgtest
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/infrastructure/database"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/secrets"
+)
+
+// DSNEnv names the environment variable with the test database DSN.
+const DSNEnv = "GOPHKEEPER_TEST_DATABASE_DSN"
+
+// Config returns database config of the test database and its password,
+// the database is migrated. tb is skipped if DSNEnv isn't set.
+func Config(tb testing.TB) (*config.Database, *secrets.Secret) {
+	tb.Helper()
+
+	dsn, ok := lookupDSN()
+	if !ok {
+		tb.Skipf("%s is not set", DSNEnv)
+	}
+
+	conf, err := config.Defaults()
+	if err != nil {
+		tb.Fatalf("config defaults: %v", err)
+	}
+	db := conf.Database
+	if err := parseDSN(dsn, &db); err != nil {
+		tb.Fatalf("parse %s: %v", DSNEnv, err)
+	}
+	password := secrets.NewSecret([]byte(db.Password))
+
+	sqlDriver, err := database.NewSQLDriver(&db, password)
+	if err != nil {
+		tb.Fatalf("connect to test database: %v", err)
+	}
+	defer sqlDriver.Conn.Close()
+
+	migrator, err := database.NewMigrator(config.DatabaseEnginePostgres, sqlDriver.Conn)
+	if err != nil {
+		tb.Fatalf("create migrator: %v", err)
+	}
+	defer migrator.Close()
+	if err := migrator.Up(); err != nil {
+		tb.Fatalf("migrate test database: %v", err)
+	}
+
+	return &db, password
+}
+
+// Pgx returns a pgx driver of the migrated test database, it's closed on
+// test cleanup.
+func Pgx(tb testing.TB) *database.PgxDriver {
+	tb.Helper()
+
+	conf, password := Config(tb)
+	db, err := database.NewPgxDriver(conf, password)
+	if err != nil {
+		tb.Fatalf("connect to test database: %v", err)
+	}
+	tb.Cleanup(db.Close)
+
+	return db
+}
+
+// BypassesRLS reports whether the role of db ignores row-level security,
+// that is a superuser or a role with BYPASSRLS.
+func BypassesRLS(ctx context.Context, db *database.PgxDriver) (bool, error) {
+	var bypass bool
+	err := db.Pool.QueryRow(
+		ctx,
+		`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user;`,
+	).Scan(&bypass)
+
+	return bypass, err
+}
+
+func lookupDSN() (string, bool) {
+	dsn := strings.TrimSpace(os.Getenv(DSNEnv))
+
+	return dsn, dsn != ""
+}
+
+func parseDSN(dsn string, conf *config.Database) error {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return err
+	}
+
+	conf.Engine = config.DatabaseEnginePostgres
+	conf.Host = u.Hostname()
+	conf.Port = 5432
+	if p := u.Port(); p != "" {
+		if conf.Port, err = strconv.Atoi(p); err != nil {
+			return err
+		}
+	}
+	conf.User = u.User.Username()
+	conf.Password, _ = u.User.Password()
+	conf.DBName = strings.TrimPrefix(u.Path, "/")
+
+	return nil
+}
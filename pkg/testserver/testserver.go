@@ -0,0 +1,172 @@
+// Package testserver runs the complete gophkeeper gRPC server in-process
+// over bufconn with in-memory repositories, so auth, storage and
+// subscription flows can be exercised end-to-end in go test without
+// a database or network ports:
+//
+//	srv := testserver.New(t)
+//	conn, err := srv.Dial()
+//	...
+//	token, err := srv.Register(ctx, "alice", "secret")
+//	ctx = testserver.WithToken(ctx, token)
+package testserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/app"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/config"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	bufSize = 1 << 20
+	// DefaultJWTSecret signs tokens of the test server unless changed with WithJWTSecret
+	DefaultJWTSecret = "testserver-jwt-secret"
+)
+
+// Option changes server configuration before it's started.
+type Option func(conf *config.Config)
+
+func WithJWTSecret(secret string) Option {
+	return func(conf *config.Config) {
+		conf.Server.JWT.SecretKey = secret
+	}
+}
+
+// WithLogLevel sets server log level, default is error to keep test output clean.
+func WithLogLevel(level string) Option {
+	return func(conf *config.Config) {
+		conf.Server.LogLevel = level
+	}
+}
+
+// WithAdminUserIDs allows users to call AdminService.
+func WithAdminUserIDs(ids ...int) Option {
+	return func(conf *config.Config) {
+		conf.Server.Admin.UserIDs = ids
+	}
+}
+
+// WithMaxStreamsPerUser overrides the limit of concurrent streams per user, 0 disables it.
+func WithMaxStreamsPerUser(n int) Option {
+	return func(conf *config.Config) {
+		conf.Server.Limits.MaxStreamsPerUser = n
+	}
+}
+
+// WithQuotas overrides per-user storage quotas.
+func WithQuotas(quotas config.Quotas) Option {
+	return func(conf *config.Config) {
+		conf.Server.Quotas = quotas
+	}
+}
+
+type Server struct {
+	app  *app.Application
+	lis  *bufconn.Listener
+	done chan error
+}
+
+// Start starts the server with default settings, in-memory repositories
+// and disabled metrics and tracing.
+func Start(opts ...Option) (*Server, error) {
+	conf, err := config.Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Database.Engine = config.DatabaseEngineMemory
+	conf.Server.JWT.SecretKey = DefaultJWTSecret
+	conf.Server.LogLevel = "error"
+	conf.Server.Metrics.Port = 0
+	conf.Server.Tracing.Exporter = config.TracingExporterNone
+	for _, opt := range opts {
+		opt(conf)
+	}
+	if err := conf.Validate(config.AppServer); err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		app:  app.New(conf),
+		lis:  bufconn.Listen(bufSize),
+		done: make(chan error, 1),
+	}
+	go func() {
+		s.done <- s.app.Serve(s.lis)
+	}()
+
+	return s, nil
+}
+
+// New starts the server and stops it on test cleanup.
+// Client connections must be closed before, e.g. with their own cleanup
+// registered after New, as stopping waits for open streams.
+func New(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+
+	s, err := Start(opts...)
+	if err != nil {
+		tb.Fatalf("failed to start test server: %v", err)
+	}
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// Dial creates client connection to the server, opts are appended
+// to the in-memory dialer and insecure credentials.
+func (s *Server) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append(
+		[]grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return s.lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+		opts...,
+	)
+
+	return grpc.NewClient("passthrough:///bufnet", opts...)
+}
+
+// Register creates a user and returns its token.
+func (s *Server) Register(ctx context.Context, username, password string) (string, error) {
+	conn, err := s.Dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	resp, err := auth.NewAuthServiceClient(conn).Register(
+		ctx,
+		auth.RegisterRequest_builder{
+			Username: proto.String(username),
+			Password: proto.String(password),
+		}.Build(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("register %s: %w", username, err)
+	}
+
+	return resp.GetToken(), nil
+}
+
+// WithToken returns context authenticating outgoing calls with token.
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", token)
+}
+
+// Close stops the server gracefully.
+func (s *Server) Close() {
+	s.app.Shudown()
+	<-s.done
+}
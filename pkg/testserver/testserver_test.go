@@ -0,0 +1,203 @@
+package testserver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/auth"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/storage"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/internal/proto/subscription"
+	"github.com/funkymotions/go-ya-practicum-gophkeeper/pkg/testserver"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type clients struct {
+	auth         auth.AuthServiceClient
+	storage      storage.StorageServiceClient
+	subscription subscription.SubscriptionServiceClient
+}
+
+func newClients(t *testing.T, srv *testserver.Server) clients {
+	t.Helper()
+
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return clients{
+		auth:         auth.NewAuthServiceClient(conn),
+		storage:      storage.NewStorageServiceClient(conn),
+		subscription: subscription.NewSubscriptionServiceClient(conn),
+	}
+}
+
+func register(t *testing.T, srv *testserver.Server, username string) context.Context {
+	t.Helper()
+
+	token, err := srv.Register(context.Background(), username, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testserver.WithToken(context.Background(), token)
+}
+
+func saveBlock(t *testing.T, c clients, ctx context.Context, title string) {
+	t.Helper()
+
+	_, err := c.storage.SaveDataBlock(ctx, storage.SaveDataBlockRequest_builder{
+		Title:       proto.String(title),
+		Chiphertext: []byte("ciphertext"),
+		Salt:        []byte("salt"),
+		Nonce:       []byte("nonce"),
+		TypeId:      proto.Int32(1),
+		BlockUid:    proto.String(uuid.NewString()),
+	}.Build())
+	if err != nil {
+		t.Fatalf("SaveDataBlock: %v", err)
+	}
+}
+
+// listBlocks subscribes a new client and opens its block stream, the
+// stream is closed on test cleanup.
+func listBlocks(t *testing.T, c clients, ctx context.Context) grpc.ServerStreamingClient[storage.ListDataBlocksResponse] {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	t.Cleanup(cancel)
+
+	clientID := uuid.NewString()
+	_, err := c.subscription.Subscribe(ctx, subscription.SubscribeRequest_builder{
+		ClientId: proto.String(clientID),
+	}.Build())
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	stream, err := c.storage.ListDataBlocks(ctx, storage.ListDataBlocksRequest_builder{
+		ClientId: proto.String(clientID),
+	}.Build())
+	if err != nil {
+		t.Fatalf("ListDataBlocks: %v", err)
+	}
+
+	return stream
+}
+
+func titles(t *testing.T, stream grpc.ServerStreamingClient[storage.ListDataBlocksResponse]) []string {
+	t.Helper()
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("receive blocks: %v", err)
+	}
+
+	var titles []string
+	for _, b := range resp.GetDataBlocks() {
+		titles = append(titles, b.GetTitle())
+	}
+
+	return titles
+}
+
+func TestRegisterAndAuthenticate(t *testing.T) {
+	srv := testserver.New(t)
+	c := newClients(t, srv)
+	ctx := context.Background()
+
+	if _, err := srv.Register(ctx, "alice", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Register(ctx, "alice", "other"); err == nil {
+		t.Error("registering a taken username succeeded")
+	}
+
+	resp, err := c.auth.Authenticate(ctx, auth.AuthRequest_builder{
+		Username: proto.String("alice"),
+		Password: proto.String("password"),
+	}.Build())
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if resp.GetToken() == "" {
+		t.Error("Authenticate returned an empty token")
+	}
+
+	_, err = c.auth.Authenticate(ctx, auth.AuthRequest_builder{
+		Username: proto.String("alice"),
+		Password: proto.String("wrong"),
+	}.Build())
+	if err == nil {
+		t.Error("Authenticate with a wrong password succeeded")
+	}
+
+	// the issued token authorizes storage calls, none is rejected
+	if _, err := c.storage.GetUsage(testserver.WithToken(ctx, resp.GetToken()), storage.GetUsageRequest_builder{}.Build()); err != nil {
+		t.Errorf("GetUsage with the issued token: %v", err)
+	}
+	_, err = c.storage.GetUsage(ctx, storage.GetUsageRequest_builder{}.Build())
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetUsage without a token: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestSaveAndList(t *testing.T) {
+	srv := testserver.New(t)
+	c := newClients(t, srv)
+	alice := register(t, srv, "alice")
+	bob := register(t, srv, "bob")
+
+	saveBlock(t, c, alice, "first")
+	saveBlock(t, c, alice, "second")
+
+	got := titles(t, listBlocks(t, c, alice))
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("alice's blocks are %v, want [first second]", got)
+	}
+
+	if got := titles(t, listBlocks(t, c, bob)); len(got) != 0 {
+		t.Errorf("bob sees blocks %v of another user", got)
+	}
+}
+
+func TestListRequiresSubscription(t *testing.T) {
+	srv := testserver.New(t)
+	c := newClients(t, srv)
+	alice := register(t, srv, "alice")
+
+	stream, err := c.storage.ListDataBlocks(alice, storage.ListDataBlocksRequest_builder{
+		ClientId: proto.String(uuid.NewString()),
+	}.Build())
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("ListDataBlocks without subscription: got %v, want NotFound", err)
+	}
+}
+
+func TestSubscriptionPushesUpdates(t *testing.T) {
+	srv := testserver.New(t)
+	c := newClients(t, srv)
+	alice := register(t, srv, "alice")
+
+	stream := listBlocks(t, c, alice)
+	if got := titles(t, stream); len(got) != 0 {
+		t.Fatalf("new user has blocks %v", got)
+	}
+
+	// a block saved by another client of the same user is pushed
+	other := newClients(t, srv)
+	saveBlock(t, other, alice, "pushed")
+
+	if got := titles(t, stream); len(got) != 1 || got[0] != "pushed" {
+		t.Errorf("pushed blocks are %v, want [pushed]", got)
+	}
+}